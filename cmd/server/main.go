@@ -11,9 +11,13 @@ import (
 	sentrygin "github.com/getsentry/sentry-go/gin"
 	"github.com/gin-gonic/gin"
 
+	"pdf-service/internal/cache"
 	"pdf-service/internal/config"
+	"pdf-service/internal/jobs"
 	"pdf-service/internal/service"
+	"pdf-service/internal/signing"
 	httptransport "pdf-service/internal/transport/http"
+	"pdf-service/internal/verification"
 )
 
 func main() {
@@ -48,13 +52,92 @@ func main() {
 		}))
 	}
 
+	registry, err := verification.OpenFileRegistry(config.VerificationRegistryPath())
+	if err != nil {
+		log.Fatalf("failed to open verification registry: %v", err)
+	}
+	// Registered before the auth middleware: whoever received a document
+	// may check it.
+	router.GET("/verify/:id", httptransport.NewVerificationHandler(registry).Verify)
+
 	router.Use(httptransport.AuthMiddleware())
 
-	pdfService := service.NewPDFService()
-	handler := httptransport.NewHandler(pdfService)
+	verificationSecret := config.VerificationSecret()
+	if verificationSecret == "" {
+		log.Println("VERIFICATION_SECRET is not set: documents get random verification IDs and are not reproducible")
+	}
+	serviceOptions := []service.Option{
+		service.WithDefaultBrandingProfile(config.DefaultBrandingProfileID()),
+		service.WithVerification(registry, config.PublicBaseURL()),
+		service.WithVerificationSecret(verificationSecret),
+	}
+	if path := config.BrandingProfilesPath(); path != "" {
+		profiles, err := service.LoadBrandingProfiles(path)
+		if err != nil {
+			log.Fatalf("failed to load branding profiles: %v", err)
+		}
+		serviceOptions = append(serviceOptions, service.WithBrandingProfiles(profiles...))
+	}
+	if dir := config.DocumentTemplatesDir(); dir != "" {
+		templates, err := service.LoadDocumentTemplates(dir)
+		if err != nil {
+			log.Fatalf("failed to load document templates: %v", err)
+		}
+		serviceOptions = append(serviceOptions, service.WithDocumentTemplates(templates...))
+	}
+
+	if path := config.SigningCertificatePath(); path != "" {
+		signer, err := signing.LoadPKCS12File(path, config.SigningCertificatePassword(),
+			signing.WithReason(config.SigningReason()),
+			signing.WithLocation(config.SigningLocation()),
+		)
+		if err != nil {
+			log.Fatalf("failed to load signing certificate: %v", err)
+		}
+		serviceOptions = append(serviceOptions, service.WithSigner(signer))
+	}
+
+	pdfService := service.NewPDFService(serviceOptions...)
+	if id := config.DefaultBrandingProfileID(); id != "" && !pdfService.HasBrandingProfile(id) {
+		log.Fatalf("BRANDING_DEFAULT_PROFILE %q is not a configured branding profile", id)
+	}
+
+	var renderCache cache.Store = cache.NewLRU(config.RenderCacheEntries())
+	// A stored response must be replayed for the whole IDEMPOTENCY_TTL, so
+	// idempotency gets a store of its own that never evicts early.
+	var idempotencyStore cache.Store = cache.NewExpiring(config.IdempotencyStoreBytes())
+	if addr := config.RedisAddr(); addr != "" {
+		redis := cache.NewRedis(addr, cache.WithPassword(config.RedisPassword()))
+		renderCache, idempotencyStore = redis, redis
+	}
+	cachedService := service.NewCachedPDFService(pdfService, renderCache, config.RenderCacheTTL())
+	handler := httptransport.NewHandler(cachedService)
+	idempotency := httptransport.NewIdempotency(idempotencyStore, config.IdempotencyTTL())
+	idempotent := idempotency.Middleware()
 
-	router.POST("/generate-proposal", handler.GenerateProposal)
-	router.POST("/generate-contract", handler.GenerateContract)
+	router.POST("/generate-proposal", idempotent, handler.GenerateProposal)
+	router.POST("/generate-contract", idempotent, handler.GenerateContract)
+	router.POST("/generate-receipt", idempotent, handler.GenerateReceipt)
+	router.POST("/generate-financing-simulation", idempotent, handler.GenerateFinancingSimulation)
+	bundleIdempotent := idempotency.MiddlewareWithLimit(httptransport.MaxBundlePayloadBytes)
+	router.POST("/bundles", bundleIdempotent, handler.GenerateBundle)
+	router.POST("/proposals/validate", handler.ValidateProposal)
+	router.POST("/contracts/validate", handler.ValidateContract)
+
+	var queueOptions []jobs.Option
+	if secret := config.WebhookSecret(); secret != "" {
+		baseURL := config.PublicBaseURL()
+		queueOptions = append(queueOptions, jobs.WithNotifier(jobs.NewNotifier(secret,
+			jobs.WithRetries(config.WebhookMaxAttempts(), time.Second),
+			jobs.WithResultURL(func(id string) string { return baseURL + "/jobs/" + id + "/result" }),
+			jobs.WithErrorText(httptransport.GenerationErrorMessage),
+		)))
+	}
+	jobQueue := jobs.NewQueue(config.JobWorkers(), config.JobQueueSize(), config.JobResultTTL(), queueOptions...)
+	jobHandler := httptransport.NewJobHandler(cachedService, jobQueue)
+	router.POST("/jobs", bundleIdempotent, jobHandler.SubmitJob)
+	router.GET("/jobs/:id", jobHandler.JobStatus)
+	router.GET("/jobs/:id/result", jobHandler.JobResult)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -0,0 +1,13 @@
+// Package cache stores rendered documents by key, in Redis when one is
+// configured and in process memory otherwise.
+package cache
+
+import "time"
+
+// Store keeps byte values for a limited time. Get reports a missing or
+// expired key with ok false and a nil error; errors mean the store itself
+// could not be reached.
+type Store interface {
+	Get(key string) (value []byte, ok bool, err error)
+	Set(key string, value []byte, ttl time.Duration) error
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrFull is returned by Expiring.Set when the value does not fit.
+var ErrFull = errors.New("cache is full")
+
+// Expiring is an in-memory Store that keeps every entry until its ttl runs
+// out, holding at most a fixed number of bytes of values. Unlike LRU it
+// never drops an entry early to make room: Set fails with ErrFull instead,
+// so a value it took is served for its whole ttl.
+type Expiring struct {
+	maxBytes int64
+	now      func() time.Time
+
+	mu      sync.Mutex
+	size    int64
+	entries map[string]expiringEntry
+}
+
+type expiringEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewExpiring returns an empty store of at most maxBytes bytes of values.
+func NewExpiring(maxBytes int64) *Expiring {
+	return &Expiring{
+		maxBytes: maxBytes,
+		now:      time.Now,
+		entries:  map[string]expiringEntry{},
+	}
+}
+
+// Get returns a copy of the value stored under key.
+func (c *Expiring) Get(key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if entry.expired(c.now()) {
+		c.removeLocked(key)
+		return nil, false, nil
+	}
+	return append([]byte(nil), entry.value...), true, nil
+}
+
+// Set stores a copy of value under key, replacing any value it had. A ttl
+// of zero keeps it for the life of the process. Expired entries are
+// dropped to make room; when that is not enough Set fails with ErrFull.
+func (c *Expiring) Set(key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	size := c.size + int64(len(value))
+	if previous, ok := c.entries[key]; ok {
+		size -= int64(len(previous.value))
+	}
+	if size > c.maxBytes {
+		now := c.now()
+		for existing, entry := range c.entries {
+			if existing != key && entry.expired(now) {
+				c.removeLocked(existing)
+				size -= int64(len(entry.value))
+			}
+		}
+		if size > c.maxBytes {
+			return ErrFull
+		}
+	}
+
+	entry := expiringEntry{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expiresAt = c.now().Add(ttl)
+	}
+	c.entries[key] = entry
+	c.size = size
+	return nil
+}
+
+func (c *Expiring) removeLocked(key string) {
+	c.size -= int64(len(c.entries[key].value))
+	delete(c.entries, key)
+}
+
+func (e expiringEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
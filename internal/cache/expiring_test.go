@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExpiringKeepsEntriesUntilTheirTTLWhenFull(t *testing.T) {
+	now := time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC)
+	c := NewExpiring(6)
+	c.now = func() time.Time { return now }
+
+	_ = c.Set("a", []byte("123"), time.Minute)
+	_ = c.Set("b", []byte("456"), time.Hour)
+	if err := c.Set("c", []byte("7"), time.Hour); !errors.Is(err, ErrFull) {
+		t.Fatalf("expected ErrFull, got %v", err)
+	}
+	for _, key := range []string{"a", "b"} {
+		if _, ok, _ := c.Get(key); !ok {
+			t.Fatalf("expected %s to be kept", key)
+		}
+	}
+	if err := c.Set("b", []byte("789"), time.Hour); err != nil {
+		t.Fatalf("expected replacing a value to fit, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := c.Set("c", []byte("7"), time.Hour); err != nil {
+		t.Fatalf("expected the expired entry to make room, got %v", err)
+	}
+	if _, ok, _ := c.Get("a"); ok {
+		t.Fatal("expected a to expire")
+	}
+	if got, ok, _ := c.Get("b"); !ok || string(got) != "789" {
+		t.Fatalf("Get(b) = %q, %v", got, ok)
+	}
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU is an in-memory Store that holds at most a fixed number of entries,
+// evicting the least recently used one to make room.
+type LRU struct {
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewLRU returns an empty cache of at most maxEntries entries.
+func NewLRU(maxEntries int) *LRU {
+	return &LRU{
+		maxEntries: max(maxEntries, 1),
+		now:        time.Now,
+		order:      list.New(),
+		entries:    map[string]*list.Element{},
+	}
+}
+
+// Get returns a copy of the value stored under key.
+func (c *LRU) Get(key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		c.removeLocked(element)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(element)
+	return append([]byte(nil), entry.value...), true, nil
+}
+
+// Set stores a copy of value under key. A ttl of zero keeps it until it is
+// evicted.
+func (c *LRU) Set(key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &lruEntry{key: key, value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expiresAt = c.now().Add(ttl)
+	}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		c.removeLocked(c.order.Back())
+	}
+	return nil
+}
+
+func (c *LRU) removeLocked(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruEntry).key)
+}
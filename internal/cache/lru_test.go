@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU(2)
+	_ = c.Set("a", []byte("1"), 0)
+	_ = c.Set("b", []byte("2"), 0)
+	if _, ok, _ := c.Get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	_ = c.Set("c", []byte("3"), 0)
+
+	if _, ok, _ := c.Get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := c.Get(key); !ok {
+			t.Fatalf("expected %s to be kept", key)
+		}
+	}
+}
+
+func TestLRUExpiresEntriesAndCopiesValues(t *testing.T) {
+	now := time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC)
+	c := NewLRU(4)
+	c.now = func() time.Time { return now }
+
+	value := []byte("pdf")
+	_ = c.Set("key", value, time.Minute)
+	value[0] = 'x'
+
+	got, ok, err := c.Get("key")
+	if err != nil || !ok || string(got) != "pdf" {
+		t.Fatalf("Get() = %q, %v, %v", got, ok, err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok, _ := c.Get("key"); ok {
+		t.Fatal("expected the entry to expire")
+	}
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is a Store backed by a Redis server. The go-redis client pools its
+// connections, so one Redis is shared by every request.
+type Redis struct {
+	client *redis.Client
+}
+
+// RedisOption customizes a Redis store at construction time.
+type RedisOption func(*redis.Options)
+
+// WithPassword authenticates every new connection.
+func WithPassword(password string) RedisOption {
+	return func(o *redis.Options) {
+		o.Password = password
+	}
+}
+
+// WithTimeout bounds dialing and each command round trip.
+func WithTimeout(timeout time.Duration) RedisOption {
+	return func(o *redis.Options) {
+		o.DialTimeout = timeout
+		o.ReadTimeout = timeout
+		o.WriteTimeout = timeout
+	}
+}
+
+// NewRedis returns a store for the server at addr ("host:port"). No
+// connection is made until the first command.
+func NewRedis(addr string, options ...RedisOption) *Redis {
+	opts := &redis.Options{
+		Addr:         addr,
+		Protocol:     2,
+		DialTimeout:  2 * time.Second,
+		ReadTimeout:  2 * time.Second,
+		WriteTimeout: 2 * time.Second,
+	}
+	for _, option := range options {
+		option(opts)
+	}
+	return &Redis{client: redis.NewClient(opts)}
+}
+
+// Get returns the value of key.
+func (r *Redis) Get(key string) ([]byte, bool, error) {
+	value, err := r.client.Get(context.Background(), key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores value under key, expiring it after ttl when ttl is positive.
+func (r *Redis) Set(key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(context.Background(), key, value, max(ttl, 0)).Err()
+}
+
+// Close closes the pooled connections.
+func (r *Redis) Close() {
+	_ = r.client.Close()
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisStoresBinaryValuesWithExpiry(t *testing.T) {
+	server := miniredis.RunT(t)
+	store := NewRedis(server.Addr())
+	defer store.Close()
+
+	if _, ok, err := store.Get("missing"); err != nil || ok {
+		t.Fatalf("Get(missing) = %v, %v", ok, err)
+	}
+
+	value := []byte("%PDF-1.4\r\n\x00\xff")
+	if err := store.Set("doc", value, 90*time.Second); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	got, ok, err := store.Get("doc")
+	if err != nil || !ok || string(got) != string(value) {
+		t.Fatalf("Get(doc) = %q, %v, %v", got, ok, err)
+	}
+	if ttl := server.TTL("doc"); ttl != 90*time.Second {
+		t.Fatalf("expected a 90s expiry, got %v", ttl)
+	}
+
+	server.FastForward(90 * time.Second)
+	if _, ok, err := store.Get("doc"); err != nil || ok {
+		t.Fatalf("expected doc to expire, got %v, %v", ok, err)
+	}
+}
+
+func TestRedisAuthenticatesNewConnections(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.RequireAuth("test-password")
+
+	var redisErr redis.Error
+	if _, _, err := NewRedis(server.Addr()).Get("doc"); !errors.As(err, &redisErr) {
+		t.Fatalf("expected a NOAUTH error reply, got %v", err)
+	}
+	if _, _, err := NewRedis(server.Addr(), WithPassword("wrong")).Get("doc"); err == nil {
+		t.Fatal("expected a wrong password to fail")
+	}
+
+	store := NewRedis(server.Addr(), WithPassword("test-password"))
+	defer store.Close()
+	if err := store.Set("doc", []byte("pdf"), 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, ok, err := store.Get("doc"); err != nil || !ok || string(got) != "pdf" {
+		t.Fatalf("Get(doc) = %q, %v, %v", got, ok, err)
+	}
+}
+
+func TestRedisReportsUnreachableServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	if _, _, err := NewRedis(addr, WithTimeout(100*time.Millisecond)).Get("doc"); err == nil {
+		t.Fatal("expected an error for an unreachable server")
+	}
+}
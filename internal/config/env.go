@@ -1,8 +1,11 @@
 package config
 
 import (
+	"net"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func InternalAPIKey() string {
@@ -12,3 +15,158 @@ func InternalAPIKey() string {
 
 	return strings.TrimSpace(os.Getenv("PDF_INTERNAL_API_KEY"))
 }
+
+// BrandingProfilesPath points to the JSON file with partner agency branding
+// profiles. When empty only the built-in profile is available.
+func BrandingProfilesPath() string {
+	return strings.TrimSpace(os.Getenv("BRANDING_PROFILES_FILE"))
+}
+
+func DefaultBrandingProfileID() string {
+	return strings.TrimSpace(os.Getenv("BRANDING_DEFAULT_PROFILE"))
+}
+
+// DocumentTemplatesDir points to a directory of document template files
+// that replace the built-in proposal and contract templates.
+func DocumentTemplatesDir() string {
+	return strings.TrimSpace(os.Getenv("DOCUMENT_TEMPLATES_DIR"))
+}
+
+// JobWorkers is how many asynchronous jobs render at the same time.
+func JobWorkers() int {
+	return positiveIntEnv("JOB_WORKERS", 4)
+}
+
+// JobQueueSize is how many asynchronous jobs may wait for a worker before
+// new ones are refused.
+func JobQueueSize() int {
+	return positiveIntEnv("JOB_QUEUE_SIZE", 100)
+}
+
+// JobResultTTL is how long a finished job and its PDF are kept, as a Go
+// duration such as "15m".
+func JobResultTTL() time.Duration {
+	value := strings.TrimSpace(os.Getenv("JOB_RESULT_TTL"))
+	if ttl, err := time.ParseDuration(value); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 15 * time.Minute
+}
+
+// WebhookSecret is the shared secret that signs job callbacks. Callbacks
+// are refused while it is empty.
+func WebhookSecret() string {
+	return strings.TrimSpace(os.Getenv("WEBHOOK_SECRET"))
+}
+
+// WebhookMaxAttempts is how many times a job callback is tried before it
+// is given up on.
+func WebhookMaxAttempts() int {
+	return positiveIntEnv("WEBHOOK_MAX_ATTEMPTS", 5)
+}
+
+// PublicBaseURL is the externally visible address of the service, such as
+// "https://pdf.example.com", used to build absolute download links in
+// callbacks. When empty the links are paths.
+func PublicBaseURL() string {
+	return strings.TrimRight(strings.TrimSpace(os.Getenv("PUBLIC_BASE_URL")), "/")
+}
+
+// RedisAddr is the "host:port" of the Redis server that caches rendered
+// documents, or empty to cache them in process memory.
+func RedisAddr() string {
+	host := strings.TrimSpace(os.Getenv("REDIS_HOST"))
+	if host == "" {
+		return ""
+	}
+	port := strings.TrimSpace(os.Getenv("REDIS_PORT"))
+	if port == "" {
+		port = "6379"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+func RedisPassword() string {
+	return os.Getenv("REDIS_PASSWORD")
+}
+
+// RenderCacheTTL is how long a rendered document stays cached, as a Go
+// duration such as "1h".
+func RenderCacheTTL() time.Duration {
+	value := strings.TrimSpace(os.Getenv("RENDER_CACHE_TTL"))
+	if ttl, err := time.ParseDuration(value); err == nil && ttl > 0 {
+		return ttl
+	}
+	return time.Hour
+}
+
+// RenderCacheEntries is how many documents the in-memory cache keeps when
+// Redis is not configured.
+func RenderCacheEntries() int {
+	return positiveIntEnv("RENDER_CACHE_ENTRIES", 128)
+}
+
+// IdempotencyTTL is how long the first response to a request with an
+// Idempotency-Key is replayed, as a Go duration such as "24h".
+func IdempotencyTTL() time.Duration {
+	value := strings.TrimSpace(os.Getenv("IDEMPOTENCY_TTL"))
+	if ttl, err := time.ParseDuration(value); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 24 * time.Hour
+}
+
+// IdempotencyStoreBytes is how much memory, in bytes, stored responses to
+// Idempotency-Key requests may take when Redis is not configured, read
+// from IDEMPOTENCY_STORE_MB. Once it is used up new keys are not honored
+// until stored ones expire.
+func IdempotencyStoreBytes() int64 {
+	return int64(positiveIntEnv("IDEMPOTENCY_STORE_MB", 256)) << 20
+}
+
+// SigningCertificatePath points to the PKCS#12 (.p12/.pfx) file whose
+// certificate signs documents requested with "sign". When empty signing is
+// disabled.
+func SigningCertificatePath() string {
+	return strings.TrimSpace(os.Getenv("SIGNING_CERT_FILE"))
+}
+
+func SigningCertificatePassword() string {
+	return os.Getenv("SIGNING_CERT_PASSWORD")
+}
+
+// SigningReason and SigningLocation are recorded in the signature, for
+// example "Emissão de documento" and "São Paulo, SP".
+func SigningReason() string {
+	return strings.TrimSpace(os.Getenv("SIGNING_REASON"))
+}
+
+func SigningLocation() string {
+	return strings.TrimSpace(os.Getenv("SIGNING_LOCATION"))
+}
+
+// VerificationRegistryPath is the JSON Lines file that records generated
+// documents for public verification.
+func VerificationRegistryPath() string {
+	if path := strings.TrimSpace(os.Getenv("VERIFICATION_REGISTRY_FILE")); path != "" {
+		return path
+	}
+	return "data/verification-registry.jsonl"
+}
+
+// VerificationSecret keys the verification IDs derived from document
+// content, so they cannot be worked out from what a document says. When it
+// is unset, documents get random verification IDs.
+func VerificationSecret() string {
+	return strings.TrimSpace(os.Getenv("VERIFICATION_SECRET"))
+}
+
+// positiveIntEnv reads a positive integer, falling back when the variable
+// is unset or invalid.
+func positiveIntEnv(name string, fallback int) int {
+	value, err := strconv.Atoi(strings.TrimSpace(os.Getenv(name)))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
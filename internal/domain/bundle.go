@@ -0,0 +1,215 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Part types of a bundle: a document generated from the inline request of
+// its generate endpoint, or an uploaded file.
+const (
+	BundlePartProposal            = "proposal"
+	BundlePartContract            = "contract"
+	BundlePartReceipt             = "receipt"
+	BundlePartFinancingSimulation = "financing_simulation"
+	BundlePartUpload              = "upload"
+)
+
+var bundlePartChoices = []string{BundlePartProposal, BundlePartContract, BundlePartReceipt, BundlePartFinancingSimulation, BundlePartUpload}
+
+// Formats accepted for uploaded parts, recognised by their content.
+const (
+	UploadFormatPDF  = "pdf"
+	UploadFormatJPEG = "jpeg"
+	UploadFormatPNG  = "png"
+)
+
+const (
+	maxBundleParts       = 20
+	maxBundleTitleLength = 120
+)
+
+// BundleRequest merges generated documents and uploaded files into one PDF
+// with a cover page listing the parts, such as the dossier a buyer gets at
+// closing.
+type BundleRequest struct {
+	Title             string       `json:"title"`
+	Parts             []BundlePart `json:"parts"`
+	BrandingProfileID string       `json:"branding_profile_id"`
+	IssueDate         string       `json:"issue_date"`
+	Sign              bool         `json:"sign"`
+}
+
+// BundlePart is one entry of a bundle, in JSON as {"type", "title",
+// "request"} for generated documents and {"type": "upload", "title",
+// "data"} for files, data being the base64 of a PDF, JPEG or PNG. Only the
+// request field matching Type is decoded.
+type BundlePart struct {
+	Type                string
+	Title               string
+	Proposal            *ProposalRequest
+	Contract            *ContractRequest
+	Receipt             *ReceiptRequest
+	FinancingSimulation *FinancingSimulationRequest
+	Data                []byte
+}
+
+func (p *BundlePart) UnmarshalJSON(data []byte) error {
+	var payload struct {
+		Type    string          `json:"type"`
+		Title   string          `json:"title"`
+		Request json.RawMessage `json:"request"`
+		Data    []byte          `json:"data"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+	*p = BundlePart{Type: sanitizeText(payload.Type), Title: payload.Title, Data: payload.Data}
+	if len(payload.Request) == 0 || string(payload.Request) == "null" {
+		return nil
+	}
+	var request any
+	switch p.Type {
+	case BundlePartProposal:
+		p.Proposal = &ProposalRequest{}
+		request = p.Proposal
+	case BundlePartContract:
+		p.Contract = &ContractRequest{}
+		request = p.Contract
+	case BundlePartReceipt:
+		p.Receipt = &ReceiptRequest{}
+		request = p.Receipt
+	case BundlePartFinancingSimulation:
+		p.FinancingSimulation = &FinancingSimulationRequest{}
+		request = p.FinancingSimulation
+	default:
+		return nil
+	}
+	return json.Unmarshal(payload.Request, request)
+}
+
+// UploadFormat recognises the uploaded file by its first bytes, returning
+// an empty string for anything but a PDF, JPEG or PNG.
+func (p BundlePart) UploadFormat() string {
+	switch {
+	case bytes.HasPrefix(p.Data, []byte("%PDF-")):
+		return UploadFormatPDF
+	case bytes.HasPrefix(p.Data, []byte{0xff, 0xd8, 0xff}):
+		return UploadFormatJPEG
+	case bytes.HasPrefix(p.Data, []byte("\x89PNG\r\n\x1a\n")):
+		return UploadFormatPNG
+	}
+	return ""
+}
+
+// ResolvedTitle is the title of the part on the cover page and in the
+// bookmarks, named after the document when the request gives none.
+func (p BundlePart) ResolvedTitle() string {
+	if p.Title != "" {
+		return p.Title
+	}
+	switch p.Type {
+	case BundlePartProposal:
+		return "Proposta"
+	case BundlePartContract:
+		if p.Contract != nil && p.Contract.ResolvedDocumentState() == DocumentStateDraft {
+			return "Minuta do contrato"
+		}
+		return "Contrato"
+	case BundlePartReceipt:
+		return "Recibo de sinal"
+	case BundlePartFinancingSimulation:
+		return "Simulação de financiamento"
+	default:
+		return "Documento anexo"
+	}
+}
+
+// ResolvedTitle is the title of the cover page.
+func (r BundleRequest) ResolvedTitle() string {
+	if r.Title != "" {
+		return r.Title
+	}
+	return "Dossiê da negociação"
+}
+
+func (r *BundleRequest) Sanitize() {
+	r.Title = sanitizeText(r.Title)
+	r.BrandingProfileID = sanitizeText(r.BrandingProfileID)
+	r.IssueDate = sanitizeText(r.IssueDate)
+	for i := range r.Parts {
+		r.Parts[i].Title = sanitizeText(r.Parts[i].Title)
+	}
+}
+
+// Validate checks the bundle and every inline request, reporting the
+// problems of a request under its part, as in
+// "/parts/1/request/clientName".
+func (r *BundleRequest) Validate() error {
+	r.Sanitize()
+	var v validator
+	v.date("/issue_date", r.IssueDate)
+	v.maxLength("/title", r.Title, maxBundleTitleLength)
+	switch {
+	case len(r.Parts) == 0:
+		v.required("/parts")
+	case len(r.Parts) > maxBundleParts:
+		v.add("/parts", CodeMaxItems, fmt.Sprintf("Informe no máximo %d partes.", maxBundleParts), maxBundleParts)
+	}
+
+	for i := range r.Parts {
+		part := &r.Parts[i]
+		path := fmt.Sprintf("/parts/%d", i)
+		v.maxLength(path+"/title", part.Title, maxBundleTitleLength)
+
+		var request interface{ Validate() error }
+		var sign bool
+		switch part.Type {
+		case BundlePartProposal:
+			if part.Proposal != nil {
+				request, sign = part.Proposal, part.Proposal.Sign
+			}
+		case BundlePartContract:
+			if part.Contract != nil {
+				request, sign = part.Contract, part.Contract.Sign
+			}
+		case BundlePartReceipt:
+			if part.Receipt != nil {
+				request, sign = part.Receipt, part.Receipt.Sign
+			}
+		case BundlePartFinancingSimulation:
+			if part.FinancingSimulation != nil {
+				request, sign = part.FinancingSimulation, part.FinancingSimulation.Sign
+			}
+		case BundlePartUpload:
+			if len(part.Data) == 0 {
+				v.required(path + "/data")
+			} else if part.UploadFormat() == "" {
+				v.add(path+"/data", CodeInvalidFormat, "Envie um arquivo PDF, JPEG ou PNG em base64.", []string{UploadFormatPDF, UploadFormatJPEG, UploadFormatPNG})
+			}
+			continue
+		default:
+			v.oneOf(path+"/type", bundlePartChoices)
+			continue
+		}
+
+		if request == nil {
+			v.required(path + "/request")
+			continue
+		}
+		if sign {
+			// A merged part loses its signature; the bundle is signed
+			// as a whole instead.
+			v.add(path+"/request/sign", CodeNotApplicable, "Partes do dossiê não são assinadas individualmente; use sign no dossiê.", nil)
+		}
+		var fieldErrs ValidationErrors
+		if err := request.Validate(); errors.As(err, &fieldErrs) {
+			for _, fieldErr := range fieldErrs {
+				v.add(path+"/request"+fieldErr.Path, fieldErr.Code, fieldErr.Message, fieldErr.Limit)
+			}
+		}
+	}
+	return v.err()
+}
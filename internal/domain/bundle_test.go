@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBundlePartDecodesTheRequestOfItsType(t *testing.T) {
+	var req BundleRequest
+	payload := `{"parts":[
+		{"type":"contract","request":{"contract_id":"contract-1","document_state":"draft"}},
+		{"type":"upload","title":"Matrícula","data":"JVBERi0xLjQK"}
+	]}`
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	contract := req.Parts[0]
+	if contract.Contract == nil || contract.Contract.ContractID != "contract-1" || contract.Proposal != nil {
+		t.Fatalf("expected the contract request only, got %+v", contract)
+	}
+	if got := contract.ResolvedTitle(); got != "Minuta do contrato" {
+		t.Fatalf("expected the draft title, got %q", got)
+	}
+	upload := req.Parts[1]
+	if upload.UploadFormat() != UploadFormatPDF || upload.ResolvedTitle() != "Matrícula" {
+		t.Fatalf("expected a titled PDF upload, got %+v", upload)
+	}
+	if req.ResolvedTitle() != "Dossiê da negociação" {
+		t.Fatalf("unexpected default title %q", req.ResolvedTitle())
+	}
+}
+
+func TestBundleValidationReportsProblemsUnderTheirPart(t *testing.T) {
+	req := BundleRequest{
+		Title: strings.Repeat("a", maxBundleTitleLength+1),
+		Parts: []BundlePart{
+			{Type: BundlePartReceipt, Receipt: &ReceiptRequest{Sign: true}},
+			{Type: BundlePartContract},
+			{Type: BundlePartUpload, Data: []byte("GIF89a")},
+			{Type: BundlePartUpload},
+			{Type: "photo"},
+		},
+	}
+	err := req.Validate()
+
+	requireFieldError(t, err, "/title", CodeMaxLength)
+	requireFieldError(t, err, "/parts/0/request/sign", CodeNotApplicable)
+	requireFieldError(t, err, "/parts/0/request/amount", CodePositive)
+	requireFieldError(t, err, "/parts/1/request", CodeRequired)
+	requireFieldError(t, err, "/parts/2/data", CodeInvalidFormat)
+	requireFieldError(t, err, "/parts/3/data", CodeRequired)
+	requireFieldError(t, err, "/parts/4/type", CodeInvalidChoice)
+
+	tooMany := BundleRequest{Parts: make([]BundlePart, maxBundleParts+1)}
+	requireFieldError(t, tooMany.Validate(), "/parts", CodeMaxItems)
+	requireFieldError(t, (&BundleRequest{}).Validate(), "/parts", CodeRequired)
+}
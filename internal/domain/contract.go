@@ -1,60 +1,514 @@
 package domain
 
 import (
-	"errors"
+	"fmt"
+	"math"
 	"strings"
 )
 
+const (
+	PartyKindPerson  = "person"
+	PartyKindCompany = "company"
+)
+
+var partyKindAliases = map[string]string{
+	PartyKindPerson: PartyKindPerson, "pf": PartyKindPerson, "pessoa_fisica": PartyKindPerson,
+	PartyKindCompany: PartyKindCompany, "pj": PartyKindCompany, "pessoa_juridica": PartyKindCompany,
+}
+
+const (
+	MaritalStatusSingle      = "solteiro"
+	MaritalStatusMarried     = "casado"
+	MaritalStatusStableUnion = "uniao_estavel"
+	MaritalStatusDivorced    = "divorciado"
+	MaritalStatusSeparated   = "separado"
+	MaritalStatusWidowed     = "viuvo"
+)
+
+const (
+	PropertyRegimePartialCommunity    = "comunhao_parcial"
+	PropertyRegimeUniversalCommunity  = "comunhao_universal"
+	PropertyRegimeTotalSeparation     = "separacao_total"
+	PropertyRegimeMandatorySeparation = "separacao_obrigatoria"
+	PropertyRegimeFinalParticipation  = "participacao_final_aquestos"
+)
+
+var maritalStatusAliases = map[string]string{
+	"solteiro": MaritalStatusSingle, "solteira": MaritalStatusSingle, "single": MaritalStatusSingle,
+	"casado": MaritalStatusMarried, "casada": MaritalStatusMarried, "married": MaritalStatusMarried,
+	"uniao_estavel": MaritalStatusStableUnion, "união estável": MaritalStatusStableUnion, "uniao estavel": MaritalStatusStableUnion, "stable_union": MaritalStatusStableUnion,
+	"divorciado": MaritalStatusDivorced, "divorciada": MaritalStatusDivorced, "divorced": MaritalStatusDivorced,
+	"separado": MaritalStatusSeparated, "separada": MaritalStatusSeparated, "separated": MaritalStatusSeparated,
+	"viuvo": MaritalStatusWidowed, "viúvo": MaritalStatusWidowed, "viuva": MaritalStatusWidowed, "viúva": MaritalStatusWidowed, "widowed": MaritalStatusWidowed,
+}
+
+// partyKindChoices, maritalStatusChoices and propertyRegimeChoices list
+// the canonical values for error messages.
+var (
+	partyKindChoices     = []string{PartyKindPerson, PartyKindCompany}
+	maritalStatusChoices = []string{
+		MaritalStatusSingle, MaritalStatusMarried, MaritalStatusStableUnion,
+		MaritalStatusDivorced, MaritalStatusSeparated, MaritalStatusWidowed,
+	}
+	propertyRegimeChoices = []string{
+		PropertyRegimePartialCommunity, PropertyRegimeUniversalCommunity, PropertyRegimeTotalSeparation,
+		PropertyRegimeMandatorySeparation, PropertyRegimeFinalParticipation,
+	}
+)
+
+// propertyRegimeAliases is keyed by the folded form of the regime (see
+// normalizePropertyRegime), so "Comunhão Parcial" and "comunhao_parcial"
+// are the same key.
+var propertyRegimeAliases = map[string]string{
+	"comunhao_parcial": PropertyRegimePartialCommunity, "comunhao_parcial_de_bens": PropertyRegimePartialCommunity,
+	"comunhao_universal": PropertyRegimeUniversalCommunity, "comunhao_universal_de_bens": PropertyRegimeUniversalCommunity,
+	"separacao_total": PropertyRegimeTotalSeparation, "separacao_total_de_bens": PropertyRegimeTotalSeparation,
+	"separacao_obrigatoria": PropertyRegimeMandatorySeparation, "separacao_obrigatoria_de_bens": PropertyRegimeMandatorySeparation,
+	"participacao_final_aquestos": PropertyRegimeFinalParticipation, "participacao_final_nos_aquestos": PropertyRegimeFinalParticipation,
+}
+
+var accentFolder = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a",
+	"é", "e", "ê", "e",
+	"í", "i",
+	"ó", "o", "ô", "o", "õ", "o",
+	"ú", "u", "ü", "u",
+	"ç", "c",
+)
+
 // ContractParty contains only the qualification needed to render a draft.
 // Authorization and account identifiers stay in the backend.
 type ContractParty struct {
+	Kind  string `json:"kind"`
 	Name  string `json:"name"`
 	CPF   string `json:"cpf"`
 	Email string `json:"email"`
 	Phone string `json:"phone"`
+
+	// Individual-only qualification (pessoa física).
+	Nationality    string          `json:"nationality"`
+	MaritalStatus  string          `json:"marital_status"`
+	PropertyRegime string          `json:"property_regime"`
+	Profession     string          `json:"profession"`
+	RG             string          `json:"rg"`
+	Spouse         *ContractSpouse `json:"spouse,omitempty"`
+
+	// Company-only qualification (pessoa jurídica).
+	CNPJ              string                `json:"cnpj"`
+	LegalName         string                `json:"legal_name"`
+	TradeName         string                `json:"trade_name"`
+	RegisteredAddress FlexibleAddress       `json:"registered_address"`
+	Representatives   []LegalRepresentative `json:"representatives"`
+}
+
+// LegalRepresentative is the person signing on behalf of a company party.
+type LegalRepresentative struct {
+	Name string `json:"name"`
+	CPF  string `json:"cpf"`
+	Role string `json:"role"`
 }
 
+// ContractSpouse identifies the spouse or partner who must consent to the
+// deal (outorga conjugal) under community property regimes.
+type ContractSpouse struct {
+	Name        string `json:"name"`
+	CPF         string `json:"cpf"`
+	RG          string `json:"rg"`
+	Nationality string `json:"nationality"`
+	Profession  string `json:"profession"`
+}
+
+// ContractProperty identifies the property at the real estate registry
+// (matrícula and Cartório de Registro de Imóveis) and at the municipality.
+type ContractProperty struct {
+	Type                  string          `json:"type"`
+	RegistryNumber        string          `json:"registry_number"`
+	RegistryOffice        string          `json:"registry_office"`
+	MunicipalRegistration string          `json:"municipal_registration"`
+	BuiltArea             float64         `json:"built_area"`
+	LandArea              float64         `json:"land_area"`
+	ParkingSpaces         int             `json:"parking_spaces"`
+	Address               FlexibleAddress `json:"address"`
+	Description           string          `json:"description"`
+}
+
+// ContractRequest accepts either the legacy single Seller/Buyer or the
+// Sellers/Buyers lists; the lists win when both are present.
 type ContractRequest struct {
 	ContractID      string           `json:"contract_id"`
 	DealType        string           `json:"deal_type"`
 	PropertyTitle   string           `json:"property_title"`
 	PropertyAddress string           `json:"property_address"`
+	Property        ContractProperty `json:"property"`
 	Seller          ContractParty    `json:"seller"`
 	Buyer           ContractParty    `json:"buyer"`
+	Sellers         []ContractParty  `json:"sellers"`
+	Buyers          []ContractParty  `json:"buyers"`
 	SaleTerms       PaymentBreakdown `json:"sale_terms"`
+	SaleValue       float64          `json:"sale_value"`
 	RentalTerms     RentalTerms      `json:"rental_terms"`
+
+	BrandingProfileID string `json:"branding_profile_id"`
+	TemplateVersion   string `json:"template_version"`
+	IssueDate         string `json:"issue_date"`
+	Sign              bool   `json:"sign"`
+	DocumentState     string `json:"document_state"`
 }
 
 func (p *ContractParty) Sanitize() {
+	p.Kind = strings.ToLower(sanitizeText(p.Kind))
 	p.Name = sanitizeText(p.Name)
 	p.CPF = sanitizeText(p.CPF)
 	p.Email = sanitizeText(p.Email)
 	p.Phone = sanitizeText(p.Phone)
+	p.CNPJ = sanitizeText(p.CNPJ)
+	p.LegalName = sanitizeText(p.LegalName)
+	p.TradeName = sanitizeText(p.TradeName)
+	p.Nationality = sanitizeText(p.Nationality)
+	p.MaritalStatus = normalizeMaritalStatus(sanitizeText(p.MaritalStatus))
+	p.PropertyRegime = normalizePropertyRegime(sanitizeText(p.PropertyRegime))
+	p.Profession = sanitizeText(p.Profession)
+	p.RG = sanitizeText(p.RG)
+	if p.Spouse != nil {
+		p.Spouse.Name = sanitizeText(p.Spouse.Name)
+		p.Spouse.CPF = sanitizeText(p.Spouse.CPF)
+		p.Spouse.RG = sanitizeText(p.Spouse.RG)
+		p.Spouse.Nationality = sanitizeText(p.Spouse.Nationality)
+		p.Spouse.Profession = sanitizeText(p.Spouse.Profession)
+	}
+	p.RegisteredAddress.Sanitize()
+	for i := range p.Representatives {
+		p.Representatives[i].Name = sanitizeText(p.Representatives[i].Name)
+		p.Representatives[i].CPF = sanitizeText(p.Representatives[i].CPF)
+		p.Representatives[i].Role = sanitizeText(p.Representatives[i].Role)
+	}
+}
+
+// ResolvedKind treats a party as a company when it says so explicitly or
+// when it only carries a CNPJ; everything else is an individual.
+func (p *ContractParty) ResolvedKind() string {
+	return p.resolveKind("").Value
+}
+
+func (p *ContractParty) resolveKind(path string) Resolved[string] {
+	if kind, ok := partyKindAliases[p.Kind]; ok {
+		return from(path+"/kind", kind)
+	}
+	if p.CNPJ != "" && p.CPF == "" {
+		return from(path+"/cnpj", PartyKindCompany)
+	}
+	return from(SourceDefault, PartyKindPerson)
+}
+
+// ResolvedName prefers the legal name (razão social) for companies.
+func (p *ContractParty) ResolvedName() string {
+	return p.resolveName("").Value
+}
+
+func (p *ContractParty) resolveName(path string) Resolved[string] {
+	if p.ResolvedKind() == PartyKindCompany {
+		return firstText(from(path+"/legal_name", p.LegalName), from(path+"/name", p.Name))
+	}
+	return firstText(from(path+"/name", p.Name))
+}
+
+// ResolvedPropertyRegime falls back to partial community, the legal default
+// for marriages without a prenuptial agreement.
+func (p *ContractParty) ResolvedPropertyRegime() string {
+	return p.resolvePropertyRegime("").Value
+}
+
+func (p *ContractParty) resolvePropertyRegime(path string) Resolved[string] {
+	if p.PropertyRegime == "" && p.hasSpouseStatus() {
+		return from(SourceDefault, PropertyRegimePartialCommunity)
+	}
+	return firstText(from(path+"/property_regime", p.PropertyRegime))
+}
+
+func (p *ContractParty) resolve(path string) PartyResolution {
+	return PartyResolution{
+		Kind:                  p.resolveKind(path),
+		Name:                  p.resolveName(path),
+		PropertyRegime:        p.resolvePropertyRegime(path),
+		RequiresSpouseConsent: p.RequiresSpouseConsent(),
+		Party:                 *p,
+	}
+}
+
+// RequiresSpouseConsent reports whether the spouse must take part in the
+// deal, which is the case for married or stable-union parties under any
+// regime other than total separation.
+func (p *ContractParty) RequiresSpouseConsent() bool {
+	if p.ResolvedKind() == PartyKindCompany || !p.hasSpouseStatus() {
+		return false
+	}
+	regime := p.ResolvedPropertyRegime()
+	return regime != PropertyRegimeTotalSeparation && regime != PropertyRegimeMandatorySeparation
+}
+
+func (p *ContractParty) hasSpouseStatus() bool {
+	return p.MaritalStatus == MaritalStatusMarried || p.MaritalStatus == MaritalStatusStableUnion
+}
+
+func (p *ContractParty) validate(v *validator, path string) {
+	if _, ok := partyKindAliases[p.Kind]; p.Kind != "" && !ok {
+		v.oneOf(path+"/kind", partyKindChoices)
+	}
+	v.document(path+"/cpf", &p.CPF, "")
+	if p.ResolvedKind() != PartyKindCompany {
+		p.validateIndividual(v, path)
+		return
+	}
+
+	v.document(path+"/cnpj", &p.CNPJ, DocumentKindCNPJ)
+	v.requiredText(path+"/cnpj", p.CNPJ)
+	if len(p.Representatives) == 0 {
+		v.add(path+"/representatives", CodeRequired, "Informe ao menos um representante legal.", 1)
+	}
+	for i := range p.Representatives {
+		representative := &p.Representatives[i]
+		representativePath := fmt.Sprintf("%s/representatives/%d", path, i)
+		v.requiredText(representativePath+"/name", representative.Name)
+		v.document(representativePath+"/cpf", &representative.CPF, DocumentKindCPF)
+	}
 }
 
 func (r *ContractRequest) Sanitize() {
 	r.ContractID = sanitizeText(r.ContractID)
 	r.DealType = strings.ToLower(sanitizeText(r.DealType))
+	r.DocumentState = strings.ToLower(sanitizeText(r.DocumentState))
 	r.PropertyTitle = sanitizeText(r.PropertyTitle)
 	r.PropertyAddress = sanitizeText(r.PropertyAddress)
+	r.Property.Sanitize()
 	r.Seller.Sanitize()
 	r.Buyer.Sanitize()
+	for i := range r.Sellers {
+		r.Sellers[i].Sanitize()
+	}
+	for i := range r.Buyers {
+		r.Buyers[i].Sanitize()
+	}
+	r.SaleTerms.Sanitize()
+	r.BrandingProfileID = sanitizeText(r.BrandingProfileID)
+	r.TemplateVersion = sanitizeText(r.TemplateVersion)
 	r.RentalTerms.Sanitize()
 }
 
+func (p *ContractParty) validateIndividual(v *validator, path string) {
+	v.maxLength(path+"/nationality", p.Nationality, 60)
+	v.maxLength(path+"/profession", p.Profession, 80)
+	v.maxLength(path+"/rg", p.RG, 30)
+	if _, ok := maritalStatusAliases[p.MaritalStatus]; p.MaritalStatus != "" && !ok {
+		v.oneOf(path+"/marital_status", maritalStatusChoices)
+	}
+	if _, ok := propertyRegimeAliases[p.PropertyRegime]; p.PropertyRegime != "" && !ok {
+		v.oneOf(path+"/property_regime", propertyRegimeChoices)
+	}
+	if p.PropertyRegime != "" && !p.hasSpouseStatus() {
+		v.add(path+"/property_regime", CodeNotApplicable, "O regime de bens só se aplica a partes casadas ou em união estável.", nil)
+	}
+
+	if p.RequiresSpouseConsent() {
+		const message = "Obrigatório para o regime de bens informado."
+		if p.Spouse == nil {
+			v.add(path+"/spouse", CodeRequired, message, nil)
+		} else {
+			if p.Spouse.Name == "" {
+				v.add(path+"/spouse/name", CodeRequired, message, nil)
+			}
+			if p.Spouse.CPF == "" {
+				v.add(path+"/spouse/cpf", CodeRequired, message, nil)
+			}
+		}
+	}
+	if p.Spouse != nil {
+		v.document(path+"/spouse/cpf", &p.Spouse.CPF, DocumentKindCPF)
+	}
+}
+
+func normalizeMaritalStatus(value string) string {
+	lowered := strings.ToLower(value)
+	if canonical, ok := maritalStatusAliases[lowered]; ok {
+		return canonical
+	}
+	return lowered
+}
+
+// normalizePropertyRegime folds case, accents and spacing before looking
+// the regime up, so "Comunhão Parcial de Bens" resolves like
+// "comunhao_parcial".
+func normalizePropertyRegime(value string) string {
+	folded := strings.Join(strings.Fields(accentFolder.Replace(strings.ToLower(value))), "_")
+	if canonical, ok := propertyRegimeAliases[folded]; ok {
+		return canonical
+	}
+	return strings.ToLower(value)
+}
+
+// ResolvedSellers returns the sellers (or landlords) list, falling back to
+// the legacy single seller.
+func (r *ContractRequest) ResolvedSellers() []ContractParty {
+	if len(r.Sellers) > 0 {
+		return r.Sellers
+	}
+	return []ContractParty{r.Seller}
+}
+
+// ResolvedBuyers returns the buyers (or tenants) list, falling back to the
+// legacy single buyer.
+func (r *ContractRequest) ResolvedBuyers() []ContractParty {
+	if len(r.Buyers) > 0 {
+		return r.Buyers
+	}
+	return []ContractParty{r.Buyer}
+}
+
+// resolvePartyList resolves each party of a list, or the legacy single
+// party when the list is empty, like ResolvedSellers and ResolvedBuyers.
+func resolvePartyList(path string, parties []ContractParty, legacyPath string, legacy ContractParty) Resolved[[]PartyResolution] {
+	if len(parties) == 0 {
+		return from(legacyPath, []PartyResolution{legacy.resolve(legacyPath)})
+	}
+	resolved := make([]PartyResolution, len(parties))
+	for i := range parties {
+		resolved[i] = parties[i].resolve(fmt.Sprintf("%s/%d", path, i))
+	}
+	return from(path, resolved)
+}
+
+func validateContractParties(v *validator, path string, parties []ContractParty, legacyPath string, legacy *ContractParty) {
+	if len(parties) == 0 {
+		v.requiredText(legacyPath+"/name", legacy.ResolvedName())
+		legacy.validate(v, legacyPath)
+		return
+	}
+	for i := range parties {
+		partyPath := fmt.Sprintf("%s/%d", path, i)
+		v.requiredText(partyPath+"/name", parties[i].ResolvedName())
+		parties[i].validate(v, partyPath)
+	}
+}
+
+func (p *ContractProperty) Sanitize() {
+	p.Type = sanitizeText(p.Type)
+	p.RegistryNumber = sanitizeText(p.RegistryNumber)
+	p.RegistryOffice = sanitizeText(p.RegistryOffice)
+	p.MunicipalRegistration = sanitizeText(p.MunicipalRegistration)
+	p.Address.Sanitize()
+	p.Description = sanitizeText(p.Description)
+}
+
+func (p *ContractProperty) validate(v *validator) {
+	v.maxLength("/property/type", p.Type, 60)
+	v.maxLength("/property/registry_number", p.RegistryNumber, 40)
+	v.maxLength("/property/registry_office", p.RegistryOffice, 150)
+	v.maxLength("/property/municipal_registration", p.MunicipalRegistration, 60)
+	v.maxLength("/property/address", p.Address.String(), maxPropertyAddressLength)
+	v.maxLength("/property/description", p.Description, 2000)
+	v.nonNegative("/property/built_area", p.BuiltArea)
+	v.nonNegative("/property/land_area", p.LandArea)
+	v.nonNegative("/property/parking_spaces", float64(p.ParkingSpaces))
+}
+
+// ResolvedPropertyAddress prefers the legacy free-form address and falls
+// back to the structured property address.
+func (r *ContractRequest) ResolvedPropertyAddress() string {
+	return r.resolvePropertyAddress().Value
+}
+
+func (r *ContractRequest) resolvePropertyAddress() Resolved[string] {
+	return firstText(from("/property_address", r.PropertyAddress), from("/property/address", r.Property.Address.String()))
+}
+
 func (r *ContractRequest) Validate() error {
 	r.Sanitize()
+	var v validator
+	v.templateVersion(r.TemplateVersion)
+	v.date("/issue_date", r.IssueDate)
+	v.documentState(r.DocumentState)
+	v.signedState(r.Sign, r.ResolvedDocumentState())
 	if r.DealType != "sale" && r.DealType != "rent" {
-		return errors.New("deal_type must be sale or rent")
+		v.oneOf("/deal_type", []string{"sale", "rent"})
 	}
-	if r.PropertyTitle == "" || r.PropertyAddress == "" {
-		return errors.New("property_title and property_address are required")
+	v.requiredText("/property_title", r.PropertyTitle)
+	v.requiredText("/property_address", r.ResolvedPropertyAddress())
+	r.Property.validate(&v)
+	validateContractParties(&v, "/sellers", r.Sellers, "/seller", &r.Seller)
+	validateContractParties(&v, "/buyers", r.Buyers, "/buyer", &r.Buyer)
+	switch r.DealType {
+	case "rent":
+		v.positive("/rental_terms/monthly_rent", r.RentalTerms.MonthlyRent)
+	case "sale":
+		r.validateSaleTerms(&v)
 	}
-	if r.Seller.Name == "" || r.Buyer.Name == "" {
-		return errors.New("seller.name and buyer.name are required")
+	return v.err()
+}
+
+func (r *ContractRequest) validateSaleTerms(v *validator) {
+	v.nonNegative("/sale_value", r.SaleValue)
+	installments := r.SaleTerms.resolveInstallments("/sale_terms")
+	validateInstallments(v, installments.Source, installments.Value)
+	if r.SaleValue > 0 && math.Abs(r.ResolvedSalePayments().Total()-r.SaleValue) > 0.01 {
+		v.add("/sale_terms", CodeSumMismatch, "A soma das condições de pagamento deve ser igual ao valor de venda.", r.SaleValue)
+	}
+}
+
+// ResolvedDocumentState is the requested state, a draft by default: until
+// states existed every contract was an unsigned draft.
+func (r *ContractRequest) ResolvedDocumentState() string {
+	if r.DocumentState == "" {
+		return DocumentStateDraft
+	}
+	return r.DocumentState
+}
+
+func (r *ContractRequest) References() DocumentReferences {
+	dealType := "sale"
+	if r.DealType == "rent" {
+		dealType = "rent"
+	}
+	return DocumentReferences{
+		Document:          DocumentTypeContract,
+		DealType:          dealType,
+		BrandingProfileID: r.BrandingProfileID,
+		TemplateVersion:   r.TemplateVersion,
+	}
+}
+
+// ResolvedSalePayments applies the proposal payment aliases to SaleTerms.
+func (r *ContractRequest) ResolvedSalePayments() PaymentValues {
+	return r.SaleTerms.resolve("/sale_terms").Values()
+}
+
+// ResolvedSaleValue is the declared sale value or, when absent, the sum of
+// the payment components.
+func (r *ContractRequest) ResolvedSaleValue() float64 {
+	return r.resolveSaleValue().Value
+}
+
+func (r *ContractRequest) resolveSaleValue() Resolved[float64] {
+	return firstPositiveOf(from("/sale_value", r.SaleValue), from("/sale_terms", r.SaleTerms.resolve("/sale_terms").Total))
+}
+
+// Resolve returns the values the contract renderer prints, with the field
+// each one came from. Call it after Validate.
+func (r *ContractRequest) Resolve() ContractResolution {
+	resolution := ContractResolution{
+		DealType:        r.DealType,
+		PropertyTitle:   r.PropertyTitle,
+		PropertyAddress: r.resolvePropertyAddress(),
+		Property:        r.Property,
+		Sellers:         resolvePartyList("/sellers", r.Sellers, "/seller", r.Seller),
+		Buyers:          resolvePartyList("/buyers", r.Buyers, "/buyer", r.Buyer),
 	}
-	if r.DealType == "rent" && r.RentalTerms.MonthlyRent <= 0 {
-		return errors.New("rental_terms.monthly_rent must be greater than zero")
+	if r.DealType == "rent" {
+		terms := r.RentalTerms
+		resolution.RentalTerms = &terms
+		return resolution
 	}
-	return nil
+	saleValue := r.resolveSaleValue()
+	saleTerms := r.SaleTerms.resolve("/sale_terms")
+	resolution.SaleValue = &saleValue
+	resolution.SaleTerms = &saleTerms
+	return resolution
 }
@@ -0,0 +1,209 @@
+package domain
+
+import "testing"
+
+func TestContractValidationRequiresCompanyRepresentative(t *testing.T) {
+	req := ContractRequest{
+		DealType:        "sale",
+		PropertyTitle:   "Casa",
+		PropertyAddress: "Rua A, 10",
+		Seller:          ContractParty{Kind: "company", LegalName: "Holding Ltda", CNPJ: "11222333000181"},
+		Buyer:           ContractParty{Name: "Comprador"},
+	}
+
+	requireFieldError(t, req.Validate(), "/seller/representatives", CodeRequired)
+
+	req.Seller.Representatives = []LegalRepresentative{{Name: "Maria Souza", CPF: "52998224725", Role: "sócia"}}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if req.Seller.CNPJ != "11.222.333/0001-81" || req.Seller.Representatives[0].CPF != "529.982.247-25" {
+		t.Fatalf("expected normalized company documents, got %q and %q", req.Seller.CNPJ, req.Seller.Representatives[0].CPF)
+	}
+}
+
+func TestContractValidationRejectsCPFInCompanyCNPJField(t *testing.T) {
+	req := ContractRequest{
+		DealType:        "sale",
+		PropertyTitle:   "Casa",
+		PropertyAddress: "Rua A, 10",
+		Seller: ContractParty{
+			Kind:            "company",
+			LegalName:       "Holding Ltda",
+			CNPJ:            "529.982.247-25",
+			Representatives: []LegalRepresentative{{Name: "Maria Souza"}},
+		},
+		Buyer: ContractParty{Name: "Comprador"},
+	}
+
+	got := requireFieldError(t, req.Validate(), "/seller/cnpj", CodeInvalidDocument)
+	if got.Limit != DocumentKindCNPJ {
+		t.Fatalf("expected the CNPJ kind as limit, got %v", got.Limit)
+	}
+}
+
+func TestContractValidationRejectsUnknownPartyKind(t *testing.T) {
+	req := ContractRequest{
+		DealType:        "sale",
+		PropertyTitle:   "Casa",
+		PropertyAddress: "Rua A, 10",
+		Sellers:         []ContractParty{{Name: "Vendedor", Kind: "PJ"}, {Name: "Outra", Kind: "empresa"}},
+		Buyers:          []ContractParty{{Name: "Comprador", Kind: "juridica"}},
+	}
+
+	err := req.Validate()
+	requireFieldError(t, err, "/sellers/1/kind", CodeInvalidChoice)
+	requireFieldError(t, err, "/buyers/0/kind", CodeInvalidChoice)
+	if err.(ValidationErrors).Has("/sellers/0/kind") {
+		t.Fatalf("expected the pj alias to be accepted, got %v", err)
+	}
+}
+
+func TestContractValidationRequiresSpouseUnderCommunityRegime(t *testing.T) {
+	req := ContractRequest{
+		DealType:        "sale",
+		PropertyTitle:   "Casa",
+		PropertyAddress: "Rua A, 10",
+		Sellers: []ContractParty{
+			{Name: "Vendedor Um"},
+			{Name: "Vendedor Dois", MaritalStatus: "Casada"},
+		},
+		Buyers: []ContractParty{{Name: "Comprador"}},
+	}
+
+	requireFieldError(t, req.Validate(), "/sellers/1/spouse", CodeRequired)
+
+	req.Sellers[1].Spouse = &ContractSpouse{Name: "Cônjuge", CPF: "52998224725"}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := req.ResolvedSellers()[1].Spouse.CPF; got != "529.982.247-25" {
+		t.Fatalf("expected normalized spouse CPF, got %q", got)
+	}
+}
+
+func TestContractValidationDoesNotRequireSpouseUnderTotalSeparation(t *testing.T) {
+	req := ContractRequest{
+		DealType:        "sale",
+		PropertyTitle:   "Casa",
+		PropertyAddress: "Rua A, 10",
+		Seller:          ContractParty{Name: "Vendedor", MaritalStatus: "casado", PropertyRegime: "separacao_total"},
+		Buyer:           ContractParty{Name: "Comprador"},
+	}
+
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+}
+
+func TestContractValidationAcceptsAccentedPropertyRegime(t *testing.T) {
+	req := ContractRequest{
+		DealType:        "sale",
+		PropertyTitle:   "Casa",
+		PropertyAddress: "Rua A, 10",
+		Seller:          ContractParty{Name: "Vendedor", MaritalStatus: "Casado", PropertyRegime: "Separação Total de Bens"},
+		Buyers: []ContractParty{{
+			Name: "Comprador", MaritalStatus: "casada", PropertyRegime: "comunhão parcial",
+			Spouse: &ContractSpouse{Name: "Cônjuge", CPF: "52998224725"},
+		}},
+	}
+
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if req.Seller.PropertyRegime != PropertyRegimeTotalSeparation || req.Buyers[0].PropertyRegime != PropertyRegimePartialCommunity {
+		t.Fatalf("expected canonical regimes, got %q and %q", req.Seller.PropertyRegime, req.Buyers[0].PropertyRegime)
+	}
+}
+
+func TestContractValidationRejectsRegimeForSingleParty(t *testing.T) {
+	req := ContractRequest{
+		DealType:        "sale",
+		PropertyTitle:   "Casa",
+		PropertyAddress: "Rua A, 10",
+		Seller:          ContractParty{Name: "Vendedor"},
+		Buyers:          []ContractParty{{Name: "Comprador", MaritalStatus: "solteiro", PropertyRegime: "comunhao_parcial"}},
+	}
+
+	requireFieldError(t, req.Validate(), "/buyers/0/property_regime", CodeNotApplicable)
+}
+
+func TestContractValidationAcceptsStructuredPropertyAddress(t *testing.T) {
+	req := ContractRequest{
+		DealType:      "sale",
+		PropertyTitle: "Casa",
+		Property: ContractProperty{
+			RegistryNumber: "12.345",
+			Address:        FlexibleAddress{Street: "Rua B", Number: "20", City: "Rio Verde", State: "go"},
+		},
+		Seller: ContractParty{Name: "Vendedor"},
+		Buyer:  ContractParty{Name: "Comprador"},
+	}
+
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := req.ResolvedPropertyAddress(); got != "Rua B, Nº 20, Rio Verde, GO" {
+		t.Fatalf("expected structured address, got %q", got)
+	}
+
+	req.Property.ParkingSpaces = -1
+	requireFieldError(t, req.Validate(), "/property/parking_spaces", CodeNonNegative)
+}
+
+func TestContractDocumentStateDefaultsToDraft(t *testing.T) {
+	req := ContractRequest{
+		DealType:        "sale",
+		PropertyTitle:   "Casa",
+		PropertyAddress: "Rua A, 10",
+		Seller:          ContractParty{Name: "Vendedor"},
+		Buyer:           ContractParty{Name: "Comprador"},
+		DocumentState:   " For_Signature ",
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := req.ResolvedDocumentState(); got != DocumentStateForSignature {
+		t.Fatalf("expected the normalized state, got %q", got)
+	}
+
+	req.DocumentState = ""
+	if got := req.ResolvedDocumentState(); got != DocumentStateDraft {
+		t.Fatalf("expected contracts to default to drafts, got %q", got)
+	}
+
+	req.DocumentState = "signed"
+	requireFieldError(t, req.Validate(), "/document_state", CodeInvalidChoice)
+}
+
+func TestSignIsOnlyAcceptedOnFinalDocuments(t *testing.T) {
+	contract := ContractRequest{
+		DealType:        "sale",
+		PropertyTitle:   "Casa",
+		PropertyAddress: "Rua A, 10",
+		Seller:          ContractParty{Name: "Vendedor"},
+		Buyer:           ContractParty{Name: "Comprador"},
+		Sign:            true,
+	}
+	for _, state := range []string{"", DocumentStateDraft, DocumentStateForSignature} {
+		contract.DocumentState = state
+		requireFieldError(t, contract.Validate(), "/sign", CodeNotApplicable)
+	}
+	contract.DocumentState = DocumentStateFinal
+	if err := contract.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	proposal := ProposalRequest{
+		ClientName:            "Ana Silva",
+		PropertyAddressLegacy: "Rua A, 10",
+		TotalValue:            100000,
+		Payment:               PaymentBreakdown{Cash: 100000},
+		Sign:                  true,
+	}
+	if err := proposal.Validate(); err != nil {
+		t.Fatalf("expected proposals, final by default, to be signable: %v", err)
+	}
+	proposal.DocumentState = DocumentStateDraft
+	requireFieldError(t, proposal.Validate(), "/sign", CodeNotApplicable)
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GeneratedDocument is a rendered PDF together with the provenance of the
+// template that laid it out. VerificationID is the ID the document is
+// registered under, empty when documents are not registered. Cache is
+// CacheHit or CacheMiss when the document went through the render cache
+// and empty otherwise.
+type GeneratedDocument struct {
+	PDF            []byte
+	Template       TemplateProvenance
+	VerificationID string
+	Cache          string
+}
+
+// Document states. A draft is still under negotiation, a document for
+// signature is the copy the parties initial and sign, and a final document
+// is the agreed text.
+const (
+	DocumentStateDraft        = "draft"
+	DocumentStateForSignature = "for_signature"
+	DocumentStateFinal        = "final"
+)
+
+var documentStateChoices = []string{DocumentStateDraft, DocumentStateForSignature, DocumentStateFinal}
+
+// Document types, as references name them.
+const (
+	DocumentTypeProposal            = "proposal"
+	DocumentTypeContract            = "contract"
+	DocumentTypeReceipt             = "receipt"
+	DocumentTypeFinancingSimulation = "financing_simulation"
+)
+
+// DocumentReferences are the parts of the service configuration a request
+// names: a branding profile, and a template version of its document and
+// deal type. Validate cannot tell whether they exist; the service checks
+// them.
+type DocumentReferences struct {
+	Document          string
+	DealType          string
+	BrandingProfileID string
+	TemplateVersion   string
+}
+
+// Render cache outcomes reported in GeneratedDocument.Cache.
+const (
+	CacheHit  = "HIT"
+	CacheMiss = "MISS"
+)
+
+// TemplateProvenance identifies the template revision behind a document.
+// Hash is the hex SHA-256 of the template source, so two revisions that
+// share a version number can still be told apart.
+type TemplateProvenance struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+	Hash    string `json:"hash"`
+}
+
+// String formats the provenance as "id@version".
+func (p TemplateProvenance) String() string {
+	return p.ID + "@" + p.Version
+}
+
+var semanticVersionPattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)$`)
+
+// IsSemanticVersion reports whether value is a MAJOR.MINOR.PATCH version.
+func IsSemanticVersion(value string) bool {
+	return semanticVersionPattern.MatchString(value)
+}
+
+// CompareSemanticVersions orders two valid semantic versions, returning a
+// negative number when a precedes b, zero when equal and positive otherwise.
+func CompareSemanticVersions(a, b string) int {
+	partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < 3 && i < len(partsA) && i < len(partsB); i++ {
+		numberA, _ := strconv.Atoi(partsA[i])
+		numberB, _ := strconv.Atoi(partsB[i])
+		if numberA != numberB {
+			return numberA - numberB
+		}
+	}
+	return 0
+}
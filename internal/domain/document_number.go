@@ -0,0 +1,82 @@
+package domain
+
+import "strings"
+
+type DocumentKind string
+
+const (
+	DocumentKindCPF  DocumentKind = "cpf"
+	DocumentKindCNPJ DocumentKind = "cnpj"
+)
+
+// DocumentNumber is a Brazilian taxpayer number (CPF for individuals, CNPJ
+// for companies) whose check digits have been verified.
+type DocumentNumber struct {
+	Kind   DocumentKind
+	Digits string
+}
+
+// ParseDocumentNumber accepts a CPF or CNPJ with or without punctuation.
+func ParseDocumentNumber(value string) (DocumentNumber, bool) {
+	var digits strings.Builder
+	for _, r := range strings.TrimSpace(value) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '.', r == '-', r == '/', r == ' ':
+		default:
+			return DocumentNumber{}, false
+		}
+	}
+
+	number := digits.String()
+	switch {
+	case len(number) == 11 && !isRepeatedDigit(number) && hasValidCPFCheckDigits(number):
+		return DocumentNumber{Kind: DocumentKindCPF, Digits: number}, true
+	case len(number) == 14 && !isRepeatedDigit(number) && hasValidCNPJCheckDigits(number):
+		return DocumentNumber{Kind: DocumentKindCNPJ, Digits: number}, true
+	default:
+		return DocumentNumber{}, false
+	}
+}
+
+// String returns the masked form used in documents:
+// 000.000.000-00 for CPF and 00.000.000/0000-00 for CNPJ.
+func (d DocumentNumber) String() string {
+	switch d.Kind {
+	case DocumentKindCPF:
+		return d.Digits[0:3] + "." + d.Digits[3:6] + "." + d.Digits[6:9] + "-" + d.Digits[9:11]
+	case DocumentKindCNPJ:
+		return d.Digits[0:2] + "." + d.Digits[2:5] + "." + d.Digits[5:8] + "/" + d.Digits[8:12] + "-" + d.Digits[12:14]
+	default:
+		return d.Digits
+	}
+}
+
+func isRepeatedDigit(number string) bool {
+	return strings.Count(number, number[:1]) == len(number)
+}
+
+func hasValidCPFCheckDigits(number string) bool {
+	first := documentCheckDigit(number[:9], []int{10, 9, 8, 7, 6, 5, 4, 3, 2})
+	second := documentCheckDigit(number[:10], []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2})
+	return int(number[9]-'0') == first && int(number[10]-'0') == second
+}
+
+func hasValidCNPJCheckDigits(number string) bool {
+	first := documentCheckDigit(number[:12], []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2})
+	second := documentCheckDigit(number[:13], []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2})
+	return int(number[12]-'0') == first && int(number[13]-'0') == second
+}
+
+func documentCheckDigit(digits string, weights []int) int {
+	sum := 0
+	for i, weight := range weights {
+		sum += int(digits[i]-'0') * weight
+	}
+	remainder := sum % 11
+	if remainder < 2 {
+		return 0
+	}
+	return 11 - remainder
+}
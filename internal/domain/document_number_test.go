@@ -0,0 +1,72 @@
+package domain
+
+import "testing"
+
+func TestParseDocumentNumberNormalizesValidNumbers(t *testing.T) {
+	cases := []struct {
+		input string
+		kind  DocumentKind
+		want  string
+	}{
+		{"52998224725", DocumentKindCPF, "529.982.247-25"},
+		{"529.982.247-25", DocumentKindCPF, "529.982.247-25"},
+		{" 529 982 247 25 ", DocumentKindCPF, "529.982.247-25"},
+		{"11222333000181", DocumentKindCNPJ, "11.222.333/0001-81"},
+		{"11.222.333/0001-81", DocumentKindCNPJ, "11.222.333/0001-81"},
+	}
+
+	for _, tc := range cases {
+		got, ok := ParseDocumentNumber(tc.input)
+		if !ok {
+			t.Errorf("ParseDocumentNumber(%q) rejected a valid number", tc.input)
+			continue
+		}
+		if got.Kind != tc.kind || got.String() != tc.want {
+			t.Errorf("ParseDocumentNumber(%q) = %s %q, want %s %q", tc.input, got.Kind, got.String(), tc.kind, tc.want)
+		}
+	}
+}
+
+func TestParseDocumentNumberRejectsInvalidNumbers(t *testing.T) {
+	for _, input := range []string{
+		"529.982.247-24",
+		"111.111.111-11",
+		"11.222.333/0001-80",
+		"00.000.000/0000-00",
+		"1234567890",
+		"529.982.247-2X",
+	} {
+		if _, ok := ParseDocumentNumber(input); ok {
+			t.Errorf("ParseDocumentNumber(%q) accepted an invalid number", input)
+		}
+	}
+}
+
+func TestContractValidationReportsInvalidPartyDocument(t *testing.T) {
+	req := ContractRequest{
+		DealType:        "sale",
+		PropertyTitle:   "Casa",
+		PropertyAddress: "Rua A, 10",
+		Seller:          ContractParty{Name: "Vendedor", CPF: "529.982.247-25"},
+		Buyer:           ContractParty{Name: "Comprador", CPF: "123.456.789-00"},
+	}
+
+	requireFieldError(t, req.Validate(), "/buyer/cpf", CodeInvalidDocument)
+}
+
+func TestProposalValidationNormalizesClientDocument(t *testing.T) {
+	req := ProposalRequest{
+		ClientName:            "Ana Silva",
+		ClientCPF:             "52998224725",
+		PropertyAddressLegacy: "Rua A, 10",
+		TotalValue:            100,
+		Payment:               PaymentBreakdown{Cash: 100},
+	}
+
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := req.ResolvedClientCPF(); got != "529.982.247-25" {
+		t.Fatalf("expected masked CPF, got %q", got)
+	}
+}
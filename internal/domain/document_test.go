@@ -0,0 +1,31 @@
+package domain
+
+import "testing"
+
+func TestCompareSemanticVersionsComparesNumerically(t *testing.T) {
+	if CompareSemanticVersions("1.10.0", "1.9.3") <= 0 {
+		t.Fatal("expected 1.10.0 to be newer than 1.9.3")
+	}
+	if CompareSemanticVersions("2.0.0", "2.0.0") != 0 {
+		t.Fatal("expected equal versions to compare equal")
+	}
+	if CompareSemanticVersions("0.9.9", "1.0.0") >= 0 {
+		t.Fatal("expected 0.9.9 to be older than 1.0.0")
+	}
+}
+
+func TestValidateRejectsMalformedTemplateVersion(t *testing.T) {
+	req := ProposalRequest{
+		ClientName:      "Ana Silva",
+		PropertyAddress: FlexibleAddress{Raw: "Rua A, 10"},
+		TotalValue:      100,
+		Payment:         PaymentBreakdown{Cash: 100},
+		TemplateVersion: "v1",
+	}
+	requireFieldError(t, req.Validate(), "/template_version", CodeInvalidFormat)
+
+	req.TemplateVersion = "1.2.0"
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+}
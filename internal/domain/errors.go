@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownBrandingProfile is returned by the renderers when a request
+// names a branding profile that is not configured.
+var ErrUnknownBrandingProfile = errors.New("branding_profile_id does not match a configured profile")
+
+// ErrUnknownTemplateVersion is returned by the renderers when a request
+// pins a template version that is not registered.
+var ErrUnknownTemplateVersion = errors.New("template_version does not match a registered template")
+
+// UnknownBrandingProfileError is ErrUnknownBrandingProfile reported as a
+// field error at /branding_profile_id, so clients get it in the list of
+// the other problems of the request.
+func UnknownBrandingProfileError() error {
+	return errors.Join(ErrUnknownBrandingProfile, ValidationErrors{{
+		Path:    "/branding_profile_id",
+		Code:    CodeInvalidChoice,
+		Message: "Nenhum perfil de marca configurado tem este ID.",
+	}})
+}
+
+// UnknownTemplateVersionError is ErrUnknownTemplateVersion, described by
+// detail, reported as a field error at /template_version.
+func UnknownTemplateVersionError(detail string) error {
+	return errors.Join(fmt.Errorf("%w: %s", ErrUnknownTemplateVersion, detail), ValidationErrors{{
+		Path:    "/template_version",
+		Code:    CodeInvalidChoice,
+		Message: "Nenhum modelo registrado tem esta versão.",
+	}})
+}
@@ -0,0 +1,104 @@
+package domain
+
+import "strings"
+
+const (
+	AmortizationSAC   = "sac"
+	AmortizationPrice = "price"
+
+	maxFinancingTermMonths = 420
+)
+
+var amortizationSystemAliases = map[string]string{
+	"sac": AmortizationSAC, "tabela sac": AmortizationSAC, "sistema sac": AmortizationSAC,
+	"price": AmortizationPrice, "tabela price": AmortizationPrice, "sistema price": AmortizationPrice,
+}
+
+// FinancingSimulationRequest describes a bank financing scenario. Entry has
+// the same meaning as PaymentValues.Cash: the down payment made in money.
+type FinancingSimulationRequest struct {
+	ClientName         string  `json:"client_name"`
+	PropertyTitle      string  `json:"property_title"`
+	PropertyValue      float64 `json:"property_value"`
+	Entry              float64 `json:"entry"`
+	Cash               float64 `json:"cash"`
+	Dinheiro           float64 `json:"dinheiro"`
+	AnnualInterestRate float64 `json:"annual_interest_rate"`
+	TermMonths         int     `json:"term_months"`
+	System             string  `json:"system"`
+
+	BrandingProfileID string `json:"branding_profile_id"`
+	TemplateVersion   string `json:"template_version"`
+	IssueDate         string `json:"issue_date"`
+	Sign              bool   `json:"sign"`
+}
+
+func (r *FinancingSimulationRequest) Sanitize() {
+	r.ClientName = sanitizeText(r.ClientName)
+	r.PropertyTitle = sanitizeText(r.PropertyTitle)
+	r.System = strings.ToLower(sanitizeText(r.System))
+	r.BrandingProfileID = sanitizeText(r.BrandingProfileID)
+	r.TemplateVersion = sanitizeText(r.TemplateVersion)
+}
+
+// ResolvedEntry accepts the entry under the payment breakdown aliases.
+func (r *FinancingSimulationRequest) ResolvedEntry() float64 {
+	return firstPositive(r.Entry, r.Cash, r.Dinheiro)
+}
+
+// ResolvedSystem maps "Tabela Price" and the other known spellings to the
+// canonical amortization system identifiers. Anything else is returned as
+// is, for Validate to reject.
+func (r *FinancingSimulationRequest) ResolvedSystem() string {
+	if system, ok := amortizationSystemAliases[r.System]; ok {
+		return system
+	}
+	return r.System
+}
+
+// FinancedAmount is the principal borrowed from the bank.
+func (r *FinancingSimulationRequest) FinancedAmount() float64 {
+	return r.PropertyValue - r.ResolvedEntry()
+}
+
+func (r *FinancingSimulationRequest) Validate() error {
+	r.Sanitize()
+	var v validator
+	v.templateVersion(r.TemplateVersion)
+	v.date("/issue_date", r.IssueDate)
+	v.maxLength("/client_name", r.ClientName, maxClientNameLength)
+	v.maxLength("/property_title", r.PropertyTitle, maxPropertyAddressLength)
+	v.positive("/property_value", r.PropertyValue)
+	v.nonNegative("/entry", r.Entry)
+	v.nonNegative("/cash", r.Cash)
+	v.nonNegative("/dinheiro", r.Dinheiro)
+	if r.PropertyValue > 0 && r.FinancedAmount() <= 0 {
+		v.add(r.entryPath(), CodeTooLarge, "A entrada deve ser menor que o valor do imóvel.", r.PropertyValue)
+	}
+	v.inRange("/annual_interest_rate", r.AnnualInterestRate, 0, 100)
+	v.inRange("/term_months", float64(r.TermMonths), 1, maxFinancingTermMonths)
+	if system := r.ResolvedSystem(); system != AmortizationSAC && system != AmortizationPrice {
+		v.oneOf("/system", []string{AmortizationSAC, AmortizationPrice})
+	}
+	return v.err()
+}
+
+func (r *FinancingSimulationRequest) References() DocumentReferences {
+	return DocumentReferences{
+		Document:          DocumentTypeFinancingSimulation,
+		BrandingProfileID: r.BrandingProfileID,
+		TemplateVersion:   r.TemplateVersion,
+	}
+}
+
+// entryPath points at the alias ResolvedEntry reads from.
+func (r *FinancingSimulationRequest) entryPath() string {
+	switch {
+	case r.Entry <= 0 && r.Cash > 0:
+		return "/cash"
+	case r.Entry <= 0 && r.Dinheiro > 0:
+		return "/dinheiro"
+	default:
+		return "/entry"
+	}
+}
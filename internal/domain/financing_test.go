@@ -0,0 +1,49 @@
+package domain
+
+import "testing"
+
+func TestFinancingSimulationValidationResolvesEntryAndSystem(t *testing.T) {
+	req := FinancingSimulationRequest{
+		PropertyValue:      400000,
+		Dinheiro:           80000,
+		AnnualInterestRate: 10.5,
+		TermMonths:         360,
+		System:             " Tabela Price ",
+	}
+
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := req.ResolvedSystem(); got != AmortizationPrice {
+		t.Fatalf("expected price system, got %q", got)
+	}
+	if got := req.FinancedAmount(); got != 320000 {
+		t.Fatalf("expected financed amount 320000, got %v", got)
+	}
+}
+
+func TestFinancingSimulationValidationRejectsEntryCoveringWholeValue(t *testing.T) {
+	req := FinancingSimulationRequest{
+		PropertyValue:      400000,
+		Entry:              400000,
+		AnnualInterestRate: 10.5,
+		TermMonths:         360,
+		System:             "sac",
+	}
+
+	requireFieldError(t, req.Validate(), "/entry", CodeTooLarge)
+}
+
+func TestFinancingSimulationValidationRejectsSystemsThatOnlyContainAnAlias(t *testing.T) {
+	for _, system := range []string{"sacola", "priceless", "sac price"} {
+		req := FinancingSimulationRequest{
+			PropertyValue:      400000,
+			Entry:              80000,
+			AnnualInterestRate: 10.5,
+			TermMonths:         360,
+			System:             system,
+		}
+
+		requireFieldError(t, req.Validate(), "/system", CodeInvalidChoice)
+	}
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Installment is one entry of a sale payment schedule (parcela), optionally
+// corrected by a price index until it is paid.
+type Installment struct {
+	DueDate     string  `json:"due_date"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+	Index       string  `json:"index"`
+}
+
+var installmentIndexes = map[string]string{
+	"INCC":   "INCC",
+	"IGP-M":  "IGP-M",
+	"IGPM":   "IGP-M",
+	"IPCA":   "IPCA",
+	"INPC":   "INPC",
+	"IGP-DI": "IGP-DI",
+	"IGPDI":  "IGP-DI",
+}
+
+const maxInstallments = 480
+
+func (i *Installment) Sanitize() {
+	i.DueDate = sanitizeText(i.DueDate)
+	i.Description = sanitizeText(i.Description)
+	i.Index = strings.ToUpper(sanitizeText(i.Index))
+	if canonical, ok := installmentIndexes[i.Index]; ok {
+		i.Index = canonical
+	}
+}
+
+func sumInstallments(installments []Installment) float64 {
+	total := 0.0
+	for _, installment := range installments {
+		total += installment.Amount
+	}
+	return total
+}
+
+// installmentIndexChoices lists the canonical price indexes for error
+// messages.
+var installmentIndexChoices = []string{"INCC", "IGP-M", "IPCA", "INPC", "IGP-DI"}
+
+func validateInstallments(v *validator, path string, installments []Installment) {
+	if len(installments) > maxInstallments {
+		v.add(path, CodeMaxItems, fmt.Sprintf("Informe no máximo %d parcelas.", maxInstallments), maxInstallments)
+	}
+	for i, installment := range installments {
+		itemPath := fmt.Sprintf("%s/%d", path, i)
+		v.positive(itemPath+"/amount", installment.Amount)
+		v.requiredDate(itemPath+"/due_date", installment.DueDate)
+		v.maxLength(itemPath+"/description", installment.Description, 120)
+		if _, ok := installmentIndexes[installment.Index]; installment.Index != "" && !ok {
+			v.oneOf(itemPath+"/index", installmentIndexChoices)
+		}
+	}
+}
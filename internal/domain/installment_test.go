@@ -0,0 +1,68 @@
+package domain
+
+import "testing"
+
+func TestProposalValidationIncludesInstallmentsInPaymentSum(t *testing.T) {
+	req := ProposalRequest{
+		ClientName:            "Ana Silva",
+		PropertyAddressLegacy: "Rua A, 10",
+		TotalValue:            100000,
+		Payment: PaymentBreakdown{
+			Cash: 40000,
+			Installments: []Installment{
+				{DueDate: "2026-05-10", Amount: 30000, Index: "igpm"},
+				{DueDate: "2026-06-10", Amount: 30000, Index: "INCC"},
+			},
+		},
+	}
+
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := req.ResolvedPayments().Installments[0].Index; got != "IGP-M" {
+		t.Fatalf("expected canonical index, got %q", got)
+	}
+
+	req.Payment.Installments[1].Amount = 20000
+	requireFieldError(t, req.Validate(), "/payment", CodeSumMismatch)
+}
+
+func TestProposalValidationRejectsInvalidInstallment(t *testing.T) {
+	req := ProposalRequest{
+		ClientName:            "Ana Silva",
+		PropertyAddressLegacy: "Rua A, 10",
+		TotalValue:            100000,
+		Payment: PaymentBreakdown{
+			Cash:     40000,
+			Parcelas: []Installment{{DueDate: "2026-13-10", Amount: 60000}},
+		},
+	}
+
+	requireFieldError(t, req.Validate(), "/payment/parcelas/0/due_date", CodeInvalidDate)
+
+	req.Payment.Parcelas[0].DueDate = "2026-12-10"
+	req.Payment.Parcelas[0].Index = "SELIC"
+	requireFieldError(t, req.Validate(), "/payment/parcelas/0/index", CodeInvalidChoice)
+}
+
+func TestContractValidationMatchesScheduleAgainstSaleValue(t *testing.T) {
+	req := ContractRequest{
+		DealType:        "sale",
+		PropertyTitle:   "Casa",
+		PropertyAddress: "Rua A, 10",
+		Seller:          ContractParty{Name: "Vendedor"},
+		Buyer:           ContractParty{Name: "Comprador"},
+		SaleValue:       300000,
+		SaleTerms: PaymentBreakdown{
+			Cash:         100000,
+			Installments: []Installment{{DueDate: "2026-05-10", Amount: 100000}},
+		},
+	}
+
+	requireFieldError(t, req.Validate(), "/sale_terms", CodeSumMismatch)
+
+	req.SaleTerms.Installments = append(req.SaleTerms.Installments, Installment{DueDate: "2026-06-10", Amount: 100000})
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+}
@@ -2,7 +2,6 @@ package domain
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"math"
 	"regexp"
@@ -74,13 +73,36 @@ type PaymentBreakdown struct {
 	Permuta       float64 `json:"permuta"`
 	Financiamento float64 `json:"financiamento"`
 	Outros        float64 `json:"outros"`
+
+	Installments []Installment `json:"installments"`
+	Parcelas     []Installment `json:"parcelas"`
 }
 
 type PaymentValues struct {
-	Cash      float64
-	TradeIn   float64
-	Financing float64
-	Others    float64
+	Cash         float64
+	TradeIn      float64
+	Financing    float64
+	Others       float64
+	Installments []Installment
+}
+
+// Total sums the lump-sum components and the installment schedule.
+func (v PaymentValues) Total() float64 {
+	return v.Cash + v.TradeIn + v.Financing + v.Others + sumInstallments(v.Installments)
+}
+
+// ResolvedInstallments prefers the English key and falls back to "parcelas".
+func (b *PaymentBreakdown) ResolvedInstallments() []Installment {
+	return b.resolveInstallments("").Value
+}
+
+func (b *PaymentBreakdown) Sanitize() {
+	for i := range b.Installments {
+		b.Installments[i].Sanitize()
+	}
+	for i := range b.Parcelas {
+		b.Parcelas[i].Sanitize()
+	}
 }
 
 // RentalTerms carries commercial conditions that are specific to a lease.
@@ -108,6 +130,8 @@ type ProposalRequest struct {
 	PaymentMethodLegacy   string  `json:"payment_method"`
 	ValidityDaysLegacy    int     `json:"validity_days"`
 
+	ProposalID string `json:"proposal_id"`
+
 	ClientName       string           `json:"clientName"`
 	ClientCPF        string           `json:"clientCpf"`
 	PropertyAddress  FlexibleAddress  `json:"propertyAddress"`
@@ -121,9 +145,16 @@ type ProposalRequest struct {
 
 	PropertyCity  string `json:"propertyCity"`
 	PropertyState string `json:"propertyState"`
+
+	BrandingProfileID string `json:"branding_profile_id"`
+	TemplateVersion   string `json:"template_version"`
+	IssueDate         string `json:"issue_date"`
+	Sign              bool   `json:"sign"`
+	DocumentState     string `json:"document_state"`
 }
 
 const (
+	maxProposalIDLength      = 100
 	maxClientNameLength      = 100
 	maxClientCPFLength       = 20
 	maxPropertyAddressLength = 255
@@ -135,113 +166,74 @@ const (
 
 func (p *ProposalRequest) Validate() error {
 	p.Sanitize()
-
-	if err := validateMaxLength("client_name", p.ResolvedClientName(), maxClientNameLength); err != nil {
-		return err
-	}
-	if err := validateMaxLength("client_cpf", p.ResolvedClientCPF(), maxClientCPFLength); err != nil {
-		return err
-	}
-	if err := validateMaxLength("property_address", p.ResolvedPropertyAddress(), maxPropertyAddressLength); err != nil {
-		return err
-	}
-	if err := validateMaxLength("broker_name", p.ResolvedBrokerName(), maxBrokerNameLength); err != nil {
-		return err
-	}
-	if err := validateMaxLength("property_city", p.ResolvedCity(), maxCityLength); err != nil {
-		return err
-	}
-	if err := validateMaxLength("property_state", p.ResolvedState(), maxStateLength); err != nil {
-		return err
-	}
-	if err := validateMaxLength("payment_method", p.PaymentMethodLegacy, maxPaymentMethodLength); err != nil {
-		return err
-	}
+	var v validator
+	v.templateVersion(p.TemplateVersion)
+	v.date("/issue_date", p.IssueDate)
+	v.documentState(p.DocumentState)
+	v.signedState(p.Sign, p.ResolvedDocumentState())
+
+	clientName := p.resolveClientName()
+	address := p.resolvePropertyAddress()
+	terms := p.resolveRentalTerms()
+	v.maxLength("/proposal_id", p.ProposalID, maxProposalIDLength)
+	v.maxLength(pathOr(clientName, "/clientName"), clientName.Value, maxClientNameLength)
+	v.maxLength(pathOr(p.resolveClientCPF(), "/clientCpf"), p.ResolvedClientCPF(), maxClientCPFLength)
+	v.maxLength(pathOr(address, "/propertyAddress"), address.Value, maxPropertyAddressLength)
+	v.maxLength(pathOr(p.resolveBrokerName(), "/brokerName"), p.ResolvedBrokerName(), maxBrokerNameLength)
+	v.maxLength(pathOr(p.resolveCity(), "/propertyAddress/city"), p.ResolvedCity(), maxCityLength)
+	v.maxLength(pathOr(p.resolveState(), "/propertyAddress/state"), p.ResolvedState(), maxStateLength)
+	v.maxLength("/payment_method", p.PaymentMethodLegacy, maxPaymentMethodLength)
 	for _, field := range []struct {
-		name  string
-		value string
+		value Resolved[string]
 		limit int
 	}{
-		{"rental_terms.guarantee_type", p.ResolvedRentalTerms().GuaranteeType, 80},
-		{"rental_terms.expected_start_date", p.ResolvedRentalTerms().ExpectedStartDate, 10},
-		{"rental_terms.condominium_responsibility", p.ResolvedRentalTerms().CondominiumResponsibility, 80},
-		{"rental_terms.property_tax_responsibility", p.ResolvedRentalTerms().PropertyTaxResponsibility, 80},
-		{"rental_terms.observations", p.ResolvedRentalTerms().Observations, 1000},
+		{terms.GuaranteeType, 80},
+		{terms.ExpectedStartDate, 10},
+		{terms.CondominiumResponsibility, 80},
+		{terms.PropertyTaxResponsibility, 80},
+		{terms.Observations, 1000},
 	} {
-		if err := validateMaxLength(field.name, field.value, field.limit); err != nil {
-			return err
-		}
+		v.maxLength(field.value.Source, field.value.Value, field.limit)
 	}
 
-	if strings.TrimSpace(p.ResolvedClientName()) == "" {
-		return errors.New("client_name is required")
-	}
-	if strings.TrimSpace(p.ResolvedPropertyAddress()) == "" {
-		return errors.New("property_address is required")
-	}
-	validity := p.ResolvedValidityDays()
-	if validity <= 0 {
-		return errors.New("validity_days must be greater than zero")
-	}
+	v.document("/clientCpf", &p.ClientCPF, "")
+	v.document("/client_cpf", &p.ClientCPFLegacy, "")
+
+	v.requiredText(pathOr(clientName, "/clientName"), clientName.Value)
+	v.requiredText(pathOr(address, "/propertyAddress"), address.Value)
+	v.positive("/validadeDias", float64(p.ResolvedValidityDays()))
 
 	if p.ResolvedDealType() == "rent" {
-		if err := validateRawRentalTerms(p.RentalTerms); err != nil {
-			return err
-		}
-		if err := validateRawRentalTerms(p.RentalTermsCamel); err != nil {
-			return err
-		}
-		terms := p.ResolvedRentalTerms()
-		if terms.MonthlyRent <= 0 {
-			return errors.New("rental_terms.monthly_rent must be greater than zero")
-		}
-		if terms.GuaranteeAmount < 0 {
-			return errors.New("rental_terms.guarantee_amount must not be negative")
-		}
-		if terms.LeaseTermMonths < 0 {
-			return errors.New("rental_terms.lease_term_months must not be negative")
-		}
-		if terms.MonthlyDueDay < 0 || terms.MonthlyDueDay > 31 {
-			return errors.New("rental_terms.monthly_due_day must be between 1 and 31")
-		}
-		if terms.ExpectedStartDate != "" && !isValidISODate(terms.ExpectedStartDate) {
-			return errors.New("rental_terms.expected_start_date must use a valid YYYY-MM-DD date")
-		}
-		return nil
+		validateRawRentalTerms(&v, "/rental_terms", p.RentalTerms)
+		validateRawRentalTerms(&v, "/rentalTerms", p.RentalTermsCamel)
+		v.positive(pathOr(terms.MonthlyRent, "/rental_terms/monthly_rent"), terms.MonthlyRent.Value)
+		v.date(terms.ExpectedStartDate.Source, terms.ExpectedStartDate.Value)
+		return v.err()
 	}
 
-	total := p.ResolvedTotalValue()
-	if total <= 0 {
-		return errors.New("value must be greater than zero")
-	}
+	total := p.resolveTotalValue()
+	v.positive(pathOr(total, "/totalValue"), total.Value)
 
-	payments := p.ResolvedPayments()
-	sum := payments.Cash + payments.TradeIn + payments.Financing + payments.Others
-	if sum <= 0 {
-		return errors.New("payment breakdown is required")
-	}
+	payments := p.resolvePayments()
+	validateInstallments(&v, payments.Installments.Source, payments.Installments.Value)
 
-	if math.Abs(sum-total) > 0.01 {
-		return errors.New("payment breakdown must match total value")
+	switch {
+	case payments.Total <= 0:
+		v.required("/payment")
+	case total.Value > 0 && math.Abs(payments.Total-total.Value) > 0.01:
+		v.add("/payment", CodeSumMismatch, "A soma das formas de pagamento deve ser igual ao valor total.", total.Value)
 	}
 
-	return nil
+	return v.err()
 }
 
-func validateRawRentalTerms(terms RentalTerms) error {
-	if terms.MonthlyRent < 0 {
-		return errors.New("rental_terms.monthly_rent must not be negative")
-	}
-	if terms.GuaranteeAmount < 0 {
-		return errors.New("rental_terms.guarantee_amount must not be negative")
-	}
-	if terms.LeaseTermMonths < 0 {
-		return errors.New("rental_terms.lease_term_months must not be negative")
-	}
-	if terms.MonthlyDueDay < 0 || terms.MonthlyDueDay > 31 {
-		return errors.New("rental_terms.monthly_due_day must be between 1 and 31")
+func validateRawRentalTerms(v *validator, path string, terms RentalTerms) {
+	v.nonNegative(path+"/monthly_rent", terms.MonthlyRent)
+	v.nonNegative(path+"/guarantee_amount", terms.GuaranteeAmount)
+	v.nonNegative(path+"/lease_term_months", float64(terms.LeaseTermMonths))
+	if terms.MonthlyDueDay != 0 {
+		v.inRange(path+"/monthly_due_day", float64(terms.MonthlyDueDay), 1, 31)
 	}
-	return nil
 }
 
 func isValidISODate(value string) bool {
@@ -259,6 +251,7 @@ func (p *ProposalRequest) Sanitize() {
 	p.BrokerNameLegacy = sanitizeText(p.BrokerNameLegacy)
 	p.PaymentMethodLegacy = sanitizeText(p.PaymentMethodLegacy)
 
+	p.ProposalID = sanitizeText(p.ProposalID)
 	p.ClientName = sanitizeText(p.ClientName)
 	p.ClientCPF = sanitizeText(p.ClientCPF)
 	p.BrokerName = sanitizeText(p.BrokerName)
@@ -267,6 +260,10 @@ func (p *ProposalRequest) Sanitize() {
 	p.PropertyState = strings.ToUpper(sanitizeText(p.PropertyState))
 	p.DealTypeLegacy = sanitizeText(p.DealTypeLegacy)
 	p.DealType = sanitizeText(p.DealType)
+	p.DocumentState = strings.ToLower(sanitizeText(p.DocumentState))
+	p.Payment.Sanitize()
+	p.BrandingProfileID = sanitizeText(p.BrandingProfileID)
+	p.TemplateVersion = sanitizeText(p.TemplateVersion)
 	p.RentalTerms.Sanitize()
 	p.RentalTermsCamel.Sanitize()
 }
@@ -290,87 +287,158 @@ func (a *FlexibleAddress) Sanitize() {
 }
 
 func (p *ProposalRequest) ResolvedClientName() string {
-	return firstNonBlank(p.ClientName, p.ClientNameLegacy)
+	return p.resolveClientName().Value
+}
+
+func (p *ProposalRequest) resolveClientName() Resolved[string] {
+	return firstText(from("/clientName", p.ClientName), from("/client_name", p.ClientNameLegacy))
 }
 
 func (p *ProposalRequest) ResolvedClientCPF() string {
-	return firstNonBlank(p.ClientCPF, p.ClientCPFLegacy)
+	return p.resolveClientCPF().Value
+}
+
+func (p *ProposalRequest) resolveClientCPF() Resolved[string] {
+	return firstText(from("/clientCpf", p.ClientCPF), from("/client_cpf", p.ClientCPFLegacy))
 }
 
 func (p *ProposalRequest) ResolvedBrokerName() string {
-	return firstNonBlank(p.BrokerName, p.BrokerNameLegacy)
+	return p.resolveBrokerName().Value
+}
+
+func (p *ProposalRequest) resolveBrokerName() Resolved[string] {
+	return firstText(from("/brokerName", p.BrokerName), from("/broker_name", p.BrokerNameLegacy))
+}
+
+// ResolvedDocumentState is the requested state, final by default.
+func (p *ProposalRequest) ResolvedDocumentState() string {
+	if p.DocumentState == "" {
+		return DocumentStateFinal
+	}
+	return p.DocumentState
 }
 
 func (p *ProposalRequest) ResolvedDealType() string {
-	normalized := strings.ToLower(firstNonBlank(p.DealType, p.DealTypeLegacy))
+	return p.resolveDealType().Value
+}
+
+func (p *ProposalRequest) References() DocumentReferences {
+	return DocumentReferences{
+		Document:          DocumentTypeProposal,
+		DealType:          p.ResolvedDealType(),
+		BrandingProfileID: p.BrandingProfileID,
+		TemplateVersion:   p.TemplateVersion,
+	}
+}
+
+func (p *ProposalRequest) resolveDealType() Resolved[string] {
+	raw := firstText(from("/dealType", p.DealType), from("/deal_type", p.DealTypeLegacy))
+	normalized := strings.ToLower(raw.Value)
 	switch {
 	case strings.Contains(normalized, "alug"), strings.Contains(normalized, "rent"):
-		return "rent"
+		return from(raw.Source, "rent")
 	case strings.Contains(normalized, "vend"), strings.Contains(normalized, "sale"):
-		return "sale"
+		return from(raw.Source, "sale")
 	default:
-		return "sale"
+		return from(SourceDefault, "sale")
 	}
 }
 
 func (p *ProposalRequest) ResolvedValidityDays() int {
-	if p.ValidityDays > 0 {
-		return p.ValidityDays
-	}
-	if p.ValidityDaysLegacy > 0 {
-		return p.ValidityDaysLegacy
+	return p.resolveValidityDays().Value
+}
+
+func (p *ProposalRequest) resolveValidityDays() Resolved[int] {
+	validity := firstPositiveOf(from("/validadeDias", p.ValidityDays), from("/validity_days", p.ValidityDaysLegacy))
+	if validity.Source == "" {
+		return from(SourceDefault, 10)
 	}
-	return 10
+	return validity
 }
 
 func (p *ProposalRequest) ResolvedTotalValue() float64 {
+	return p.resolveTotalValue().Value
+}
+
+// resolveTotalValue uses the monthly rent for leases, then the declared
+// value, then the sum of the payment breakdown.
+func (p *ProposalRequest) resolveTotalValue() Resolved[float64] {
 	if p.ResolvedDealType() == "rent" {
-		if monthlyRent := p.ResolvedRentalTerms().MonthlyRent; monthlyRent > 0 {
+		if monthlyRent := p.resolveRentalTerms().MonthlyRent; monthlyRent.Value > 0 {
 			return monthlyRent
 		}
 	}
-	if p.TotalValue > 0 {
-		return p.TotalValue
+	if declared := firstPositiveOf(from("/totalValue", p.TotalValue), from("/value", p.TotalValueLegacy)); declared.Source != "" {
+		return declared
 	}
-	if p.TotalValueLegacy > 0 {
-		return p.TotalValueLegacy
+	payments := p.resolvePayments()
+	if payments.Total <= 0 {
+		return Resolved[float64]{}
 	}
-	payments := p.ResolvedPayments()
-	return payments.Cash + payments.TradeIn + payments.Financing + payments.Others
+	return from("/payment", payments.Total)
 }
 
 func (p *ProposalRequest) ResolvedRentalTerms() RentalTerms {
-	primary := p.RentalTerms
-	fallback := p.RentalTermsCamel
+	return p.resolveRentalTerms().Terms()
+}
 
-	return RentalTerms{
-		MonthlyRent:               firstPositive(primary.MonthlyRent, fallback.MonthlyRent, p.TotalValue, p.TotalValueLegacy),
-		GuaranteeType:             firstNonBlank(primary.GuaranteeType, fallback.GuaranteeType),
-		GuaranteeAmount:           firstPositive(primary.GuaranteeAmount, fallback.GuaranteeAmount),
-		LeaseTermMonths:           firstPositiveInteger(primary.LeaseTermMonths, fallback.LeaseTermMonths),
-		ExpectedStartDate:         firstNonBlank(primary.ExpectedStartDate, fallback.ExpectedStartDate),
-		MonthlyDueDay:             firstPositiveInteger(primary.MonthlyDueDay, fallback.MonthlyDueDay),
-		CondominiumResponsibility: firstNonBlank(primary.CondominiumResponsibility, fallback.CondominiumResponsibility),
-		PropertyTaxResponsibility: firstNonBlank(primary.PropertyTaxResponsibility, fallback.PropertyTaxResponsibility),
-		Observations:              firstNonBlank(primary.Observations, fallback.Observations),
+// resolveRentalTerms prefers "rental_terms" over "rentalTerms"; the monthly
+// rent finally falls back to the declared total value.
+func (p *ProposalRequest) resolveRentalTerms() RentalTermsResolution {
+	primary, fallback := p.RentalTerms, p.RentalTermsCamel
+	text := func(field, primaryValue, fallbackValue string) Resolved[string] {
+		return firstText(from("/rental_terms/"+field, primaryValue), from("/rentalTerms/"+field, fallbackValue))
+	}
+	amount := func(field string, primaryValue, fallbackValue float64) Resolved[float64] {
+		return firstPositiveOf(from("/rental_terms/"+field, primaryValue), from("/rentalTerms/"+field, fallbackValue))
+	}
+	integer := func(field string, primaryValue, fallbackValue int) Resolved[int] {
+		return firstPositiveOf(from("/rental_terms/"+field, primaryValue), from("/rentalTerms/"+field, fallbackValue))
+	}
+
+	monthlyRent := amount("monthly_rent", primary.MonthlyRent, fallback.MonthlyRent)
+	if monthlyRent.Source == "" {
+		monthlyRent = firstPositiveOf(from("/totalValue", p.TotalValue), from("/value", p.TotalValueLegacy))
+	}
+	return RentalTermsResolution{
+		MonthlyRent:               monthlyRent,
+		GuaranteeType:             text("guarantee_type", primary.GuaranteeType, fallback.GuaranteeType),
+		GuaranteeAmount:           amount("guarantee_amount", primary.GuaranteeAmount, fallback.GuaranteeAmount),
+		LeaseTermMonths:           integer("lease_term_months", primary.LeaseTermMonths, fallback.LeaseTermMonths),
+		ExpectedStartDate:         text("expected_start_date", primary.ExpectedStartDate, fallback.ExpectedStartDate),
+		MonthlyDueDay:             integer("monthly_due_day", primary.MonthlyDueDay, fallback.MonthlyDueDay),
+		CondominiumResponsibility: text("condominium_responsibility", primary.CondominiumResponsibility, fallback.CondominiumResponsibility),
+		PropertyTaxResponsibility: text("property_tax_responsibility", primary.PropertyTaxResponsibility, fallback.PropertyTaxResponsibility),
+		Observations:              text("observations", primary.Observations, fallback.Observations),
 	}
 }
 
 func (p *ProposalRequest) ResolvedPropertyAddress() string {
-	if strings.TrimSpace(p.PropertyAddress.Raw) != "" {
-		return strings.TrimSpace(p.PropertyAddress.Raw)
-	}
-	if strings.TrimSpace(p.PropertyAddressLegacy) != "" {
-		return strings.TrimSpace(p.PropertyAddressLegacy)
+	return p.resolvePropertyAddress().Value
+}
+
+func (p *ProposalRequest) resolvePropertyAddress() Resolved[string] {
+	return firstText(
+		from("/propertyAddress", p.PropertyAddress.Raw),
+		from("/property_address", p.PropertyAddressLegacy),
+		from("/propertyAddress", p.PropertyAddress.String()),
+	)
+}
+
+// String joins the structured parts of an address, preferring the raw
+// formatted value when the caller sent one.
+func (a FlexibleAddress) String() string {
+	if strings.TrimSpace(a.Raw) != "" {
+		return strings.TrimSpace(a.Raw)
 	}
 
 	parts := []string{
-		p.PropertyAddress.Street,
-		withPrefixIfPresent("Nº ", p.PropertyAddress.Number),
-		p.PropertyAddress.Neighborhood,
-		p.PropertyAddress.City,
-		p.PropertyAddress.State,
-		p.PropertyAddress.Complement,
+		a.Street,
+		withPrefixIfPresent("Nº ", a.Number),
+		a.Neighborhood,
+		a.City,
+		a.State,
+		a.Complement,
 	}
 
 	filtered := make([]string, 0, len(parts))
@@ -384,35 +452,74 @@ func (p *ProposalRequest) ResolvedPropertyAddress() string {
 }
 
 func (p *ProposalRequest) ResolvedCity() string {
-	return firstNonBlank(p.PropertyAddress.City, p.PropertyCity)
+	return p.resolveCity().Value
+}
+
+func (p *ProposalRequest) resolveCity() Resolved[string] {
+	return firstText(from("/propertyAddress/city", p.PropertyAddress.City), from("/propertyCity", p.PropertyCity))
 }
 
 func (p *ProposalRequest) ResolvedState() string {
-	return strings.ToUpper(firstNonBlank(p.PropertyAddress.State, p.PropertyState))
+	return p.resolveState().Value
+}
+
+func (p *ProposalRequest) resolveState() Resolved[string] {
+	state := firstText(from("/propertyAddress/state", p.PropertyAddress.State), from("/propertyState", p.PropertyState))
+	state.Value = strings.ToUpper(state.Value)
+	return state
 }
 
 func (p *ProposalRequest) ResolvedPayments() PaymentValues {
-	values := PaymentValues{
-		Cash:      firstPositive(p.Payment.Cash, p.Payment.Dinheiro),
-		TradeIn:   firstPositive(p.Payment.TradeIn, p.Payment.TradeInSnake, p.Payment.Permuta),
-		Financing: firstPositive(p.Payment.Financing, p.Payment.Financiamento),
-		Others:    firstPositive(p.Payment.Others, p.Payment.Outros),
-	}
+	return p.resolvePayments().Values()
+}
 
-	if values.Cash+values.TradeIn+values.Financing+values.Others > 0 {
-		return values
+// resolvePayments reads the payment breakdown and, when it is empty, parses
+// the amounts out of the legacy free-text payment_method.
+func (p *ProposalRequest) resolvePayments() PaymentResolution {
+	payments := p.Payment.resolve("/payment")
+	if payments.Total > 0 {
+		return payments
 	}
 
 	legacy := strings.TrimSpace(p.PaymentMethodLegacy)
 	if legacy == "" {
-		return values
+		return payments
 	}
 
-	values.Cash = extractLegacyPaymentValue(legacy, "Dinheiro")
-	values.TradeIn = extractLegacyPaymentValue(legacy, "Permuta")
-	values.Financing = extractLegacyPaymentValue(legacy, "Financiamento")
-	values.Others = extractLegacyPaymentValue(legacy, "Outros")
-	return values
+	payments.Cash = extractLegacyPayment(legacy, "Dinheiro")
+	payments.TradeIn = extractLegacyPayment(legacy, "Permuta")
+	payments.Financing = extractLegacyPayment(legacy, "Financiamento")
+	payments.Others = extractLegacyPayment(legacy, "Outros")
+	payments.Total = payments.Values().Total()
+	return payments
+}
+
+func extractLegacyPayment(source string, label string) Resolved[float64] {
+	return firstPositiveOf(from("/payment_method", extractLegacyPaymentValue(source, label)))
+}
+
+// Resolve returns the values the proposal renderer prints, with the field
+// each one came from. Call it after Validate.
+func (p *ProposalRequest) Resolve() ProposalResolution {
+	resolution := ProposalResolution{
+		DealType:        p.resolveDealType(),
+		ClientName:      p.resolveClientName(),
+		ClientCPF:       p.resolveClientCPF(),
+		BrokerName:      p.resolveBrokerName(),
+		PropertyAddress: p.resolvePropertyAddress(),
+		City:            p.resolveCity(),
+		State:           p.resolveState(),
+		ValidityDays:    p.resolveValidityDays(),
+		TotalValue:      p.resolveTotalValue(),
+	}
+	if resolution.DealType.Value == "rent" {
+		terms := p.resolveRentalTerms()
+		resolution.RentalTerms = &terms
+	} else {
+		payments := p.resolvePayments()
+		resolution.Payment = &payments
+	}
+	return resolution
 }
 
 func withPrefixIfPresent(prefix, value string) string {
@@ -423,16 +530,6 @@ func withPrefixIfPresent(prefix, value string) string {
 	return prefix + trimmed
 }
 
-func firstNonBlank(values ...string) string {
-	for _, value := range values {
-		trimmed := strings.TrimSpace(value)
-		if trimmed != "" {
-			return trimmed
-		}
-	}
-	return ""
-}
-
 func firstPositive(values ...float64) float64 {
 	for _, value := range values {
 		if value > 0 {
@@ -442,15 +539,6 @@ func firstPositive(values ...float64) float64 {
 	return 0
 }
 
-func firstPositiveInteger(values ...int) int {
-	for _, value := range values {
-		if value > 0 {
-			return value
-		}
-	}
-	return 0
-}
-
 func extractLegacyPaymentValue(source string, label string) float64 {
 	pattern := regexp.MustCompile(label + `\s*:\s*R\$\s*([0-9\.,]+)`)
 	matches := pattern.FindStringSubmatch(source)
@@ -467,17 +555,6 @@ func extractLegacyPaymentValue(source string, label string) float64 {
 	return parsed
 }
 
-func validateMaxLength(field, value string, maxLength int) error {
-	trimmed := strings.TrimSpace(value)
-	if trimmed == "" {
-		return nil
-	}
-	if len([]rune(trimmed)) > maxLength {
-		return fmt.Errorf("%s exceeds max length of %d", field, maxLength)
-	}
-	return nil
-}
-
 func sanitizeText(value string) string {
 	trimmed := strings.TrimSpace(value)
 	if trimmed == "" {
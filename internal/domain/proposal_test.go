@@ -33,7 +33,49 @@ func TestRentalProposalValidationRejectsInvalidCommercialTerms(t *testing.T) {
 		},
 	}
 
-	if err := req.Validate(); err == nil {
-		t.Fatal("expected invalid rental date to be rejected")
+	requireFieldError(t, req.Validate(), "/rental_terms/expected_start_date", CodeInvalidDate)
+}
+
+func TestProposalResolveRecordsTheFieldEachValueCameFrom(t *testing.T) {
+	req := ProposalRequest{
+		ClientName:  "Ana Silva",
+		DealType:    "aluguel",
+		TotalValue:  2500,
+		RentalTerms: RentalTerms{GuaranteeType: "Caução"},
+		RentalTermsCamel: RentalTerms{
+			GuaranteeType:   "Fiador",
+			GuaranteeAmount: 7500,
+			MonthlyDueDay:   5,
+		},
+		PropertyCity: "Goiânia",
+	}
+
+	resolution := req.Resolve()
+
+	if resolution.DealType != (Resolved[string]{Value: "rent", Source: "/dealType"}) {
+		t.Fatalf("unexpected deal type %+v", resolution.DealType)
+	}
+	if resolution.Payment != nil || resolution.RentalTerms == nil {
+		t.Fatal("expected rental terms and no payment breakdown for a lease")
+	}
+	terms := resolution.RentalTerms
+	for _, tc := range []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"monthly_rent", terms.MonthlyRent.Source, "/totalValue"},
+		{"guarantee_type", terms.GuaranteeType.Source, "/rental_terms/guarantee_type"},
+		{"guarantee_amount", terms.GuaranteeAmount.Source, "/rentalTerms/guarantee_amount"},
+		{"monthly_due_day", terms.MonthlyDueDay.Source, "/rentalTerms/monthly_due_day"},
+		{"city", resolution.City.Source, "/propertyCity"},
+		{"total_value", resolution.TotalValue.Source, "/totalValue"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s source = %q, want %q", tc.name, tc.got, tc.want)
+		}
+	}
+	if terms.Terms() != req.ResolvedRentalTerms() {
+		t.Fatalf("expected Resolve to agree with ResolvedRentalTerms, got %+v", terms.Terms())
 	}
 }
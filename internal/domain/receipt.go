@@ -0,0 +1,86 @@
+package domain
+
+// ReceiptRequest describes the earnest money (sinal/arras) paid after a
+// proposal is accepted. It references the proposal or contract by ID only;
+// the backend keeps the deal itself.
+type ReceiptRequest struct {
+	ReceiptID       string        `json:"receipt_id"`
+	ProposalID      string        `json:"proposal_id"`
+	ContractID      string        `json:"contract_id"`
+	Payer           ContractParty `json:"payer"`
+	Payee           ContractParty `json:"payee"`
+	Amount          float64       `json:"amount"`
+	PaymentMethod   string        `json:"payment_method"`
+	PaymentDate     string        `json:"payment_date"`
+	PropertyAddress string        `json:"property_address"`
+	City            string        `json:"city"`
+
+	BrandingProfileID string `json:"branding_profile_id"`
+	TemplateVersion   string `json:"template_version"`
+	IssueDate         string `json:"issue_date"`
+	Sign              bool   `json:"sign"`
+}
+
+const (
+	maxReceiptReferenceLength = 100
+	maxReceiptMethodLength    = 120
+)
+
+func (r *ReceiptRequest) Sanitize() {
+	r.ReceiptID = sanitizeText(r.ReceiptID)
+	r.ProposalID = sanitizeText(r.ProposalID)
+	r.ContractID = sanitizeText(r.ContractID)
+	r.Payer.Sanitize()
+	r.Payee.Sanitize()
+	r.PaymentMethod = sanitizeText(r.PaymentMethod)
+	r.PaymentDate = sanitizeText(r.PaymentDate)
+	r.PropertyAddress = sanitizeText(r.PropertyAddress)
+	r.City = sanitizeText(r.City)
+	r.BrandingProfileID = sanitizeText(r.BrandingProfileID)
+	r.TemplateVersion = sanitizeText(r.TemplateVersion)
+}
+
+func (r *ReceiptRequest) Validate() error {
+	r.Sanitize()
+	var v validator
+	v.templateVersion(r.TemplateVersion)
+	v.date("/issue_date", r.IssueDate)
+	for _, field := range []struct {
+		path  string
+		value string
+		limit int
+	}{
+		{"/receipt_id", r.ReceiptID, maxReceiptReferenceLength},
+		{"/proposal_id", r.ProposalID, maxReceiptReferenceLength},
+		{"/contract_id", r.ContractID, maxReceiptReferenceLength},
+		{"/payer/name", r.Payer.Name, maxClientNameLength},
+		{"/payer/cpf", r.Payer.CPF, maxClientCPFLength},
+		{"/payee/name", r.Payee.Name, maxClientNameLength},
+		{"/payee/cpf", r.Payee.CPF, maxClientCPFLength},
+		{"/payment_method", r.PaymentMethod, maxReceiptMethodLength},
+		{"/property_address", r.PropertyAddress, maxPropertyAddressLength},
+		{"/city", r.City, maxCityLength},
+	} {
+		v.maxLength(field.path, field.value, field.limit)
+	}
+
+	if r.ProposalID == "" && r.ContractID == "" {
+		v.add("/proposal_id", CodeRequired, "Informe proposal_id ou contract_id.", nil)
+	}
+	v.requiredText("/payer/name", r.Payer.Name)
+	v.requiredText("/payee/name", r.Payee.Name)
+	v.document("/payer/cpf", &r.Payer.CPF, "")
+	v.document("/payee/cpf", &r.Payee.CPF, "")
+	v.positive("/amount", r.Amount)
+	v.requiredText("/payment_method", r.PaymentMethod)
+	v.requiredDate("/payment_date", r.PaymentDate)
+	return v.err()
+}
+
+func (r *ReceiptRequest) References() DocumentReferences {
+	return DocumentReferences{
+		Document:          DocumentTypeReceipt,
+		BrandingProfileID: r.BrandingProfileID,
+		TemplateVersion:   r.TemplateVersion,
+	}
+}
@@ -0,0 +1,190 @@
+package domain
+
+import "strings"
+
+// SourceDefault is the Source of a value the service filled in because no
+// request field supplied one.
+const SourceDefault = "default"
+
+// Resolved is a request value after alias and fallback resolution. Source
+// is the JSON pointer of the field the value was read from, SourceDefault
+// for a built-in default, or empty when nothing supplied a value.
+type Resolved[T any] struct {
+	Value  T      `json:"value"`
+	Source string `json:"source,omitempty"`
+}
+
+func from[T any](source string, value T) Resolved[T] {
+	return Resolved[T]{Value: value, Source: source}
+}
+
+// firstText returns the first candidate that is not blank, trimmed.
+func firstText(candidates ...Resolved[string]) Resolved[string] {
+	for _, candidate := range candidates {
+		if trimmed := strings.TrimSpace(candidate.Value); trimmed != "" {
+			return from(candidate.Source, trimmed)
+		}
+	}
+	return Resolved[string]{}
+}
+
+// firstPositiveOf returns the first candidate greater than zero.
+func firstPositiveOf[T int | float64](candidates ...Resolved[T]) Resolved[T] {
+	for _, candidate := range candidates {
+		if candidate.Value > 0 {
+			return candidate
+		}
+	}
+	return Resolved[T]{}
+}
+
+// PaymentResolution is a payment breakdown with each component read from
+// its first non-zero alias.
+type PaymentResolution struct {
+	Cash         Resolved[float64]       `json:"cash"`
+	TradeIn      Resolved[float64]       `json:"trade_in"`
+	Financing    Resolved[float64]       `json:"financing"`
+	Others       Resolved[float64]       `json:"others"`
+	Installments Resolved[[]Installment] `json:"installments"`
+	Total        float64                 `json:"total"`
+}
+
+// Values drops the sources.
+func (r PaymentResolution) Values() PaymentValues {
+	return PaymentValues{
+		Cash:         r.Cash.Value,
+		TradeIn:      r.TradeIn.Value,
+		Financing:    r.Financing.Value,
+		Others:       r.Others.Value,
+		Installments: r.Installments.Value,
+	}
+}
+
+func (b *PaymentBreakdown) resolve(path string) PaymentResolution {
+	resolution := PaymentResolution{
+		Cash:         firstPositiveOf(from(path+"/cash", b.Cash), from(path+"/dinheiro", b.Dinheiro)),
+		TradeIn:      firstPositiveOf(from(path+"/tradeIn", b.TradeIn), from(path+"/trade_in", b.TradeInSnake), from(path+"/permuta", b.Permuta)),
+		Financing:    firstPositiveOf(from(path+"/financing", b.Financing), from(path+"/financiamento", b.Financiamento)),
+		Others:       firstPositiveOf(from(path+"/others", b.Others), from(path+"/outros", b.Outros)),
+		Installments: b.resolveInstallments(path),
+	}
+	resolution.Total = resolution.Values().Total()
+	return resolution
+}
+
+// resolveInstallments prefers the English key and falls back to "parcelas".
+func (b *PaymentBreakdown) resolveInstallments(path string) Resolved[[]Installment] {
+	switch {
+	case len(b.Installments) > 0:
+		return from(path+"/installments", b.Installments)
+	case len(b.Parcelas) > 0:
+		return from(path+"/parcelas", b.Parcelas)
+	default:
+		return Resolved[[]Installment]{}
+	}
+}
+
+// RentalTermsResolution is RentalTerms with the source of each value.
+type RentalTermsResolution struct {
+	MonthlyRent               Resolved[float64] `json:"monthly_rent"`
+	GuaranteeType             Resolved[string]  `json:"guarantee_type"`
+	GuaranteeAmount           Resolved[float64] `json:"guarantee_amount"`
+	LeaseTermMonths           Resolved[int]     `json:"lease_term_months"`
+	ExpectedStartDate         Resolved[string]  `json:"expected_start_date"`
+	MonthlyDueDay             Resolved[int]     `json:"monthly_due_day"`
+	CondominiumResponsibility Resolved[string]  `json:"condominium_responsibility"`
+	PropertyTaxResponsibility Resolved[string]  `json:"property_tax_responsibility"`
+	Observations              Resolved[string]  `json:"observations"`
+}
+
+// Terms drops the sources.
+func (r RentalTermsResolution) Terms() RentalTerms {
+	return RentalTerms{
+		MonthlyRent:               r.MonthlyRent.Value,
+		GuaranteeType:             r.GuaranteeType.Value,
+		GuaranteeAmount:           r.GuaranteeAmount.Value,
+		LeaseTermMonths:           r.LeaseTermMonths.Value,
+		ExpectedStartDate:         r.ExpectedStartDate.Value,
+		MonthlyDueDay:             r.MonthlyDueDay.Value,
+		CondominiumResponsibility: r.CondominiumResponsibility.Value,
+		PropertyTaxResponsibility: r.PropertyTaxResponsibility.Value,
+		Observations:              r.Observations.Value,
+	}
+}
+
+// ProposalResolution is a proposal after sanitizing and alias resolution:
+// the values the renderer prints, each with the field it came from.
+// Payment is only set for sales and RentalTerms only for leases.
+type ProposalResolution struct {
+	DealType        Resolved[string]       `json:"deal_type"`
+	ClientName      Resolved[string]       `json:"client_name"`
+	ClientCPF       Resolved[string]       `json:"client_cpf"`
+	BrokerName      Resolved[string]       `json:"broker_name"`
+	PropertyAddress Resolved[string]       `json:"property_address"`
+	City            Resolved[string]       `json:"city"`
+	State           Resolved[string]       `json:"state"`
+	ValidityDays    Resolved[int]          `json:"validity_days"`
+	TotalValue      Resolved[float64]      `json:"total_value"`
+	Payment         *PaymentResolution     `json:"payment,omitempty"`
+	RentalTerms     *RentalTermsResolution `json:"rental_terms,omitempty"`
+}
+
+// PartyResolution is a contract party with its resolved kind, name and
+// property regime.
+type PartyResolution struct {
+	Kind                  Resolved[string] `json:"kind"`
+	Name                  Resolved[string] `json:"name"`
+	PropertyRegime        Resolved[string] `json:"property_regime"`
+	RequiresSpouseConsent bool             `json:"requires_spouse_consent"`
+	Party                 ContractParty    `json:"party"`
+}
+
+// ContractResolution is a contract after sanitizing and alias resolution.
+// SaleValue and SaleTerms are only set for sales and RentalTerms only for
+// leases.
+type ContractResolution struct {
+	DealType        string                      `json:"deal_type"`
+	PropertyTitle   string                      `json:"property_title"`
+	PropertyAddress Resolved[string]            `json:"property_address"`
+	Property        ContractProperty            `json:"property"`
+	Sellers         Resolved[[]PartyResolution] `json:"sellers"`
+	Buyers          Resolved[[]PartyResolution] `json:"buyers"`
+	SaleValue       *Resolved[float64]          `json:"sale_value,omitempty"`
+	SaleTerms       *PaymentResolution          `json:"sale_terms,omitempty"`
+	RentalTerms     *RentalTerms                `json:"rental_terms,omitempty"`
+}
+
+// ProposalPreview is what the proposal renderer would print for a request,
+// without the PDF itself.
+type ProposalPreview struct {
+	Proposal          ProposalResolution `json:"proposal"`
+	IntroLocation     IntroLocation      `json:"intro_location"`
+	BrandingProfileID string             `json:"branding_profile_id"`
+	Template          TemplateProvenance `json:"template"`
+}
+
+// IntroLocation is the address, city and state printed in the proposal's
+// opening paragraph, after the city and state are split out of a free-form
+// address. Missing parts are the blank lines the document leaves to fill in.
+type IntroLocation struct {
+	Address string `json:"address"`
+	City    string `json:"city"`
+	State   string `json:"state"`
+}
+
+// ContractPreview is what the contract renderer would print for a request,
+// without the PDF itself.
+type ContractPreview struct {
+	Contract          ContractResolution  `json:"contract"`
+	ObjectDescription string              `json:"object_description"`
+	Qualifications    PartyQualifications `json:"qualifications"`
+	BrandingProfileID string              `json:"branding_profile_id"`
+	Template          TemplateProvenance  `json:"template"`
+}
+
+// PartyQualifications holds the "qualificação" paragraph printed for each
+// party, in the order of ContractResolution's Sellers and Buyers.
+type PartyQualifications struct {
+	Sellers []string `json:"sellers"`
+	Buyers  []string `json:"buyers"`
+}
@@ -0,0 +1,211 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Validation error codes. Clients branch on them, so a code must never be
+// renamed once released; add a new one instead.
+const (
+	CodeRequired        = "required"
+	CodeMaxLength       = "max_length"
+	CodeMaxItems        = "max_items"
+	CodePositive        = "positive"
+	CodeNonNegative     = "non_negative"
+	CodeOutOfRange      = "out_of_range"
+	CodeTooLarge        = "too_large"
+	CodeInvalidDate     = "invalid_date"
+	CodeInvalidDocument = "invalid_document"
+	CodeInvalidChoice   = "invalid_choice"
+	CodeInvalidFormat   = "invalid_format"
+	CodeNotApplicable   = "not_applicable"
+	CodeSumMismatch     = "sum_mismatch"
+)
+
+// FieldError is one problem found in a request. Path is a JSON pointer
+// (RFC 6901) into the request body, such as "/sellers/1/spouse/cpf", and
+// Limit carries the bound that was violated when the rule has one.
+type FieldError struct {
+	Path    string `json:"path"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Limit   any    `json:"limit,omitempty"`
+}
+
+// Bounds is the Limit of an out_of_range error.
+type Bounds struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// ValidationErrors lists every problem found in a request, in the order the
+// fields were checked.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	parts := make([]string, len(e))
+	for i, fieldErr := range e {
+		parts[i] = fmt.Sprintf("%s: %s (%s)", fieldErr.Path, fieldErr.Message, fieldErr.Code)
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Has reports whether a problem was recorded for path.
+func (e ValidationErrors) Has(path string) bool {
+	for _, fieldErr := range e {
+		if fieldErr.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// JoinValidationErrors merges the field problems of errs, in order, into
+// one ValidationErrors that keeps the first problem of each path. An error
+// that carries no field problems is returned as is.
+func JoinValidationErrors(errs ...error) error {
+	var v validator
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var fieldErrs ValidationErrors
+		if !errors.As(err, &fieldErrs) {
+			return err
+		}
+		for _, fieldErr := range fieldErrs {
+			v.add(fieldErr.Path, fieldErr.Code, fieldErr.Message, fieldErr.Limit)
+		}
+	}
+	return v.err()
+}
+
+// validator collects field errors instead of stopping at the first one. It
+// keeps only the first problem of each path, so overlapping rules (a value
+// that is both too long and not a CPF, say) report the field once.
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) add(path, code, message string, limit any) {
+	if v.errs.Has(path) {
+		return
+	}
+	v.errs = append(v.errs, FieldError{Path: path, Code: code, Message: message, Limit: limit})
+}
+
+// err returns the collected problems, or nil when there are none.
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (v *validator) required(path string) {
+	v.add(path, CodeRequired, "Campo obrigatório.", nil)
+}
+
+func (v *validator) requiredText(path, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.required(path)
+	}
+}
+
+func (v *validator) maxLength(path, value string, limit int) {
+	if len([]rune(strings.TrimSpace(value))) > limit {
+		v.add(path, CodeMaxLength, fmt.Sprintf("Deve ter no máximo %d caracteres.", limit), limit)
+	}
+}
+
+func (v *validator) positive(path string, value float64) {
+	if value <= 0 {
+		v.add(path, CodePositive, "Deve ser maior que zero.", 0)
+	}
+}
+
+func (v *validator) nonNegative(path string, value float64) {
+	if value < 0 {
+		v.add(path, CodeNonNegative, "Não pode ser negativo.", 0)
+	}
+}
+
+func (v *validator) inRange(path string, value, minimum, maximum float64) {
+	if value < minimum || value > maximum {
+		v.add(path, CodeOutOfRange, fmt.Sprintf("Deve estar entre %g e %g.", minimum, maximum), Bounds{Min: minimum, Max: maximum})
+	}
+}
+
+// date checks an optional YYYY-MM-DD date; blank values pass.
+func (v *validator) date(path, value string) {
+	if value != "" && !isValidISODate(value) {
+		v.add(path, CodeInvalidDate, "Informe uma data válida no formato AAAA-MM-DD.", "YYYY-MM-DD")
+	}
+}
+
+func (v *validator) requiredDate(path, value string) {
+	v.requiredText(path, value)
+	v.date(path, value)
+}
+
+func (v *validator) oneOf(path string, choices []string) {
+	v.add(path, CodeInvalidChoice, "Use um dos valores: "+strings.Join(choices, ", ")+".", choices)
+}
+
+// document validates an optional CPF/CNPJ and rewrites it in its masked
+// form. An empty kind accepts either; blank values are left for
+// required-field checks.
+func (v *validator) document(path string, value *string, kind DocumentKind) {
+	if strings.TrimSpace(*value) == "" {
+		return
+	}
+	parsed, ok := ParseDocumentNumber(*value)
+	if !ok || (kind != "" && parsed.Kind != kind) {
+		message := "Informe um CPF ou CNPJ válido."
+		switch kind {
+		case DocumentKindCPF:
+			message = "Informe um CPF válido."
+		case DocumentKindCNPJ:
+			message = "Informe um CNPJ válido."
+		}
+		var limit any
+		if kind != "" {
+			limit = kind
+		}
+		v.add(path, CodeInvalidDocument, message, limit)
+		return
+	}
+	*value = parsed.String()
+}
+
+func (v *validator) templateVersion(value string) {
+	if value != "" && !IsSemanticVersion(value) {
+		v.add("/template_version", CodeInvalidFormat, "Use uma versão semântica como 1.0.0.", "MAJOR.MINOR.PATCH")
+	}
+}
+
+func (v *validator) documentState(value string) {
+	if value != "" && !slices.Contains(documentStateChoices, value) {
+		v.oneOf("/document_state", documentStateChoices)
+	}
+}
+
+// signedState refuses sign on a document that is not final: the agency
+// signs the agreed text, not a draft or the copy the parties initial.
+func (v *validator) signedState(sign bool, state string) {
+	if sign && slices.Contains(documentStateChoices, state) && state != DocumentStateFinal {
+		v.add("/sign", CodeNotApplicable, "Somente documentos no estado final (document_state \"final\") podem ser assinados.", nil)
+	}
+}
+
+// pathOr points at the field a resolved value was read from, or at
+// fallback when no field supplied it.
+func pathOr[T any](value Resolved[T], fallback string) string {
+	if value.Source == "" {
+		return fallback
+	}
+	return value.Source
+}
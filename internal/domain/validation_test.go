@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// requireFieldError fails unless err is a ValidationErrors that reports
+// code at path, and returns that entry.
+func requireFieldError(t *testing.T, err error, path, code string) FieldError {
+	t.Helper()
+	var fieldErrs ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	for _, fieldErr := range fieldErrs {
+		if fieldErr.Path == path {
+			if fieldErr.Code != code {
+				t.Fatalf("expected %s at %s, got %s (%v)", code, path, fieldErr.Code, err)
+			}
+			return fieldErr
+		}
+	}
+	t.Fatalf("expected %s at %s, got %v", code, path, err)
+	return FieldError{}
+}
+
+func TestProposalValidationCollectsEveryProblem(t *testing.T) {
+	req := ProposalRequest{
+		ClientCPF:  "123.456.789-00",
+		BrokerName: strings.Repeat("a", maxBrokerNameLength+1),
+		TotalValue: 100000,
+		Payment: PaymentBreakdown{
+			Cash: 10000,
+			Installments: []Installment{
+				{DueDate: "2026-02-30", Amount: 0},
+				{DueDate: "2026-03-10", Amount: 5000, Index: "SELIC"},
+			},
+		},
+		TemplateVersion: "v2",
+	}
+
+	err := req.Validate()
+
+	requireFieldError(t, err, "/template_version", CodeInvalidFormat)
+	if got := requireFieldError(t, err, "/brokerName", CodeMaxLength); got.Limit != maxBrokerNameLength {
+		t.Fatalf("expected max_length limit %d, got %v", maxBrokerNameLength, got.Limit)
+	}
+	if got := requireFieldError(t, err, "/clientCpf", CodeInvalidDocument); got.Message == "" {
+		t.Fatal("expected a human readable message")
+	}
+	requireFieldError(t, err, "/clientName", CodeRequired)
+	requireFieldError(t, err, "/propertyAddress", CodeRequired)
+	requireFieldError(t, err, "/payment/installments/0/amount", CodePositive)
+	requireFieldError(t, err, "/payment/installments/0/due_date", CodeInvalidDate)
+	requireFieldError(t, err, "/payment/installments/1/index", CodeInvalidChoice)
+	if got := requireFieldError(t, err, "/payment", CodeSumMismatch); got.Limit != 100000.0 {
+		t.Fatalf("expected the total value as limit, got %v", got.Limit)
+	}
+}
+
+func TestProposalValidationPointsAtTheAliasThatWasSent(t *testing.T) {
+	req := ProposalRequest{
+		ClientNameLegacy:      "Ana Silva",
+		PropertyAddressLegacy: "Rua A, 10",
+		PropertyCity:          "Goiânia",
+		PropertyState:         "GOIAS-BRASIL",
+		TotalValue:            100,
+		Payment:               PaymentBreakdown{Cash: 100},
+	}
+
+	err := req.Validate()
+	requireFieldError(t, err, "/propertyState", CodeMaxLength)
+
+	req.PropertyState = "GO"
+	req.ClientCPFLegacy = "111.111.111-11"
+	requireFieldError(t, req.Validate(), "/client_cpf", CodeInvalidDocument)
+}
+
+func TestContractValidationCollectsProblemsAcrossParties(t *testing.T) {
+	req := ContractRequest{
+		DealType: "permuta",
+		Property: ContractProperty{BuiltArea: -1},
+		Sellers: []ContractParty{
+			{Name: "Vendedor", CPF: "123.456.789-00"},
+			{Kind: "company", LegalName: "Holding Ltda"},
+		},
+		Buyers: []ContractParty{{MaritalStatus: "casado", Spouse: &ContractSpouse{Name: "Cônjuge"}}},
+	}
+
+	err := req.Validate()
+
+	if got := requireFieldError(t, err, "/deal_type", CodeInvalidChoice); len(got.Limit.([]string)) != 2 {
+		t.Fatalf("expected the allowed deal types as limit, got %v", got.Limit)
+	}
+	requireFieldError(t, err, "/property_title", CodeRequired)
+	requireFieldError(t, err, "/property_address", CodeRequired)
+	requireFieldError(t, err, "/property/built_area", CodeNonNegative)
+	requireFieldError(t, err, "/sellers/0/cpf", CodeInvalidDocument)
+	requireFieldError(t, err, "/sellers/1/cnpj", CodeRequired)
+	requireFieldError(t, err, "/sellers/1/representatives", CodeRequired)
+	requireFieldError(t, err, "/buyers/0/name", CodeRequired)
+	requireFieldError(t, err, "/buyers/0/spouse/cpf", CodeRequired)
+}
+
+func TestValidationErrorsMarshalToStableSchema(t *testing.T) {
+	var v validator
+	v.maxLength("/client_name", "abcd", 3)
+	v.required("/client_name")
+	v.inRange("/term_months", 500, 1, 420)
+
+	encoded, err := json.Marshal(v.errs)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	want := `[{"path":"/client_name","code":"max_length","message":"Deve ter no máximo 3 caracteres.","limit":3},` +
+		`{"path":"/term_months","code":"out_of_range","message":"Deve estar entre 1 e 420.","limit":{"min":1,"max":420}}]`
+	if string(encoded) != want {
+		t.Fatalf("unexpected encoding:\n got %s\nwant %s", encoded, want)
+	}
+}
+
+func TestValidateReturnsNilWithoutProblems(t *testing.T) {
+	req := ReceiptRequest{
+		ProposalID:    "proposal-1",
+		Payer:         ContractParty{Name: "Ana Silva"},
+		Payee:         ContractParty{Name: "Carlos Souza"},
+		Amount:        10000,
+		PaymentMethod: "PIX",
+		PaymentDate:   "2026-03-15",
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	req.Amount = 0
+	req.PaymentDate = ""
+	req.IssueDate = "2026-13-01"
+	err := req.Validate()
+	requireFieldError(t, err, "/amount", CodePositive)
+	requireFieldError(t, err, "/payment_date", CodeRequired)
+	requireFieldError(t, err, "/issue_date", CodeInvalidDate)
+}
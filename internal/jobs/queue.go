@@ -0,0 +1,310 @@
+// Package jobs renders documents in the background for clients that cannot
+// wait on a single HTTP request, keeping finished results in memory for a
+// limited time.
+package jobs
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"pdf-service/internal/domain"
+)
+
+// Status is where a job is in its lifecycle.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+var (
+	// ErrQueueFull is returned by Submit when every worker is busy and the
+	// backlog is at capacity.
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrNotFound is returned for unknown jobs and for jobs whose result
+	// has expired.
+	ErrNotFound = errors.New("job not found")
+	// ErrNotFinished is returned by Result while the job is queued or
+	// running.
+	ErrNotFinished = errors.New("job is not finished")
+	// ErrClosed is returned by Submit after Close.
+	ErrClosed = errors.New("job queue is closed")
+)
+
+// RenderFunc produces the document of a job.
+type RenderFunc func() (domain.GeneratedDocument, error)
+
+// Job is a snapshot of a job. Zero times mean the job has not reached that
+// point yet; ExpiresAt is set once the job finishes. Delivery and
+// Deliveries are only set for jobs submitted with a callback URL.
+type Job struct {
+	ID          string
+	Kind        string
+	Filename    string
+	Status      Status
+	Err         error
+	Template    domain.TemplateProvenance
+	SHA256      string
+	CreatedAt   time.Time
+	StartedAt   time.Time
+	FinishedAt  time.Time
+	ExpiresAt   time.Time
+	CallbackURL string
+	Delivery    DeliveryStatus
+	Deliveries  []DeliveryAttempt
+}
+
+// snapshot copies the job so callers never share the attempts slice the
+// delivery goroutine appends to.
+func (j Job) snapshot() Job {
+	j.Deliveries = append([]DeliveryAttempt(nil), j.Deliveries...)
+	return j
+}
+
+type entry struct {
+	job      Job
+	render   RenderFunc
+	document domain.GeneratedDocument
+}
+
+// Queue runs jobs on a fixed pool of workers with a bounded backlog.
+type Queue struct {
+	ttl      time.Duration
+	now      func() time.Time
+	notifier *Notifier
+	pending  chan *entry
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	closed  bool
+}
+
+// Option customizes a Queue at construction time.
+type Option func(*Queue)
+
+// WithClock replaces time.Now, for tests of result expiry.
+func WithClock(now func() time.Time) Option {
+	return func(q *Queue) {
+		q.now = now
+	}
+}
+
+// WithNotifier enables callbacks: jobs submitted WithCallback are
+// announced through notifier when they finish.
+func WithNotifier(notifier *Notifier) Option {
+	return func(q *Queue) {
+		q.notifier = notifier
+	}
+}
+
+// SubmitOption customizes a single job.
+type SubmitOption func(*Job)
+
+// WithCallback asks for a notification at url when the job finishes. It
+// has no effect on a queue without a notifier.
+func WithCallback(url string) SubmitOption {
+	return func(j *Job) {
+		j.CallbackURL = url
+		if url != "" {
+			j.Delivery = DeliveryPending
+		}
+	}
+}
+
+// NewQueue starts workers goroutines that take jobs from a backlog of at
+// most capacity jobs. Finished jobs are kept for ttl.
+func NewQueue(workers, capacity int, ttl time.Duration, options ...Option) *Queue {
+	q := &Queue{
+		ttl:     ttl,
+		now:     time.Now,
+		pending: make(chan *entry, max(capacity, 0)),
+		entries: map[string]*entry{},
+	}
+	for _, option := range options {
+		option(q)
+	}
+	for range max(workers, 1) {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// Submit enqueues a job and returns its initial snapshot.
+func (q *Queue) Submit(kind, filename string, render RenderFunc, options ...SubmitOption) (Job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return Job{}, ErrClosed
+	}
+	q.pruneLocked()
+
+	e := &entry{
+		job:    Job{ID: id, Kind: kind, Filename: filename, Status: StatusQueued, CreatedAt: q.now()},
+		render: render,
+	}
+	if q.notifier != nil {
+		for _, option := range options {
+			option(&e.job)
+		}
+	}
+	select {
+	case q.pending <- e:
+	default:
+		return Job{}, ErrQueueFull
+	}
+	q.entries[id] = e
+	return e.job.snapshot(), nil
+}
+
+// AcceptsCallbacks reports whether the queue was given a notifier, and so
+// whether WithCallback has any effect.
+func (q *Queue) AcceptsCallbacks() bool {
+	return q.notifier != nil
+}
+
+// Get returns the current snapshot of a job.
+func (q *Queue) Get(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pruneLocked()
+
+	e, ok := q.entries[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return e.job.snapshot(), nil
+}
+
+// Result returns the document of a succeeded job. For a failed job it
+// returns the render error.
+func (q *Queue) Result(id string) (Job, domain.GeneratedDocument, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pruneLocked()
+
+	e, ok := q.entries[id]
+	if !ok {
+		return Job{}, domain.GeneratedDocument{}, ErrNotFound
+	}
+	job := e.job.snapshot()
+	switch job.Status {
+	case StatusSucceeded:
+		return job, e.document, nil
+	case StatusFailed:
+		return job, domain.GeneratedDocument{}, job.Err
+	default:
+		return job, domain.GeneratedDocument{}, ErrNotFinished
+	}
+}
+
+// Close stops accepting jobs and waits for the backlog to drain and for
+// pending callbacks to be delivered or given up on.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	close(q.pending)
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+	for e := range q.pending {
+		q.mu.Lock()
+		e.job.Status = StatusRunning
+		e.job.StartedAt = q.now()
+		q.mu.Unlock()
+
+		document, err := runRender(e.render)
+
+		q.mu.Lock()
+		e.job.FinishedAt = q.now()
+		e.job.ExpiresAt = e.job.FinishedAt.Add(q.ttl)
+		e.render = nil
+		if err != nil {
+			e.job.Status = StatusFailed
+			e.job.Err = err
+		} else {
+			e.job.Status = StatusSucceeded
+			e.job.Template = document.Template
+			sum := sha256.Sum256(document.PDF)
+			e.job.SHA256 = hex.EncodeToString(sum[:])
+			e.document = document
+		}
+		finished := e.job.snapshot()
+		q.mu.Unlock()
+
+		if finished.CallbackURL != "" {
+			q.wg.Add(1)
+			go q.notify(e, finished)
+		}
+	}
+}
+
+// notify delivers the callback of a finished job outside the worker, so a
+// slow receiver does not hold up rendering, and records every attempt on
+// the job.
+func (q *Queue) notify(e *entry, job Job) {
+	defer q.wg.Done()
+	delivered := q.notifier.deliver(job, func(attempt DeliveryAttempt) {
+		q.mu.Lock()
+		e.job.Deliveries = append(e.job.Deliveries, attempt)
+		q.mu.Unlock()
+	})
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if delivered {
+		e.job.Delivery = DeliveryDelivered
+	} else {
+		e.job.Delivery = DeliveryFailed
+	}
+}
+
+// runRender keeps a panicking renderer from taking the worker, and the
+// process, down with it.
+func runRender(render RenderFunc) (document domain.GeneratedDocument, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("render panicked: %v", recovered)
+		}
+	}()
+	return render()
+}
+
+// pruneLocked drops finished jobs whose result has expired.
+func (q *Queue) pruneLocked() {
+	now := q.now()
+	for id, e := range q.entries {
+		if !e.job.ExpiresAt.IsZero() && !now.Before(e.job.ExpiresAt) {
+			delete(q.entries, id)
+		}
+	}
+}
+
+func newJobID() (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id[:]), nil
+}
@@ -0,0 +1,137 @@
+package jobs
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"pdf-service/internal/domain"
+)
+
+// waitForStatus polls until the job reaches want or the test times out.
+func waitForStatus(t *testing.T, q *Queue, id string, want Status) Job {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := q.Get(id)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", id, err)
+		}
+		if job.Status == want {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %s did not reach status %s", id, want)
+	return Job{}
+}
+
+func TestQueueRunsJobAndKeepsResult(t *testing.T) {
+	q := NewQueue(2, 4, time.Minute)
+	defer q.Close()
+
+	job, err := q.Submit("proposal", "proposta.pdf", func() (domain.GeneratedDocument, error) {
+		return domain.GeneratedDocument{PDF: []byte("%PDF"), Template: domain.TemplateProvenance{ID: "proposal-sale"}}, nil
+	})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if job.Status != StatusQueued || job.ID == "" {
+		t.Fatalf("expected a queued job with an ID, got %+v", job)
+	}
+
+	finished := waitForStatus(t, q, job.ID, StatusSucceeded)
+	if finished.Template.ID != "proposal-sale" || !finished.ExpiresAt.Equal(finished.FinishedAt.Add(time.Minute)) {
+		t.Fatalf("unexpected finished job %+v", finished)
+	}
+	_, document, err := q.Result(job.ID)
+	if err != nil || string(document.PDF) != "%PDF" {
+		t.Fatalf("Result() = %q, %v", document.PDF, err)
+	}
+}
+
+func TestQueueReportsRenderFailures(t *testing.T) {
+	q := NewQueue(1, 2, time.Minute)
+	defer q.Close()
+
+	renderErr := errors.New("boom")
+	failing, _ := q.Submit("contract", "", func() (domain.GeneratedDocument, error) {
+		return domain.GeneratedDocument{}, renderErr
+	})
+	panicking, _ := q.Submit("contract", "", func() (domain.GeneratedDocument, error) {
+		panic("unexpected")
+	})
+
+	if job := waitForStatus(t, q, failing.ID, StatusFailed); !errors.Is(job.Err, renderErr) {
+		t.Fatalf("expected the render error, got %v", job.Err)
+	}
+	if _, _, err := q.Result(failing.ID); !errors.Is(err, renderErr) {
+		t.Fatalf("expected Result to return the render error, got %v", err)
+	}
+	if job := waitForStatus(t, q, panicking.ID, StatusFailed); job.Err == nil {
+		t.Fatal("expected the panic to fail the job")
+	}
+}
+
+func TestQueueRejectsJobsBeyondCapacity(t *testing.T) {
+	q := NewQueue(1, 1, time.Minute)
+	release := make(chan struct{})
+	defer q.Close()
+	defer close(release)
+
+	blocking := func() (domain.GeneratedDocument, error) {
+		<-release
+		return domain.GeneratedDocument{}, nil
+	}
+	running, err := q.Submit("proposal", "", blocking)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	waitForStatus(t, q, running.ID, StatusRunning)
+
+	queued, err := q.Submit("proposal", "", blocking)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if _, _, err := q.Result(queued.ID); !errors.Is(err, ErrNotFinished) {
+		t.Fatalf("expected ErrNotFinished, got %v", err)
+	}
+	if _, err := q.Submit("proposal", "", blocking); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+}
+
+func TestQueueExpiresFinishedJobs(t *testing.T) {
+	var mu sync.Mutex
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	q := NewQueue(1, 1, 10*time.Minute, WithClock(clock))
+	defer q.Close()
+
+	job, _ := q.Submit("receipt", "", func() (domain.GeneratedDocument, error) {
+		return domain.GeneratedDocument{PDF: []byte("%PDF")}, nil
+	})
+	waitForStatus(t, q, job.ID, StatusSucceeded)
+
+	mu.Lock()
+	now = now.Add(10 * time.Minute)
+	mu.Unlock()
+
+	if _, err := q.Get(job.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected the expired job to be gone, got %v", err)
+	}
+}
+
+func TestQueueRejectsSubmitAfterClose(t *testing.T) {
+	q := NewQueue(1, 1, time.Minute)
+	q.Close()
+
+	if _, err := q.Submit("proposal", "", nil); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package jobs
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Webhook request headers. The signature is the hex HMAC-SHA256, keyed
+// with the shared secret, of the timestamp header, a dot and the raw body,
+// so receivers can reject replayed notifications.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	JobIDHeader     = "X-Webhook-Id"
+)
+
+// ErrPrivateTarget is returned when a callback would be posted to an
+// address that is not public.
+var ErrPrivateTarget = errors.New("callback target is not a public address")
+
+// PublicAddress reports whether ip may receive callbacks. Loopback,
+// private, link-local, multicast and unspecified addresses may not, so a
+// callback URL cannot make the service post to its own network.
+func PublicAddress(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+// DeliveryStatus is where a job's callback is.
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// DeliveryAttempt records one POST to a callback URL. StatusCode is zero
+// when no response was received.
+type DeliveryAttempt struct {
+	Attempt    int       `json:"attempt"`
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Notification is the JSON body posted to a callback URL when a job
+// finishes. SHA256 and DownloadURL are only set for succeeded jobs.
+type Notification struct {
+	DocumentID  string    `json:"document_id"`
+	Type        string    `json:"type"`
+	Status      Status    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	SHA256      string    `json:"sha256,omitempty"`
+	DownloadURL string    `json:"download_url,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Notifier posts signed notifications, retrying failed deliveries with
+// exponential backoff.
+type Notifier struct {
+	secret      []byte
+	client      *http.Client
+	maxAttempts int
+	baseDelay   time.Duration
+	resultURL   func(id string) string
+	errorText   func(error) string
+	sleep       func(time.Duration)
+	now         func() time.Time
+}
+
+// NotifierOption customizes a Notifier at construction time.
+type NotifierOption func(*Notifier)
+
+// WithHTTPClient replaces the client used for deliveries, together with
+// its refusal of redirects and of addresses that are not public.
+func WithHTTPClient(client *http.Client) NotifierOption {
+	return func(n *Notifier) {
+		n.client = client
+	}
+}
+
+// WithRetries sets how many times a delivery is tried and the delay before
+// the first retry, which doubles after every failed attempt.
+func WithRetries(maxAttempts int, baseDelay time.Duration) NotifierOption {
+	return func(n *Notifier) {
+		n.maxAttempts = max(maxAttempts, 1)
+		n.baseDelay = baseDelay
+	}
+}
+
+// WithResultURL sets how the download location of a job is built. By
+// default it is the path of the result endpoint.
+func WithResultURL(resultURL func(id string) string) NotifierOption {
+	return func(n *Notifier) {
+		n.resultURL = resultURL
+	}
+}
+
+// WithErrorText sets how a failed job's error is described in the
+// notification, so internal errors are not leaked to the receiver. By
+// default failed jobs carry no error text.
+func WithErrorText(errorText func(error) string) NotifierOption {
+	return func(n *Notifier) {
+		n.errorText = errorText
+	}
+}
+
+// NewNotifier signs notifications with secret.
+func NewNotifier(secret string, options ...NotifierOption) *Notifier {
+	n := &Notifier{
+		secret:      []byte(secret),
+		client:      newCallbackClient(),
+		maxAttempts: 5,
+		baseDelay:   time.Second,
+		resultURL:   func(id string) string { return "/jobs/" + id + "/result" },
+		errorText:   func(error) string { return "" },
+		sleep:       time.Sleep,
+		now:         time.Now,
+	}
+	for _, option := range options {
+		option(n)
+	}
+	return n
+}
+
+// newCallbackClient returns the default delivery client. It does not
+// follow redirects, and it only connects to public addresses: the check
+// runs on the resolved address, so a host name that points back at the
+// service's network is refused as well.
+func newCallbackClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !PublicAddress(ip) {
+				return fmt.Errorf("%w: %s", ErrPrivateTarget, host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Sign returns the signature header value for a body sent at timestamp.
+func (n *Notifier) Sign(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, n.secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// notification builds the body sent for a finished job.
+func (n *Notifier) notification(job Job) Notification {
+	notification := Notification{
+		DocumentID: job.ID,
+		Type:       job.Kind,
+		Status:     job.Status,
+		ExpiresAt:  job.ExpiresAt,
+	}
+	if job.Status == StatusSucceeded {
+		notification.SHA256 = job.SHA256
+		notification.DownloadURL = n.resultURL(job.ID)
+	} else {
+		notification.Error = n.errorText(job.Err)
+	}
+	return notification
+}
+
+// deliver posts the notification of job until it is accepted or the
+// attempts run out, reporting every attempt to record. It returns whether
+// the receiver accepted it.
+func (n *Notifier) deliver(job Job, record func(DeliveryAttempt)) bool {
+	body, err := json.Marshal(n.notification(job))
+	if err != nil {
+		record(DeliveryAttempt{Attempt: 1, At: n.now(), Error: err.Error()})
+		return false
+	}
+
+	delay := n.baseDelay
+	for attempt := 1; attempt <= n.maxAttempts; attempt++ {
+		if attempt > 1 {
+			n.sleep(delay)
+			delay *= 2
+		}
+		result := DeliveryAttempt{Attempt: attempt, At: n.now()}
+		result.StatusCode, err = n.post(job.CallbackURL, job.ID, body)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		record(result)
+		if err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// post sends one signed request. Any response outside 2xx is an error.
+func (n *Notifier) post(url, jobID string, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(n.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(JobIDHeader, jobID)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, n.Sign(timestamp, body))
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("receiver answered %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
@@ -0,0 +1,179 @@
+package jobs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pdf-service/internal/domain"
+)
+
+type receivedCallback struct {
+	header http.Header
+	body   []byte
+}
+
+// callbackReceiver answers the first failures requests with 503 and then
+// accepts, keeping every request it saw.
+func callbackReceiver(t *testing.T, failures int) (*httptest.Server, func() []receivedCallback) {
+	t.Helper()
+	var mu sync.Mutex
+	var received []receivedCallback
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, receivedCallback{header: r.Header.Clone(), body: body})
+		count := len(received)
+		mu.Unlock()
+		if count <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []receivedCallback {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]receivedCallback(nil), received...)
+	}
+}
+
+func waitForDelivery(t *testing.T, q *Queue, id string) Job {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := q.Get(id)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", id, err)
+		}
+		if job.Delivery != DeliveryPending {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("callback of job %s was not settled", id)
+	return Job{}
+}
+
+func TestQueueDeliversSignedCallbackWithRetries(t *testing.T) {
+	server, received := callbackReceiver(t, 2)
+	var delays []time.Duration
+	notifier := NewNotifier("test-secret",
+		WithHTTPClient(server.Client()),
+		WithRetries(4, 10*time.Millisecond),
+		WithResultURL(func(id string) string { return "https://pdf.example.com/jobs/" + id + "/result" }),
+	)
+	notifier.sleep = func(delay time.Duration) { delays = append(delays, delay) }
+	q := NewQueue(1, 1, time.Minute, WithNotifier(notifier))
+	defer q.Close()
+
+	submitted, err := q.Submit("proposal", "proposta.pdf", func() (domain.GeneratedDocument, error) {
+		return domain.GeneratedDocument{PDF: []byte("%PDF")}, nil
+	}, WithCallback(server.URL))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if submitted.Delivery != DeliveryPending {
+		t.Fatalf("expected a pending callback, got %q", submitted.Delivery)
+	}
+
+	job := waitForDelivery(t, q, submitted.ID)
+	if job.Delivery != DeliveryDelivered || len(job.Deliveries) != 3 {
+		t.Fatalf("expected delivery on the third attempt, got %s %+v", job.Delivery, job.Deliveries)
+	}
+	if job.Deliveries[0].StatusCode != http.StatusServiceUnavailable || job.Deliveries[0].Error == "" || job.Deliveries[2].StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected attempts %+v", job.Deliveries)
+	}
+	if len(delays) != 2 || delays[0] != 10*time.Millisecond || delays[1] != 20*time.Millisecond {
+		t.Fatalf("expected exponential backoff, got %v", delays)
+	}
+
+	last := received()[2]
+	if got, want := last.header.Get(SignatureHeader), notifier.Sign(last.header.Get(TimestampHeader), last.body); got != want {
+		t.Fatalf("signature %q does not match %q", got, want)
+	}
+	var notification Notification
+	if err := json.Unmarshal(last.body, &notification); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	wantSum := sha256.Sum256([]byte("%PDF"))
+	if notification.DocumentID != submitted.ID || notification.Status != StatusSucceeded || notification.SHA256 != hex.EncodeToString(wantSum[:]) {
+		t.Fatalf("unexpected notification %+v", notification)
+	}
+	if notification.DownloadURL != "https://pdf.example.com/jobs/"+submitted.ID+"/result" {
+		t.Fatalf("unexpected download URL %q", notification.DownloadURL)
+	}
+}
+
+func TestQueueGivesUpOnCallbackAfterMaxAttempts(t *testing.T) {
+	server, received := callbackReceiver(t, 10)
+	notifier := NewNotifier("test-secret", WithHTTPClient(server.Client()), WithRetries(3, 0), WithErrorText(func(error) string { return "failed to generate pdf" }))
+	q := NewQueue(1, 1, time.Minute, WithNotifier(notifier))
+	defer q.Close()
+
+	submitted, _ := q.Submit("contract", "", func() (domain.GeneratedDocument, error) {
+		return domain.GeneratedDocument{}, errors.New("boom")
+	}, WithCallback(server.URL))
+
+	job := waitForDelivery(t, q, submitted.ID)
+	if job.Status != StatusFailed || job.Delivery != DeliveryFailed || len(job.Deliveries) != 3 || len(received()) != 3 {
+		t.Fatalf("expected three failed attempts, got %s %+v", job.Delivery, job.Deliveries)
+	}
+	var notification Notification
+	if err := json.Unmarshal(received()[0].body, &notification); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if notification.Status != StatusFailed || notification.Error != "failed to generate pdf" || notification.SHA256 != "" || notification.DownloadURL != "" {
+		t.Fatalf("unexpected notification %+v", notification)
+	}
+}
+
+func TestQueueIgnoresCallbackWithoutNotifier(t *testing.T) {
+	q := NewQueue(1, 1, time.Minute)
+	defer q.Close()
+
+	job, _ := q.Submit("receipt", "", func() (domain.GeneratedDocument, error) {
+		return domain.GeneratedDocument{}, nil
+	}, WithCallback("http://127.0.0.1:1/hook"))
+	if q.AcceptsCallbacks() || job.CallbackURL != "" || job.Delivery != "" {
+		t.Fatalf("expected the callback to be dropped, got %+v", job)
+	}
+}
+
+func TestNotifierRefusesPrivateTargetsAndRedirects(t *testing.T) {
+	server, received := callbackReceiver(t, 0)
+	record := func(attempts *[]DeliveryAttempt) func(DeliveryAttempt) {
+		return func(attempt DeliveryAttempt) { *attempts = append(*attempts, attempt) }
+	}
+	job := Job{ID: "job-1", Kind: "receipt", Status: StatusSucceeded, CallbackURL: server.URL}
+
+	var attempts []DeliveryAttempt
+	if NewNotifier("test-secret", WithRetries(1, 0)).deliver(job, record(&attempts)) {
+		t.Fatal("expected a loopback callback to be refused")
+	}
+	if len(received()) != 0 || !strings.Contains(attempts[0].Error, ErrPrivateTarget.Error()) {
+		t.Fatalf("expected no request to reach the receiver, got %d and %+v", len(received()), attempts)
+	}
+
+	redirect := httptest.NewServer(http.RedirectHandler(server.URL, http.StatusTemporaryRedirect))
+	defer redirect.Close()
+	notifier := NewNotifier("test-secret", WithRetries(1, 0))
+	notifier.client.Transport = http.DefaultTransport
+	job.CallbackURL = redirect.URL
+	attempts = nil
+	if notifier.deliver(job, record(&attempts)) || attempts[0].StatusCode != http.StatusTemporaryRedirect {
+		t.Fatalf("expected the redirect to be reported, got %+v", attempts)
+	}
+	if len(received()) != 0 {
+		t.Fatal("expected the redirect not to be followed")
+	}
+}
@@ -0,0 +1,217 @@
+package pdfmerge
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+	"unicode/utf16"
+)
+
+// Part is one document of a merged file. A part with a title gets a
+// bookmark pointing at its first page.
+type Part struct {
+	Title    string
+	Document *Document
+}
+
+// Info fills the document information dictionary of the merged file.
+type Info struct {
+	Title    string
+	Producer string
+	Created  time.Time
+}
+
+// Merge writes the pages of every part, in order, into one file that opens
+// with the bookmarks shown. Each part brings along only the objects its
+// pages use; form fields and named destinations of the parts are not
+// carried over. The output has a classic cross-reference table and no
+// /ID, like the documents gofpdf writes, and only depends on its input.
+func Merge(parts []Part, info Info) ([]byte, error) {
+	w := &writer{objects: []Object{nil}}
+	catalog, pagesRoot, infoRef := w.reserve(), w.reserve(), w.reserve()
+
+	var kids Array
+	var bookmarks []bookmark
+	for _, part := range parts {
+		c := &copier{writer: w, document: part.Document, mapped: map[int]Ref{}}
+		// Pages get their numbers first, so links between them land on
+		// the copies.
+		for _, pg := range part.Document.pages {
+			c.mapped[pg.ref.Num] = w.reserve()
+		}
+		for i, pg := range part.Document.pages {
+			copied := Dict{}
+			for _, key := range slices.Sorted(maps.Keys(pg.dict)) {
+				if key != "Parent" {
+					copied[key] = c.copy(pg.dict[key])
+				}
+			}
+			copied["Parent"] = pagesRoot
+			ref := c.mapped[pg.ref.Num]
+			w.set(ref, copied)
+			kids = append(kids, ref)
+			if i == 0 && part.Title != "" {
+				bookmarks = append(bookmarks, bookmark{title: part.Title, page: ref})
+			}
+		}
+		if err := c.flush(); err != nil {
+			return nil, err
+		}
+	}
+	w.set(pagesRoot, Dict{"Type": Name("Pages"), "Kids": kids, "Count": len(kids)})
+
+	catalogDict := Dict{"Type": Name("Catalog"), "Pages": pagesRoot}
+	if len(bookmarks) > 0 {
+		catalogDict["Outlines"] = w.outlines(bookmarks)
+		catalogDict["PageMode"] = Name("UseOutlines")
+	}
+	w.set(catalog, catalogDict)
+
+	created := String(info.Created.UTC().Format("D:20060102150405Z"))
+	infoDict := Dict{"CreationDate": created, "ModDate": created}
+	if info.Title != "" {
+		infoDict["Title"] = textString(info.Title)
+	}
+	if info.Producer != "" {
+		infoDict["Producer"] = textString(info.Producer)
+	}
+	w.set(infoRef, infoDict)
+
+	return w.bytes(catalog, infoRef), nil
+}
+
+type bookmark struct {
+	title string
+	page  Ref
+}
+
+// writer numbers the objects of the output file.
+type writer struct {
+	objects []Object
+}
+
+func (w *writer) reserve() Ref {
+	w.objects = append(w.objects, nil)
+	return Ref{Num: len(w.objects) - 1}
+}
+
+func (w *writer) set(ref Ref, object Object) {
+	w.objects[ref.Num] = object
+}
+
+// outlines writes a flat outline with one item per bookmark, each opening
+// its page fitted to the window.
+func (w *writer) outlines(bookmarks []bookmark) Ref {
+	root := w.reserve()
+	items := make([]Ref, len(bookmarks))
+	for i := range items {
+		items[i] = w.reserve()
+	}
+	for i, b := range bookmarks {
+		item := Dict{
+			"Title":  textString(b.title),
+			"Parent": root,
+			"Dest":   Array{b.page, Name("Fit")},
+		}
+		if i > 0 {
+			item["Prev"] = items[i-1]
+		}
+		if i < len(items)-1 {
+			item["Next"] = items[i+1]
+		}
+		w.set(items[i], item)
+	}
+	w.set(root, Dict{"Type": Name("Outlines"), "First": items[0], "Last": items[len(items)-1], "Count": len(items)})
+	return root
+}
+
+func (w *writer) bytes(catalog, info Ref) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(w.objects))
+	for num := 1; num < len(w.objects); num++ {
+		offsets[num] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", num)
+		writeObject(&buf, w.objects[num])
+		buf.WriteString("\nendobj\n")
+	}
+	xrefAt := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.objects))
+	for num := 1; num < len(w.objects); num++ {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offsets[num])
+	}
+	fmt.Fprintf(&buf, "trailer\n<<\n/Size %d\n/Root %d 0 R\n/Info %d 0 R\n>>\nstartxref\n%d\n%%%%EOF\n", len(w.objects), catalog.Num, info.Num, xrefAt)
+	return buf.Bytes()
+}
+
+// copier copies objects of one document into the output, giving each
+// referenced object a new number the first time it is seen. Dictionaries
+// are walked in key order, so the numbering only depends on the input.
+type copier struct {
+	writer   *writer
+	document *Document
+	mapped   map[int]Ref
+	queue    []int
+}
+
+func (c *copier) copy(object Object) Object {
+	switch value := object.(type) {
+	case Ref:
+		if ref, ok := c.mapped[value.Num]; ok {
+			return ref
+		}
+		ref := c.writer.reserve()
+		c.mapped[value.Num] = ref
+		c.queue = append(c.queue, value.Num)
+		return ref
+	case Array:
+		copied := make(Array, len(value))
+		for i, item := range value {
+			copied[i] = c.copy(item)
+		}
+		return copied
+	case Dict:
+		copied := make(Dict, len(value))
+		for _, key := range slices.Sorted(maps.Keys(value)) {
+			copied[key] = c.copy(value[key])
+		}
+		return copied
+	case Stream:
+		// The writer sets /Length itself; an indirect one is not needed.
+		dict := make(Dict, len(value.Dict))
+		for _, key := range slices.Sorted(maps.Keys(value.Dict)) {
+			if key != "Length" {
+				dict[key] = c.copy(value.Dict[key])
+			}
+		}
+		return Stream{Dict: dict, Data: value.Data}
+	default:
+		return value
+	}
+}
+
+// flush copies the referenced objects not yet written.
+func (c *copier) flush() error {
+	for len(c.queue) > 0 {
+		num := c.queue[0]
+		c.queue = c.queue[1:]
+		object, err := c.document.object(num)
+		if err != nil {
+			return err
+		}
+		c.writer.set(c.mapped[num], c.copy(object))
+	}
+	return nil
+}
+
+// textString encodes a text string as UTF-16BE with a byte order mark,
+// which every reader accepts whatever the characters.
+func textString(value string) String {
+	encoded := []byte{0xfe, 0xff}
+	for _, unit := range utf16.Encode([]rune(value)) {
+		encoded = append(encoded, byte(unit>>8), byte(unit))
+	}
+	return encoded
+}
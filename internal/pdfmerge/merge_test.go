@@ -0,0 +1,242 @@
+package pdfmerge
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// testPDF writes an uncompressed gofpdf document with one page per text.
+func testPDF(t testing.TB, texts ...string) []byte {
+	t.Helper()
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCompression(false)
+	pdf.SetFont("Helvetica", "", 12)
+	for _, text := range texts {
+		pdf.AddPage()
+		pdf.Cell(0, 10, text)
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func openPDF(t *testing.T, data []byte) *Document {
+	t.Helper()
+	doc, err := Open(data)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return doc
+}
+
+// pageContents returns the content stream of each page.
+func pageContents(t *testing.T, doc *Document) []string {
+	t.Helper()
+	contents := make([]string, len(doc.pages))
+	for i, pg := range doc.pages {
+		object, err := doc.resolve(pg.dict["Contents"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream, ok := object.(Stream)
+		if !ok {
+			t.Fatalf("page %d has no content stream", i+1)
+		}
+		contents[i] = string(stream.Data)
+	}
+	return contents
+}
+
+func decodeTextString(value String) string {
+	if !bytes.HasPrefix(value, []byte{0xfe, 0xff}) {
+		return string(value)
+	}
+	units := make([]uint16, 0, len(value)/2)
+	for i := 2; i+1 < len(value); i += 2 {
+		units = append(units, uint16(value[i])<<8|uint16(value[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+func TestMergeKeepsPageOrderAndBookmarksEachPart(t *testing.T) {
+	first := openPDF(t, testPDF(t, "first part, page one", "first part, page two"))
+	second := openPDF(t, testPDF(t, "second part"))
+
+	created := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	merged, err := Merge([]Part{{Title: "Proposta", Document: first}, {Title: "Cópia do RG", Document: second}}, Info{Title: "Dossiê", Created: created})
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	again, _ := Merge([]Part{{Title: "Proposta", Document: first}, {Title: "Cópia do RG", Document: second}}, Info{Title: "Dossiê", Created: created})
+	if !bytes.Equal(merged, again) {
+		t.Fatal("expected identical input to merge to identical bytes")
+	}
+
+	doc := openPDF(t, merged)
+	contents := pageContents(t, doc)
+	if len(contents) != 3 {
+		t.Fatalf("expected 3 pages, got %d", len(contents))
+	}
+	for i, want := range []string{"(first part, page one)", "(first part, page two)", "(second part)"} {
+		if !strings.Contains(contents[i], want) {
+			t.Fatalf("expected page %d to show %s, got %q", i+1, want, contents[i])
+		}
+	}
+	for _, pg := range doc.pages {
+		if _, ok := pg.dict["Resources"].(Ref); !ok {
+			t.Fatalf("expected the page resources to be copied, got %v", pg.dict["Resources"])
+		}
+	}
+
+	catalog, _ := doc.resolve(doc.trailer["Root"])
+	outlines, _ := doc.resolve(catalog.(Dict)["Outlines"])
+	var titles []string
+	var targets []int
+	for item := outlines.(Dict)["First"]; item != nil; {
+		dict, _ := doc.resolve(item)
+		titles = append(titles, decodeTextString(dict.(Dict)["Title"].(String)))
+		targets = append(targets, dict.(Dict)["Dest"].(Array)[0].(Ref).Num)
+		item = dict.(Dict)["Next"]
+	}
+	if strings.Join(titles, "|") != "Proposta|Cópia do RG" {
+		t.Fatalf("unexpected bookmarks %q", titles)
+	}
+	if targets[0] != doc.pages[0].ref.Num || targets[1] != doc.pages[2].ref.Num {
+		t.Fatalf("expected the bookmarks to open pages 1 and 3, got objects %v", targets)
+	}
+}
+
+func TestOpenReadsCrossReferenceAndObjectStreams(t *testing.T) {
+	compress := func(data []byte) []byte {
+		var buf bytes.Buffer
+		writer := zlib.NewWriter(&buf)
+		_, _ = writer.Write(data)
+		_ = writer.Close()
+		return buf.Bytes()
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.5\n")
+	offsets := map[int]int{}
+	content := "BT /F1 12 Tf (from an object stream) Tj ET"
+	offsets[4] = out.Len()
+	fmt.Fprintf(&out, "4 0 obj\n<< /Length %d >>\nstream\n%s\nendstream\nendobj\n", len(content), content)
+
+	var header, body strings.Builder
+	for i, object := range []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 200 200] >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+	} {
+		fmt.Fprintf(&header, "%d %d ", i+1, body.Len())
+		body.WriteString(object + "\n")
+	}
+	objects := compress([]byte(header.String() + body.String()))
+	offsets[5] = out.Len()
+	fmt.Fprintf(&out, "5 0 obj\n<< /Type /ObjStm /N 3 /First %d /Filter /FlateDecode /Length %d >>\nstream\n", header.Len(), len(objects))
+	out.Write(objects)
+	out.WriteString("\nendstream\nendobj\n")
+
+	offsets[6] = out.Len()
+	rows := [][]int{{0, 0, 255}, {2, 5, 0}, {2, 5, 1}, {2, 5, 2}, {1, offsets[4], 0}, {1, offsets[5], 0}, {1, offsets[6], 0}}
+	var table []byte
+	previous := make([]byte, 4)
+	for _, row := range rows {
+		raw := []byte{byte(row[0]), byte(row[1] >> 8), byte(row[1]), byte(row[2])}
+		// PNG "Up" predictor: each byte minus the one above it.
+		table = append(table, 2)
+		for i := range raw {
+			table = append(table, raw[i]-previous[i])
+		}
+		previous = raw
+	}
+	xref := compress(table)
+	fmt.Fprintf(&out, "6 0 obj\n<< /Type /XRef /Size 7 /W [1 2 1] /Root 1 0 R /Filter /FlateDecode /DecodeParms << /Predictor 12 /Columns 4 >> /Length %d >>\nstream\n", len(xref))
+	out.Write(xref)
+	fmt.Fprintf(&out, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", offsets[6])
+
+	doc := openPDF(t, out.Bytes())
+	if doc.PageCount() != 1 {
+		t.Fatalf("expected 1 page, got %d", doc.PageCount())
+	}
+	if got := pageContents(t, doc)[0]; got != content {
+		t.Fatalf("unexpected content %q", got)
+	}
+	if box, ok := doc.pages[0].dict["MediaBox"].(Array); !ok || len(box) != 4 {
+		t.Fatalf("expected the inherited media box, got %v", doc.pages[0].dict["MediaBox"])
+	}
+}
+
+func TestOpenReadsTheLatestIncrementalUpdate(t *testing.T) {
+	original := testPDF(t, "original text")
+	doc := openPDF(t, original)
+	contentsRef := doc.pages[0].dict["Contents"].(Ref)
+	size, _ := intValue(doc.trailer["Size"])
+	root := doc.trailer["Root"].(Ref)
+	prev := regexp.MustCompile(`startxref\s+(\d+)`).FindSubmatch(original)[1]
+
+	var out bytes.Buffer
+	out.Write(original)
+	content := "BT /F1 12 Tf (updated text) Tj ET"
+	at := out.Len()
+	fmt.Fprintf(&out, "%d 0 obj\n<< /Length %d >>\nstream\n%s\nendstream\nendobj\n", contentsRef.Num, len(content), content)
+	xrefAt := out.Len()
+	fmt.Fprintf(&out, "xref\n%d 1\n%010d 00000 n \ntrailer\n<< /Size %d /Root %d 0 R /Prev %s >>\nstartxref\n%d\n%%%%EOF\n", contentsRef.Num, at, size, root.Num, prev, xrefAt)
+
+	if got := pageContents(t, openPDF(t, out.Bytes()))[0]; got != content {
+		t.Fatalf("expected the updated content, got %q", got)
+	}
+}
+
+func TestOpenRecoversFromABrokenCrossReference(t *testing.T) {
+	data := regexp.MustCompile(`startxref\n\d+`).ReplaceAll(testPDF(t, "page one", "page two"), []byte("startxref\n12"))
+	doc := openPDF(t, data)
+	if doc.PageCount() != 2 {
+		t.Fatalf("expected 2 pages, got %d", doc.PageCount())
+	}
+}
+
+func TestOpenRejectsEncryptedAndInvalidFiles(t *testing.T) {
+	encrypted := bytes.Replace(testPDF(t, "secret"), []byte("\ntrailer\n<<\n"), []byte("\ntrailer\n<<\n/Encrypt 99 0 R\n"), 1)
+	if _, err := Open(encrypted); !errors.Is(err, ErrEncrypted) {
+		t.Fatalf("expected ErrEncrypted, got %v", err)
+	}
+	for name, data := range map[string][]byte{
+		"not a pdf": []byte("GIF89a"),
+		"truncated": testPDF(t, "page")[:200],
+		"no pages":  []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n2 0 obj\n<< /Type /Pages /Kids [] /Count 0 >>\nendobj\ntrailer\n<< /Root 1 0 R >>\n"),
+	} {
+		if _, err := Open(data); !errors.Is(err, ErrMalformed) {
+			t.Fatalf("%s: expected ErrMalformed, got %v", name, err)
+		}
+	}
+}
+
+func FuzzOpen(f *testing.F) {
+	f.Add(testPDF(f, "page one", "page two"))
+	f.Add([]byte("%PDF-1.5\n1 0 obj\n<< /Type /XRef /Size 2 /W [1 -1 1] /Root 1 0 R /Length 3 >>\nstream\n\x01\x00\x00\nendstream\nendobj\nstartxref\n9\n%%EOF\n"))
+	f.Add([]byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n3 0 obj\n<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>\nendobj\n4 0 obj\n<< /Length 0 >>\nstream\n\nendstream\nendobj\ntrailer\n<< /Root 1 0 R >>\n"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		doc, err := Open(data)
+		if err != nil {
+			if !errors.Is(err, ErrMalformed) && !errors.Is(err, ErrEncrypted) {
+				t.Fatalf("expected ErrMalformed or ErrEncrypted, got %v", err)
+			}
+			return
+		}
+		if _, err := Merge([]Part{{Title: "Anexo", Document: doc}}, Info{}); err != nil && !errors.Is(err, ErrMalformed) {
+			t.Fatalf("Merge() error = %v", err)
+		}
+	})
+}
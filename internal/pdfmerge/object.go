@@ -0,0 +1,313 @@
+package pdfmerge
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// The PDF object types. Numbers keep their source text so they are written
+// back exactly; names are stored without the leading slash, as written.
+type (
+	Object any
+	Name   string
+	Number string
+	String []byte
+	Array  []Object
+	Dict   map[Name]Object
+	Ref    struct{ Num, Gen int }
+	// Stream keeps its data encoded, as read from the file.
+	Stream struct {
+		Dict Dict
+		Data []byte
+	}
+)
+
+// maxNesting bounds how deep arrays and dictionaries may nest, so a
+// hostile file cannot exhaust the stack.
+const maxNesting = 64
+
+// parser reads objects from a byte slice.
+type parser struct {
+	data []byte
+	pos  int
+}
+
+func isWhitespace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// skipSpace skips whitespace and comments.
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) {
+		switch c := p.data[p.pos]; {
+		case isWhitespace(c):
+			p.pos++
+		case c == '%':
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// token reads a run of regular characters.
+func (p *parser) token() string {
+	start := p.pos
+	for p.pos < len(p.data) && !isWhitespace(p.data[p.pos]) && !isDelimiter(p.data[p.pos]) {
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+// keyword reads the next token and reports whether it is want.
+func (p *parser) keyword(want string) bool {
+	p.skipSpace()
+	start := p.pos
+	if p.token() == want {
+		return true
+	}
+	p.pos = start
+	return false
+}
+
+// integer reads a non-negative integer token.
+func (p *parser) integer() (int, bool) {
+	p.skipSpace()
+	start := p.pos
+	value, err := strconv.Atoi(p.token())
+	if err != nil || value < 0 {
+		p.pos = start
+		return 0, false
+	}
+	return value, true
+}
+
+func (p *parser) object(depth int) (Object, error) {
+	if depth > maxNesting {
+		return nil, fmt.Errorf("%w: objects nested too deep", ErrMalformed)
+	}
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrMalformed)
+	}
+	switch c := p.data[p.pos]; {
+	case c == '/':
+		p.pos++
+		return Name(p.token()), nil
+	case c == '(':
+		return p.literalString()
+	case c == '<' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '<':
+		return p.dictionary(depth)
+	case c == '<':
+		return p.hexString()
+	case c == '[':
+		p.pos++
+		array := Array{}
+		for {
+			p.skipSpace()
+			if p.pos < len(p.data) && p.data[p.pos] == ']' {
+				p.pos++
+				return array, nil
+			}
+			item, err := p.object(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, item)
+		}
+	}
+
+	start := p.pos
+	word := p.token()
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	case "":
+		return nil, fmt.Errorf("%w: unexpected %q at %d", ErrMalformed, p.data[start], start)
+	}
+	if _, err := strconv.ParseFloat(word, 64); err != nil {
+		return nil, fmt.Errorf("%w: unexpected %q at %d", ErrMalformed, word, start)
+	}
+	// An integer followed by a generation and R is a reference.
+	if num, err := strconv.Atoi(word); err == nil && num >= 0 {
+		after := p.pos
+		if gen, ok := p.integer(); ok && p.keyword("R") {
+			return Ref{Num: num, Gen: gen}, nil
+		}
+		p.pos = after
+	}
+	return Number(word), nil
+}
+
+func (p *parser) dictionary(depth int) (Dict, error) {
+	p.pos += 2
+	dict := Dict{}
+	for {
+		p.skipSpace()
+		if bytes.HasPrefix(p.data[p.pos:], []byte(">>")) {
+			p.pos += 2
+			return dict, nil
+		}
+		key, err := p.object(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(Name)
+		if !ok {
+			return nil, fmt.Errorf("%w: dictionary key is not a name at %d", ErrMalformed, p.pos)
+		}
+		value, err := p.object(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		dict[name] = value
+	}
+}
+
+func (p *parser) literalString() (String, error) {
+	p.pos++
+	var out []byte
+	for nesting := 0; p.pos < len(p.data); p.pos++ {
+		c := p.data[p.pos]
+		switch {
+		case c == '(':
+			nesting++
+		case c == ')' && nesting == 0:
+			p.pos++
+			return out, nil
+		case c == ')':
+			nesting--
+		case c == '\\' && p.pos+1 < len(p.data):
+			p.pos++
+			switch e := p.data[p.pos]; e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				// A backslash before an end of line continues the string.
+				if p.pos+1 < len(p.data) && p.data[p.pos+1] == '\n' {
+					p.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					value := 0
+					for i := 0; i < 3 && p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; i++ {
+						value = value*8 + int(p.data[p.pos]-'0')
+						p.pos++
+					}
+					p.pos--
+					c = byte(value)
+				} else {
+					c = e
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return nil, fmt.Errorf("%w: unterminated string", ErrMalformed)
+}
+
+func (p *parser) hexString() (String, error) {
+	p.pos++
+	end := bytes.IndexByte(p.data[p.pos:], '>')
+	if end < 0 {
+		return nil, fmt.Errorf("%w: unterminated hex string", ErrMalformed)
+	}
+	digits := make([]byte, 0, end)
+	for _, c := range p.data[p.pos : p.pos+end] {
+		if !isWhitespace(c) {
+			digits = append(digits, c)
+		}
+	}
+	p.pos += end + 1
+	if len(digits)%2 == 1 {
+		// A missing final digit is read as 0.
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	if _, err := hex.Decode(out, digits); err != nil {
+		return nil, fmt.Errorf("%w: bad hex string", ErrMalformed)
+	}
+	return out, nil
+}
+
+// writeObject serializes an object. Dictionary keys are sorted so the
+// output only depends on the input.
+func writeObject(buf *bytes.Buffer, object Object) {
+	switch value := object.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(value))
+	case int:
+		buf.WriteString(strconv.Itoa(value))
+	case Number:
+		buf.WriteString(string(value))
+	case Name:
+		buf.WriteString("/" + string(value))
+	case String:
+		buf.WriteString("<" + hex.EncodeToString(value) + ">")
+	case Ref:
+		fmt.Fprintf(buf, "%d %d R", value.Num, value.Gen)
+	case Array:
+		buf.WriteByte('[')
+		for i, item := range value {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			writeObject(buf, item)
+		}
+		buf.WriteByte(']')
+	case Dict:
+		buf.WriteString("<<")
+		for i, key := range slices.Sorted(maps.Keys(value)) {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString("/" + string(key) + " ")
+			writeObject(buf, value[key])
+		}
+		buf.WriteString(">>")
+	case Stream:
+		dict := maps.Clone(value.Dict)
+		dict["Length"] = len(value.Data)
+		writeObject(buf, dict)
+		buf.WriteString("\nstream\n")
+		buf.Write(value.Data)
+		buf.WriteString("\nendstream")
+	default:
+		panic(fmt.Sprintf("pdfmerge: cannot write %T", object))
+	}
+}
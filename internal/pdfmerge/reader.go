@@ -0,0 +1,668 @@
+// Package pdfmerge reads PDF files and writes their pages, in order, into
+// a single file with a bookmark per file. It understands classic and
+// compressed cross-reference tables, object streams and incremental
+// updates; encrypted files are refused.
+package pdfmerge
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"strconv"
+)
+
+var (
+	// ErrMalformed is returned by Open for data that is not a readable PDF.
+	ErrMalformed = errors.New("malformed pdf")
+	// ErrEncrypted is returned by Open for password-protected files.
+	ErrEncrypted = errors.New("pdf is encrypted")
+
+	objectHeaderPattern = regexp.MustCompile(`(?:\A|[\r\n])[ \t]*(\d+)[ \t\r\n\f]+(\d+)[ \t\r\n\f]+obj\b`)
+)
+
+// maxDecodedStream bounds the size of a decompressed cross-reference or
+// object stream.
+const maxDecodedStream = 64 << 20
+
+// inheritableAttributes are the page attributes a page takes from its
+// ancestors in the page tree when it does not set them itself.
+var inheritableAttributes = []Name{"Resources", "MediaBox", "CropBox", "Rotate"}
+
+// Document is a parsed PDF. Objects are read from the file as pages are
+// copied out of it.
+type Document struct {
+	data          []byte
+	xref          map[int]xrefEntry
+	trailer       Dict
+	objects       map[int]Object
+	loading       map[int]bool
+	objectStreams map[int]*objectStream
+	pages         []page
+}
+
+// xrefEntry locates an object: at an offset in the file, or at an index
+// in an object stream.
+type xrefEntry struct {
+	offset     int
+	compressed bool
+	stream     int
+	index      int
+}
+
+// objectStream is a decoded object stream with the numbers and offsets
+// of the objects it holds.
+type objectStream struct {
+	data    []byte
+	first   int
+	nums    []int
+	offsets []int
+}
+
+// page is a page dictionary with its inherited attributes filled in.
+type page struct {
+	ref  Ref
+	dict Dict
+}
+
+// Open parses data. When the cross-reference table is missing or broken,
+// as happens with files that went through careless tools, the objects are
+// found by scanning the file instead.
+func Open(data []byte) (*Document, error) {
+	if !bytes.Contains(data[:min(len(data), 1024)], []byte("%PDF-")) {
+		return nil, fmt.Errorf("%w: no PDF header", ErrMalformed)
+	}
+	d := &Document{data: data}
+	err := d.load(d.readXRefChain)
+	if err != nil && !errors.Is(err, ErrEncrypted) {
+		if d.load(d.reconstruct) == nil {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// PageCount returns the number of pages.
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) load(readXRef func() error) error {
+	d.xref = map[int]xrefEntry{}
+	d.trailer = nil
+	d.objects = map[int]Object{}
+	d.loading = map[int]bool{}
+	d.objectStreams = map[int]*objectStream{}
+	if err := readXRef(); err != nil {
+		return err
+	}
+	if _, ok := d.trailer["Encrypt"]; ok {
+		return ErrEncrypted
+	}
+	pages, err := d.loadPages()
+	if err != nil {
+		return err
+	}
+	if len(pages) == 0 {
+		return fmt.Errorf("%w: no pages", ErrMalformed)
+	}
+	d.pages = pages
+	return nil
+}
+
+// setEntry records an object location unless a newer section already did.
+func (d *Document) setEntry(num int, entry xrefEntry) {
+	if _, ok := d.xref[num]; !ok {
+		d.xref[num] = entry
+	}
+}
+
+// readXRefChain reads the newest cross-reference section and the older
+// ones it points to through /Prev.
+func (d *Document) readXRefChain() error {
+	at := bytes.LastIndex(d.data, []byte("startxref"))
+	if at < 0 {
+		return fmt.Errorf("%w: no startxref", ErrMalformed)
+	}
+	p := parser{data: d.data, pos: at + len("startxref")}
+	offset, ok := p.integer()
+	visited := map[int]bool{}
+	for ok && !visited[offset] {
+		visited[offset] = true
+		trailer, err := d.readXRefSection(offset)
+		if err != nil {
+			return err
+		}
+		if d.trailer == nil {
+			d.trailer = trailer
+		}
+		// Hybrid files list their compressed objects in a separate stream.
+		if stm, ok := intValue(trailer["XRefStm"]); ok {
+			if _, err := d.readXRefSection(stm); err != nil {
+				return err
+			}
+		}
+		offset, ok = intValue(trailer["Prev"])
+	}
+	if d.trailer == nil {
+		return fmt.Errorf("%w: no trailer", ErrMalformed)
+	}
+	return nil
+}
+
+// readXRefSection reads a cross-reference table and its trailer, or a
+// cross-reference stream, at offset.
+func (d *Document) readXRefSection(offset int) (Dict, error) {
+	if offset < 0 || offset >= len(d.data) {
+		return nil, fmt.Errorf("%w: cross-reference offset out of range", ErrMalformed)
+	}
+	p := parser{data: d.data, pos: offset}
+	if !p.keyword("xref") {
+		return d.readXRefStream(offset)
+	}
+	for {
+		first, ok := p.integer()
+		if !ok {
+			break
+		}
+		count, ok := p.integer()
+		if !ok {
+			return nil, fmt.Errorf("%w: bad cross-reference subsection", ErrMalformed)
+		}
+		for i := range count {
+			entryOffset, okOffset := p.integer()
+			_, okGen := p.integer()
+			p.skipSpace()
+			kind := p.token()
+			if !okOffset || !okGen || (kind != "n" && kind != "f") {
+				return nil, fmt.Errorf("%w: bad cross-reference entry", ErrMalformed)
+			}
+			if kind == "n" && entryOffset > 0 {
+				d.setEntry(first+i, xrefEntry{offset: entryOffset})
+			}
+		}
+	}
+	if !p.keyword("trailer") {
+		return nil, fmt.Errorf("%w: no trailer", ErrMalformed)
+	}
+	trailer, err := p.object(0)
+	if err != nil {
+		return nil, err
+	}
+	dict, ok := trailer.(Dict)
+	if !ok {
+		return nil, fmt.Errorf("%w: trailer is not a dictionary", ErrMalformed)
+	}
+	return dict, nil
+}
+
+func (d *Document) readXRefStream(offset int) (Dict, error) {
+	_, object, err := d.readIndirect(offset)
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := object.(Stream)
+	if !ok || stream.Dict["Type"] != Name("XRef") {
+		return nil, fmt.Errorf("%w: no cross-reference at %d", ErrMalformed, offset)
+	}
+	data, err := d.decodeStream(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	widths, ok := stream.Dict["W"].(Array)
+	if !ok || len(widths) != 3 {
+		return nil, fmt.Errorf("%w: bad cross-reference stream /W", ErrMalformed)
+	}
+	var w [3]int
+	for i := range w {
+		if w[i], ok = intValue(widths[i]); !ok || w[i] < 0 || w[i] > 8 {
+			return nil, fmt.Errorf("%w: bad cross-reference stream /W", ErrMalformed)
+		}
+	}
+	index, ok := stream.Dict["Index"].(Array)
+	if !ok {
+		size, _ := intValue(stream.Dict["Size"])
+		index = Array{0, size}
+	}
+
+	entrySize := w[0] + w[1] + w[2]
+	for i := 0; i+1 < len(index); i += 2 {
+		first, okFirst := intValue(index[i])
+		count, okCount := intValue(index[i+1])
+		if !okFirst || !okCount {
+			return nil, fmt.Errorf("%w: bad cross-reference stream /Index", ErrMalformed)
+		}
+		for j := 0; j < count && len(data) >= entrySize && entrySize > 0; j++ {
+			kind := 1
+			if w[0] > 0 {
+				kind = bigEndian(data[:w[0]])
+			}
+			field2 := bigEndian(data[w[0] : w[0]+w[1]])
+			field3 := bigEndian(data[w[0]+w[1] : entrySize])
+			data = data[entrySize:]
+			switch kind {
+			case 1:
+				d.setEntry(first+j, xrefEntry{offset: field2})
+			case 2:
+				d.setEntry(first+j, xrefEntry{compressed: true, stream: field2, index: field3})
+			}
+		}
+	}
+	return stream.Dict, nil
+}
+
+func bigEndian(field []byte) int {
+	value := 0
+	for _, b := range field {
+		value = value<<8 | int(b)
+	}
+	return value
+}
+
+// reconstruct finds every object by its "N G obj" header, the last
+// definition winning as it would in an incremental update, and takes the
+// trailer from the last trailer or cross-reference stream with a /Root.
+func (d *Document) reconstruct() error {
+	var found []int
+	for _, match := range objectHeaderPattern.FindAllSubmatchIndex(d.data, -1) {
+		num, err := strconv.Atoi(string(d.data[match[2]:match[3]]))
+		if err != nil {
+			continue
+		}
+		d.xref[num] = xrefEntry{offset: match[2]}
+		found = append(found, num)
+	}
+
+	if at := bytes.LastIndex(d.data, []byte("trailer")); at >= 0 {
+		p := parser{data: d.data, pos: at + len("trailer")}
+		if trailer, err := p.object(0); err == nil {
+			if dict, ok := trailer.(Dict); ok && dict["Root"] != nil {
+				d.trailer = dict
+			}
+		}
+	}
+	for _, num := range found {
+		stream, ok := d.objectOrNil(num).(Stream)
+		if !ok {
+			continue
+		}
+		switch stream.Dict["Type"] {
+		case Name("XRef"):
+			if stream.Dict["Root"] != nil {
+				d.trailer = stream.Dict
+			}
+		case Name("ObjStm"):
+			objects, err := d.objectStream(num)
+			if err != nil {
+				continue
+			}
+			for i, contained := range objects.nums {
+				d.setEntry(contained, xrefEntry{compressed: true, stream: num, index: i})
+			}
+		}
+	}
+	if d.trailer == nil {
+		for num := range d.xref {
+			if dict, ok := d.objectOrNil(num).(Dict); ok && dict["Type"] == Name("Catalog") {
+				d.trailer = Dict{"Root": Ref{Num: num}}
+				break
+			}
+		}
+	}
+	if d.trailer == nil {
+		return fmt.Errorf("%w: no document catalog", ErrMalformed)
+	}
+	return nil
+}
+
+// readIndirect parses the object defined at offset.
+func (d *Document) readIndirect(offset int) (int, Object, error) {
+	p := parser{data: d.data, pos: offset}
+	num, okNum := p.integer()
+	_, okGen := p.integer()
+	if !okNum || !okGen || !p.keyword("obj") {
+		return 0, nil, fmt.Errorf("%w: no object at %d", ErrMalformed, offset)
+	}
+	object, err := p.object(0)
+	if err != nil {
+		return 0, nil, err
+	}
+	dict, ok := object.(Dict)
+	if !ok || !p.keyword("stream") {
+		return num, object, nil
+	}
+	if p.pos < len(d.data) && d.data[p.pos] == '\r' {
+		p.pos++
+	}
+	if p.pos < len(d.data) && d.data[p.pos] == '\n' {
+		p.pos++
+	}
+	return num, Stream{Dict: dict, Data: d.streamData(dict, p.pos)}, nil
+}
+
+// streamData returns the stream bytes starting at start. When /Length
+// does not land on endstream, the data runs up to the next endstream.
+func (d *Document) streamData(dict Dict, start int) []byte {
+	if length, ok := intValue(d.resolveOrNil(dict["Length"])); ok && length >= 0 && start+length <= len(d.data) {
+		p := parser{data: d.data, pos: start + length}
+		if p.keyword("endstream") {
+			return d.data[start : start+length]
+		}
+	}
+	end := bytes.Index(d.data[start:], []byte("endstream"))
+	if end < 0 {
+		return d.data[start:]
+	}
+	data := d.data[start : start+end]
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	return bytes.TrimSuffix(data, []byte("\r"))
+}
+
+// object returns object num, or nil for an object the file does not
+// define, which PDF readers treat as null.
+func (d *Document) object(num int) (Object, error) {
+	if object, ok := d.objects[num]; ok {
+		return object, nil
+	}
+	entry, ok := d.xref[num]
+	if !ok {
+		return nil, nil
+	}
+	if d.loading[num] {
+		return nil, fmt.Errorf("%w: object %d refers to itself", ErrMalformed, num)
+	}
+	d.loading[num] = true
+	defer delete(d.loading, num)
+
+	var object Object
+	if entry.compressed {
+		objects, err := d.objectStream(entry.stream)
+		if err != nil {
+			return nil, err
+		}
+		if entry.index >= len(objects.offsets) {
+			return nil, fmt.Errorf("%w: object %d is missing from its object stream", ErrMalformed, num)
+		}
+		p := parser{data: objects.data, pos: objects.first + objects.offsets[entry.index]}
+		if object, err = p.object(0); err != nil {
+			return nil, err
+		}
+	} else {
+		found, parsed, err := d.readIndirect(entry.offset)
+		if err != nil {
+			return nil, err
+		}
+		if found != num {
+			return nil, fmt.Errorf("%w: object %d is not at its offset", ErrMalformed, num)
+		}
+		object = parsed
+	}
+	d.objects[num] = object
+	return object, nil
+}
+
+func (d *Document) objectOrNil(num int) Object {
+	object, err := d.object(num)
+	if err != nil {
+		return nil
+	}
+	return object
+}
+
+// resolve follows references until it reaches a direct object.
+func (d *Document) resolve(object Object) (Object, error) {
+	for range maxNesting {
+		ref, ok := object.(Ref)
+		if !ok {
+			return object, nil
+		}
+		var err error
+		if object, err = d.object(ref.Num); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%w: reference chain too long", ErrMalformed)
+}
+
+func (d *Document) resolveOrNil(object Object) Object {
+	resolved, err := d.resolve(object)
+	if err != nil {
+		return nil
+	}
+	return resolved
+}
+
+func (d *Document) objectStream(num int) (*objectStream, error) {
+	if objects, ok := d.objectStreams[num]; ok {
+		return objects, nil
+	}
+	object, err := d.object(num)
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := object.(Stream)
+	if !ok {
+		return nil, fmt.Errorf("%w: object %d is not an object stream", ErrMalformed, num)
+	}
+	data, err := d.decodeStream(stream)
+	if err != nil {
+		return nil, err
+	}
+	count, okCount := intValue(d.resolveOrNil(stream.Dict["N"]))
+	first, okFirst := intValue(d.resolveOrNil(stream.Dict["First"]))
+	if !okCount || !okFirst || first > len(data) {
+		return nil, fmt.Errorf("%w: bad object stream %d", ErrMalformed, num)
+	}
+	objects := &objectStream{data: data, first: first}
+	p := parser{data: data}
+	for range count {
+		contained, okNum := p.integer()
+		offset, okOffset := p.integer()
+		if !okNum || !okOffset {
+			return nil, fmt.Errorf("%w: bad object stream %d", ErrMalformed, num)
+		}
+		objects.nums = append(objects.nums, contained)
+		objects.offsets = append(objects.offsets, offset)
+	}
+	d.objectStreams[num] = objects
+	return objects, nil
+}
+
+// decodeStream applies the stream filters. Only FlateDecode, the filter
+// of cross-reference and object streams, is supported; other streams are
+// copied encoded and never need decoding.
+func (d *Document) decodeStream(stream Stream) ([]byte, error) {
+	filters := d.resolveOrNil(stream.Dict["Filter"])
+	params := d.resolveOrNil(stream.Dict["DecodeParms"])
+	if name, ok := filters.(Name); ok {
+		filters, params = Array{name}, Array{params}
+	}
+	list, _ := filters.(Array)
+	paramList, _ := params.(Array)
+
+	data := stream.Data
+	for i, filter := range list {
+		if filter != Name("FlateDecode") && filter != Name("Fl") {
+			return nil, fmt.Errorf("%w: unsupported filter %v", ErrMalformed, filter)
+		}
+		reader, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		decoded, err := io.ReadAll(io.LimitReader(reader, maxDecodedStream+1))
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		if len(decoded) > maxDecodedStream {
+			return nil, fmt.Errorf("%w: stream too large", ErrMalformed)
+		}
+		var param Dict
+		if i < len(paramList) {
+			param, _ = d.resolveOrNil(paramList[i]).(Dict)
+		}
+		if data, err = unpredict(decoded, param); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// unpredict reverses the PNG predictors cross-reference streams use.
+func unpredict(data []byte, params Dict) ([]byte, error) {
+	predictor := intOr(params["Predictor"], 1)
+	if predictor == 1 {
+		return data, nil
+	}
+	if predictor < 10 {
+		return nil, fmt.Errorf("%w: unsupported predictor %d", ErrMalformed, predictor)
+	}
+	colors := intOr(params["Colors"], 1)
+	bits := intOr(params["BitsPerComponent"], 8)
+	columns := intOr(params["Columns"], 1)
+	bytesPerPixel := max(1, colors*bits/8)
+	rowLength := (colors*bits*columns + 7) / 8
+	if rowLength <= 0 {
+		return nil, fmt.Errorf("%w: bad predictor parameters", ErrMalformed)
+	}
+
+	out := make([]byte, 0, len(data))
+	previous := make([]byte, rowLength)
+	for len(data) >= rowLength+1 {
+		filter := data[0]
+		row := append([]byte(nil), data[1:rowLength+1]...)
+		data = data[rowLength+1:]
+		for i := range row {
+			var left, upLeft byte
+			if i >= bytesPerPixel {
+				left, upLeft = row[i-bytesPerPixel], previous[i-bytesPerPixel]
+			}
+			up := previous[i]
+			switch filter {
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			}
+		}
+		out = append(out, row...)
+		previous = row
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	default:
+		return c
+	}
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+func intValue(object Object) (int, bool) {
+	switch value := object.(type) {
+	case int:
+		return value, true
+	case Number:
+		parsed, err := strconv.Atoi(string(value))
+		return parsed, err == nil
+	}
+	return 0, false
+}
+
+func intOr(object Object, fallback int) int {
+	if value, ok := intValue(object); ok {
+		return value
+	}
+	return fallback
+}
+
+// loadPages walks the page tree in order.
+func (d *Document) loadPages() ([]page, error) {
+	root, err := d.resolve(d.trailer["Root"])
+	if err != nil {
+		return nil, err
+	}
+	catalog, ok := root.(Dict)
+	if !ok {
+		return nil, fmt.Errorf("%w: no document catalog", ErrMalformed)
+	}
+
+	var pages []page
+	visited := map[int]bool{}
+	var walk func(node Object, inherited Dict, depth int) error
+	walk = func(node Object, inherited Dict, depth int) error {
+		if depth > maxNesting {
+			return fmt.Errorf("%w: page tree too deep", ErrMalformed)
+		}
+		ref, isRef := node.(Ref)
+		if isRef {
+			if visited[ref.Num] {
+				return fmt.Errorf("%w: page %d appears twice in the page tree", ErrMalformed, ref.Num)
+			}
+			visited[ref.Num] = true
+		}
+		object, err := d.resolve(node)
+		if err != nil {
+			return err
+		}
+		dict, ok := object.(Dict)
+		if !ok {
+			return fmt.Errorf("%w: page tree node is not a dictionary", ErrMalformed)
+		}
+
+		kids, hasKids := d.resolveOrNil(dict["Kids"]).(Array)
+		if dict["Type"] == Name("Pages") || (dict["Type"] != Name("Page") && hasKids) {
+			next := maps.Clone(inherited)
+			for _, key := range inheritableAttributes {
+				if value, ok := dict[key]; ok {
+					next[key] = value
+				}
+			}
+			for _, kid := range kids {
+				if err := walk(kid, next, depth+1); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if !isRef {
+			return fmt.Errorf("%w: page is not an indirect object", ErrMalformed)
+		}
+		pageDict := maps.Clone(dict)
+		for key, value := range inherited {
+			if _, ok := pageDict[key]; !ok {
+				pageDict[key] = value
+			}
+		}
+		pages = append(pages, page{ref: ref, dict: pageDict})
+		return nil
+	}
+	if err := walk(catalog["Pages"], Dict{}, 0); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
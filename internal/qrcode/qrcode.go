@@ -0,0 +1,455 @@
+// Package qrcode encodes short texts, such as verification URLs, as QR
+// Code symbols (ISO/IEC 18004) in byte mode with error correction level M,
+// which still reads after about 15% of the symbol is damaged.
+package qrcode
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrTooLong is returned for texts that do not fit the largest supported
+// symbol.
+var ErrTooLong = errors.New("qrcode: text too long")
+
+// Code is a square grid of modules. Callers draw it with a quiet zone of
+// four light modules around it.
+type Code struct {
+	size    int
+	modules []bool
+}
+
+// Size is the number of modules on each side.
+func (c *Code) Size() int {
+	return c.size
+}
+
+// Dark reports whether the module at column x and row y is dark.
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y*c.size+x]
+}
+
+// blockLayout is the error correction structure of a version at level M:
+// blocks blocks of dataPerBlock data codewords followed by longBlocks
+// blocks with one more, each protected by ecPerBlock error correction
+// codewords.
+type blockLayout struct {
+	blocks       int
+	longBlocks   int
+	dataPerBlock int
+	ecPerBlock   int
+}
+
+// layouts lists versions 1 to 10, up to 213 bytes of text, which is more
+// than any verification URL needs.
+var layouts = []blockLayout{
+	{1, 0, 16, 10},
+	{1, 0, 28, 16},
+	{1, 0, 44, 26},
+	{2, 0, 32, 18},
+	{2, 0, 43, 24},
+	{4, 0, 27, 16},
+	{4, 0, 31, 18},
+	{2, 2, 38, 22},
+	{3, 2, 36, 22},
+	{4, 1, 43, 26},
+}
+
+// alignmentCenters are the row and column coordinates of the alignment
+// patterns of each version.
+var alignmentCenters = [][]int{
+	nil,
+	{6, 18},
+	{6, 22},
+	{6, 26},
+	{6, 30},
+	{6, 34},
+	{6, 22, 38},
+	{6, 24, 42},
+	{6, 26, 46},
+	{6, 28, 50},
+}
+
+func (l blockLayout) dataCodewords() int {
+	return (l.blocks+l.longBlocks)*l.dataPerBlock + l.longBlocks
+}
+
+// Encode returns the smallest symbol holding text.
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+	for version := 1; version <= len(layouts); version++ {
+		layout := layouts[version-1]
+		countBits := 8
+		if version >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(data) > 8*layout.dataCodewords() {
+			continue
+		}
+		codewords := interleave(layout, dataCodewords(data, countBits, layout.dataCodewords()))
+		return newSymbol(version, codewords), nil
+	}
+	return nil, ErrTooLong
+}
+
+// dataCodewords lays text out as a byte mode segment followed by the
+// terminator and the alternating pad codewords.
+func dataCodewords(data []byte, countBits, capacity int) []byte {
+	var bits bitBuffer
+	bits.append(0b0100, 4)
+	bits.append(len(data), countBits)
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	bits.append(0, min(4, 8*capacity-bits.len()))
+	bits.append(0, (8-bits.len()%8)%8)
+
+	codewords := bits.bytes()
+	for pad := 0; len(codewords) < capacity; pad++ {
+		codewords = append(codewords, [2]byte{0xec, 0x11}[pad%2])
+	}
+	return codewords
+}
+
+// interleave splits the data into blocks, appends their error correction
+// codewords and interleaves both, as the symbol stores them.
+func interleave(layout blockLayout, data []byte) []byte {
+	total := layout.blocks + layout.longBlocks
+	blocks := make([][]byte, total)
+	ecBlocks := make([][]byte, total)
+	divisor := reedSolomonDivisor(layout.ecPerBlock)
+	for i, at := 0, 0; i < total; i++ {
+		size := layout.dataPerBlock
+		if i >= layout.blocks {
+			size++
+		}
+		blocks[i] = data[at : at+size]
+		ecBlocks[i] = reedSolomonRemainder(blocks[i], divisor)
+		at += size
+	}
+
+	var out []byte
+	for i := 0; i <= layout.dataPerBlock; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				out = append(out, block[i])
+			}
+		}
+	}
+	for i := 0; i < layout.ecPerBlock; i++ {
+		for _, block := range ecBlocks {
+			out = append(out, block[i])
+		}
+	}
+	return out
+}
+
+// symbol is a symbol under construction; function marks the modules of
+// finder, timing, alignment and format patterns, which hold no data.
+type symbol struct {
+	size     int
+	modules  []bool
+	function []bool
+}
+
+func newSymbol(version int, codewords []byte) *Code {
+	size := 17 + 4*version
+	s := &symbol{size: size, modules: make([]bool, size*size), function: make([]bool, size*size)}
+	s.drawFunctionPatterns(version)
+	s.drawCodewords(codewords)
+
+	best, bestPenalty := 0, math.MaxInt
+	for mask := range 8 {
+		s.applyMask(mask)
+		s.drawFormatBits(mask)
+		if penalty := s.penalty(); penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		s.applyMask(mask)
+	}
+	s.applyMask(best)
+	s.drawFormatBits(best)
+	return &Code{size: size, modules: s.modules}
+}
+
+func (s *symbol) set(x, y int, dark bool) {
+	s.modules[y*s.size+x] = dark
+	s.function[y*s.size+x] = true
+}
+
+func (s *symbol) drawFunctionPatterns(version int) {
+	for i := 0; i < s.size; i++ {
+		s.set(6, i, i%2 == 0)
+		s.set(i, 6, i%2 == 0)
+	}
+
+	s.drawFinder(3, 3)
+	s.drawFinder(s.size-4, 3)
+	s.drawFinder(3, s.size-4)
+
+	centers := alignmentCenters[version-1]
+	for i, x := range centers {
+		for j, y := range centers {
+			// The corners taken by finder patterns get none.
+			if (i == 0 && j == 0) || (i == 0 && j == len(centers)-1) || (i == len(centers)-1 && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					s.set(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve the format areas; drawFormatBits fills them per mask.
+	s.drawFormatBits(0)
+
+	if version >= 7 {
+		remainder := version
+		for range 12 {
+			remainder = remainder<<1 ^ (remainder>>11)*0x1f25
+		}
+		bits := version<<12 | remainder
+		for i := range 18 {
+			dark := bits>>i&1 == 1
+			a, b := s.size-11+i%3, i/3
+			s.set(a, b, dark)
+			s.set(b, a, dark)
+		}
+	}
+}
+
+// drawFinder draws a finder pattern and its separator around center x, y.
+func (s *symbol) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			if x+dx < 0 || x+dx >= s.size || y+dy < 0 || y+dy >= s.size {
+				continue
+			}
+			distance := max(abs(dx), abs(dy))
+			s.set(x+dx, y+dy, distance != 2 && distance != 4)
+		}
+	}
+}
+
+// drawFormatBits writes both copies of the error correction level and
+// mask, protected by their BCH code.
+func (s *symbol) drawFormatBits(mask int) {
+	const levelM = 0b00
+	data := levelM<<3 | mask
+	remainder := data
+	for range 10 {
+		remainder = remainder<<1 ^ (remainder>>9)*0x537
+	}
+	bits := (data<<10 | remainder) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		s.set(8, i, bit(i))
+	}
+	s.set(8, 7, bit(6))
+	s.set(8, 8, bit(7))
+	s.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		s.set(14-i, 8, bit(i))
+	}
+
+	for i := range 8 {
+		s.set(s.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		s.set(8, s.size-15+i, bit(i))
+	}
+	s.set(8, s.size-8, true)
+}
+
+// drawCodewords fills the data modules in the zigzag order of the
+// standard: pairs of columns from the right, alternately upwards and
+// downwards, skipping the vertical timing pattern.
+func (s *symbol) drawCodewords(codewords []byte) {
+	i := 0
+	for right := s.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vertical := 0; vertical < s.size; vertical++ {
+			for j := range 2 {
+				x := right - j
+				y := vertical
+				if (right+1)&2 == 0 {
+					y = s.size - 1 - vertical
+				}
+				if s.function[y*s.size+x] || i >= 8*len(codewords) {
+					continue
+				}
+				s.modules[y*s.size+x] = codewords[i/8]>>(7-i%8)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+// applyMask flips the data modules selected by mask; applying it twice
+// restores them.
+func (s *symbol) applyMask(mask int) {
+	for y := 0; y < s.size; y++ {
+		for x := 0; x < s.size; x++ {
+			if s.function[y*s.size+x] {
+				continue
+			}
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip {
+				s.modules[y*s.size+x] = !s.modules[y*s.size+x]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the symbol is to scan, following the four
+// rules of the standard: long runs, 2x2 blocks, finder-like sequences and
+// an unbalanced share of dark modules.
+func (s *symbol) penalty() int {
+	dark := func(x, y int) bool { return s.modules[y*s.size+x] }
+	penalty := 0
+	for _, transposed := range []bool{false, true} {
+		at := func(i, j int) bool {
+			if transposed {
+				return dark(i, j)
+			}
+			return dark(j, i)
+		}
+		for i := 0; i < s.size; i++ {
+			run := 1
+			var line uint
+			for j := 0; j < s.size; j++ {
+				if j > 0 && at(i, j) == at(i, j-1) {
+					run++
+				} else {
+					if run >= 5 {
+						penalty += run - 2
+					}
+					run = 1
+				}
+				line = line<<1&0x7ff | boolBit(at(i, j))
+				if j >= 10 && (line == 0b10111010000 || line == 0b00001011101) {
+					penalty += 40
+				}
+			}
+			if run >= 5 {
+				penalty += run - 2
+			}
+		}
+	}
+
+	darkCount := 0
+	for y := 0; y < s.size; y++ {
+		for x := 0; x < s.size; x++ {
+			if dark(x, y) {
+				darkCount++
+			}
+			if x > 0 && y > 0 && dark(x, y) == dark(x-1, y) && dark(x, y) == dark(x, y-1) && dark(x, y) == dark(x-1, y-1) {
+				penalty += 3
+			}
+		}
+	}
+	percent := darkCount * 100 / (s.size * s.size)
+	return penalty + 10*(abs(percent-50)/5)
+}
+
+// reedSolomonDivisor returns the generator polynomial of degree n, without
+// its leading coefficient, over GF(2^8) with the QR Code modulus 0x11d.
+func reedSolomonDivisor(n int) []byte {
+	divisor := make([]byte, n)
+	divisor[n-1] = 1
+	root := byte(1)
+	for range n {
+		for j := range divisor {
+			divisor[j] = gfMultiply(divisor[j], root)
+			if j+1 < n {
+				divisor[j] ^= divisor[j+1]
+			}
+		}
+		root = gfMultiply(root, 2)
+	}
+	return divisor
+}
+
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	remainder := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ remainder[0]
+		copy(remainder, remainder[1:])
+		remainder[len(remainder)-1] = 0
+		for i, coefficient := range divisor {
+			remainder[i] ^= gfMultiply(coefficient, factor)
+		}
+	}
+	return remainder
+}
+
+func gfMultiply(x, y byte) byte {
+	var product byte
+	for i := 7; i >= 0; i-- {
+		carry := product >> 7
+		product = product<<1 ^ carry*0x1d
+		product ^= (y >> i & 1) * x
+	}
+	return product
+}
+
+type bitBuffer struct {
+	bits []bool
+}
+
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		b.bits = append(b.bits, value>>i&1 == 1)
+	}
+}
+
+func (b *bitBuffer) len() int {
+	return len(b.bits)
+}
+
+func (b *bitBuffer) bytes() []byte {
+	out := make([]byte, len(b.bits)/8)
+	for i, bit := range b.bits {
+		if bit {
+			out[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return out
+}
+
+func boolBit(b bool) uint {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
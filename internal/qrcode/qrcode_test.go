@@ -0,0 +1,169 @@
+package qrcode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// decode reads a symbol back the way a scanner would once it has located
+// the modules: format bits, unmasking, codeword order, error correction
+// check and the byte mode segment.
+func decode(t *testing.T, code *Code) string {
+	t.Helper()
+	version := (code.Size() - 17) / 4
+	layout := layouts[version-1]
+
+	format := 0
+	for i := 14; i >= 9; i-- {
+		format = format<<1 | int(boolBit(code.Dark(14-i, 8)))
+	}
+	format = format<<1 | int(boolBit(code.Dark(7, 8)))
+	format = format<<1 | int(boolBit(code.Dark(8, 8)))
+	format = format<<1 | int(boolBit(code.Dark(8, 7)))
+	for i := 5; i >= 0; i-- {
+		format = format<<1 | int(boolBit(code.Dark(8, i)))
+	}
+	format ^= 0x5412
+	if level := format >> 13; level != 0 {
+		t.Fatalf("expected error correction level M, got %02b", level)
+	}
+	mask := format >> 10 & 7
+
+	// Rebuild the function pattern map and read the data modules through it.
+	s := &symbol{size: code.Size(), modules: append([]bool(nil), code.modules...), function: make([]bool, len(code.modules))}
+	reference := &symbol{size: code.Size(), modules: make([]bool, len(code.modules)), function: s.function}
+	reference.drawFunctionPatterns(version)
+	for i, function := range s.function {
+		if function && code.modules[i] != reference.modules[i] && !isFormatModule(s.size, i) {
+			t.Fatalf("function pattern module %d differs from the standard layout", i)
+		}
+	}
+	s.applyMask(mask)
+
+	codewords := make([]byte, layout.dataCodewords()+(layout.blocks+layout.longBlocks)*layout.ecPerBlock)
+	i := 0
+	for right := s.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vertical := 0; vertical < s.size; vertical++ {
+			for j := range 2 {
+				x, y := right-j, vertical
+				if (right+1)&2 == 0 {
+					y = s.size - 1 - vertical
+				}
+				if s.function[y*s.size+x] || i >= 8*len(codewords) {
+					continue
+				}
+				if s.modules[y*s.size+x] {
+					codewords[i/8] |= 1 << (7 - i%8)
+				}
+				i++
+			}
+		}
+	}
+
+	// De-interleave and check every block with its syndromes.
+	total := layout.blocks + layout.longBlocks
+	blocks := make([][]byte, total)
+	at := 0
+	for n := 0; n <= layout.dataPerBlock; n++ {
+		for b := range total {
+			if n < layout.dataPerBlock || b >= layout.blocks {
+				blocks[b] = append(blocks[b], codewords[at])
+				at++
+			}
+		}
+	}
+	for n := 0; n < layout.ecPerBlock; n++ {
+		for b := range total {
+			blocks[b] = append(blocks[b], codewords[at])
+			at++
+		}
+	}
+	var data []byte
+	for b, block := range blocks {
+		root := byte(1)
+		for range layout.ecPerBlock {
+			var syndrome byte
+			for _, c := range block {
+				syndrome = gfMultiply(syndrome, root) ^ c
+			}
+			if syndrome != 0 {
+				t.Fatalf("block %d has a non-zero syndrome", b)
+			}
+			root = gfMultiply(root, 2)
+		}
+		data = append(data, block[:len(block)-layout.ecPerBlock]...)
+	}
+
+	if data[0]>>4 != 0b0100 {
+		t.Fatalf("expected a byte mode segment, got mode %04b", data[0]>>4)
+	}
+	bitAt := func(n int) int { return int(data[n/8] >> (7 - n%8) & 1) }
+	read := func(from, n int) int {
+		value := 0
+		for k := range n {
+			value = value<<1 | bitAt(from+k)
+		}
+		return value
+	}
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	length := read(4, countBits)
+	text := make([]byte, length)
+	for k := range text {
+		text[k] = byte(read(4+countBits+8*k, 8))
+	}
+	return string(text)
+}
+
+func isFormatModule(size, i int) bool {
+	x, y := i%size, i/size
+	return (y == 8 && (x <= 8 || x >= size-8)) || (x == 8 && (y <= 8 || y >= size-8))
+}
+
+func TestEncodeRoundTrips(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"https://pdf.example.com/verify/7KQ2M9XD4HPA",
+		"Código: 7KQ2-M9XD-4HPA",
+		strings.Repeat("a", 120),
+		strings.Repeat("z", 213),
+	} {
+		code, err := Encode(text)
+		if err != nil {
+			t.Fatalf("Encode(%d bytes) error = %v", len(text), err)
+		}
+		if got := decode(t, code); got != text {
+			t.Fatalf("expected %q back, got %q", text, got)
+		}
+	}
+}
+
+func TestEncodePicksTheSmallestVersion(t *testing.T) {
+	for _, tc := range []struct {
+		length int
+		size   int
+	}{
+		{14, 21},
+		{15, 25},
+		{62, 33},
+		{213, 57},
+	} {
+		code, err := Encode(strings.Repeat("x", tc.length))
+		if err != nil {
+			t.Fatalf("Encode(%d bytes) error = %v", tc.length, err)
+		}
+		if code.Size() != tc.size {
+			t.Fatalf("expected %d bytes to need a %d module symbol, got %d", tc.length, tc.size, code.Size())
+		}
+	}
+
+	if _, err := Encode(strings.Repeat("x", 214)); !errors.Is(err, ErrTooLong) {
+		t.Fatalf("expected ErrTooLong, got %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"pdf-service/internal/domain"
+)
+
+// DefaultBrandingProfileID identifies the built-in Encontre Aqui profile.
+const DefaultBrandingProfileID = "encontre"
+
+// RGB is a color used by the renderers, in 0-255 components.
+type RGB struct {
+	R, G, B int
+}
+
+// BrandingProfile is the institutional identity printed on documents: the
+// agency that addresses and signs proposals and whose footer closes them.
+type BrandingProfile struct {
+	ID          string
+	LegalName   string
+	DisplayName string
+	Role        string
+	CNPJ        string
+	CRECI       string
+	Address     string
+	Phone       string
+	Instagram   string
+	Email       string
+	Logo        []byte
+	// PrimaryColor tints document titles; AccentColor the footer rule and
+	// table header fills.
+	PrimaryColor RGB
+	AccentColor  RGB
+}
+
+// brandingProfileFile is the configuration file shape for one profile.
+// Logos are loaded from logo_path or inline as logo_base64.
+type brandingProfileFile struct {
+	ID           string `json:"id"`
+	LegalName    string `json:"legal_name"`
+	DisplayName  string `json:"display_name"`
+	Role         string `json:"role"`
+	CNPJ         string `json:"cnpj"`
+	CRECI        string `json:"creci"`
+	Address      string `json:"address"`
+	Phone        string `json:"phone"`
+	Instagram    string `json:"instagram"`
+	Email        string `json:"email"`
+	LogoPath     string `json:"logo_path"`
+	LogoBase64   string `json:"logo_base64"`
+	PrimaryColor string `json:"primary_color"`
+	AccentColor  string `json:"accent_color"`
+}
+
+func defaultBrandingProfile() BrandingProfile {
+	return BrandingProfile{
+		ID:           DefaultBrandingProfileID,
+		LegalName:    "Encontre Aqui Imóveis Ltda",
+		DisplayName:  "Encontre Aqui Imóveis",
+		Role:         "Imobiliária",
+		Address:      "Rua Abel Pereira de Castro, 838, Centro, Rio Verde – GO | CEP: 75.901-060",
+		Phone:        "64 3050-0118",
+		Instagram:    "@encontre.aquiimoveis",
+		Logo:         encontreLogoPNG,
+		PrimaryColor: RGB{0, 0, 0},
+		AccentColor:  RGB{220, 220, 220},
+	}
+}
+
+// LoadBrandingProfiles reads a JSON array of profiles from path. Missing
+// optional fields fall back to the built-in profile's values.
+func LoadBrandingProfiles(path string) ([]BrandingProfile, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path comes from operator configuration
+	if err != nil {
+		return nil, fmt.Errorf("read branding profiles: %w", err)
+	}
+
+	var files []brandingProfileFile
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, fmt.Errorf("parse branding profiles: %w", err)
+	}
+
+	profiles := make([]BrandingProfile, 0, len(files))
+	for i, file := range files {
+		profile, err := file.toProfile()
+		if err != nil {
+			return nil, fmt.Errorf("branding profile %d: %w", i, err)
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+func (f brandingProfileFile) toProfile() (BrandingProfile, error) {
+	defaults := defaultBrandingProfile()
+	profile := BrandingProfile{
+		ID:           strings.TrimSpace(f.ID),
+		LegalName:    strings.TrimSpace(f.LegalName),
+		DisplayName:  fallback(f.DisplayName, strings.TrimSpace(f.LegalName)),
+		Role:         fallback(f.Role, defaults.Role),
+		CNPJ:         strings.TrimSpace(f.CNPJ),
+		CRECI:        strings.TrimSpace(f.CRECI),
+		Address:      strings.TrimSpace(f.Address),
+		Phone:        strings.TrimSpace(f.Phone),
+		Instagram:    strings.TrimSpace(f.Instagram),
+		Email:        strings.TrimSpace(f.Email),
+		PrimaryColor: defaults.PrimaryColor,
+		AccentColor:  defaults.AccentColor,
+	}
+	if profile.ID == "" {
+		return BrandingProfile{}, errors.New("id is required")
+	}
+	if profile.LegalName == "" {
+		return BrandingProfile{}, errors.New("legal_name is required")
+	}
+	if profile.CNPJ != "" {
+		parsed, ok := domain.ParseDocumentNumber(profile.CNPJ)
+		if !ok || parsed.Kind != domain.DocumentKindCNPJ {
+			return BrandingProfile{}, errors.New("cnpj must be a valid CNPJ")
+		}
+		profile.CNPJ = parsed.String()
+	}
+
+	switch {
+	case strings.TrimSpace(f.LogoPath) != "":
+		logo, err := os.ReadFile(strings.TrimSpace(f.LogoPath))
+		if err != nil {
+			return BrandingProfile{}, fmt.Errorf("read logo: %w", err)
+		}
+		profile.Logo = logo
+	case strings.TrimSpace(f.LogoBase64) != "":
+		logo, err := base64.StdEncoding.DecodeString(strings.TrimSpace(f.LogoBase64))
+		if err != nil {
+			return BrandingProfile{}, fmt.Errorf("decode logo_base64: %w", err)
+		}
+		profile.Logo = logo
+	}
+	if len(profile.Logo) > 0 && logoImageType(profile.Logo) == "" {
+		return BrandingProfile{}, errors.New("logo must be a PNG or JPEG image")
+	}
+
+	for _, color := range []struct {
+		value  string
+		target *RGB
+	}{
+		{f.PrimaryColor, &profile.PrimaryColor},
+		{f.AccentColor, &profile.AccentColor},
+	} {
+		if strings.TrimSpace(color.value) == "" {
+			continue
+		}
+		parsed, err := parseHexColor(color.value)
+		if err != nil {
+			return BrandingProfile{}, err
+		}
+		*color.target = parsed
+	}
+	return profile, nil
+}
+
+func parseHexColor(value string) (RGB, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(value), "#")
+	if len(hex) != 6 {
+		return RGB{}, fmt.Errorf("color %q must use the #RRGGBB form", value)
+	}
+	parsed, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return RGB{}, fmt.Errorf("color %q must use the #RRGGBB form", value)
+	}
+	return RGB{R: int(parsed >> 16 & 0xFF), G: int(parsed >> 8 & 0xFF), B: int(parsed & 0xFF)}, nil
+}
+
+func logoImageType(logo []byte) string {
+	switch http.DetectContentType(logo) {
+	case "image/png":
+		return "PNG"
+	case "image/jpeg":
+		return "JPG"
+	default:
+		return ""
+	}
+}
+
+// brandingProfile resolves the profile requested by ID, using the service
+// default when the request does not pick one.
+func (s *PDFService) brandingProfile(id string) (BrandingProfile, error) {
+	if strings.TrimSpace(id) == "" {
+		id = s.defaultBrandingID
+	}
+	profile, ok := s.brandingProfiles[id]
+	if !ok {
+		return BrandingProfile{}, domain.UnknownBrandingProfileError()
+	}
+	return profile, nil
+}
+
+// HasBrandingProfile reports whether id names a configured profile.
+func (s *PDFService) HasBrandingProfile(id string) bool {
+	_, ok := s.brandingProfiles[id]
+	return ok
+}
+
+// footerLines returns the contact lines printed under the footer logo.
+func (b BrandingProfile) footerLines() []string {
+	var registration []string
+	if b.CNPJ != "" {
+		registration = append(registration, "CNPJ: "+b.CNPJ)
+	}
+	if b.CRECI != "" {
+		registration = append(registration, "CRECI: "+b.CRECI)
+	}
+
+	var contacts []string
+	if b.Phone != "" {
+		contacts = append(contacts, b.Phone)
+	}
+	if b.Email != "" {
+		contacts = append(contacts, b.Email)
+	}
+	if b.Instagram != "" {
+		contacts = append(contacts, "Instagram: "+b.Instagram)
+	}
+
+	var lines []string
+	for _, line := range []string{
+		strings.Join(registration, " | "),
+		b.Address,
+		strings.Join(contacts, " | "),
+	} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func (b BrandingProfile) logoImageName() string {
+	return "brand_logo_" + b.ID
+}
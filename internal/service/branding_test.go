@@ -0,0 +1,99 @@
+package service
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pdf-service/internal/domain"
+)
+
+func writeBrandingProfilesFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "branding.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write branding file: %v", err)
+	}
+	return path
+}
+
+func TestLoadBrandingProfilesParsesPartnerProfile(t *testing.T) {
+	path := writeBrandingProfilesFile(t, `[{
+		"id":"parceira",
+		"legal_name":"Parceira Negócios Imobiliários Ltda",
+		"display_name":"Parceira Imóveis",
+		"cnpj":"11222333000181",
+		"creci":"J-1234",
+		"address":"Av. Central, 100, Goiânia – GO",
+		"phone":"62 3000-0000",
+		"logo_base64":"`+base64.StdEncoding.EncodeToString(encontreLogoPNG)+`",
+		"primary_color":"#1A2B3C"
+	}]`)
+
+	profiles, err := LoadBrandingProfiles(path)
+	if err != nil {
+		t.Fatalf("LoadBrandingProfiles() error = %v", err)
+	}
+	if len(profiles) != 1 {
+		t.Fatalf("expected one profile, got %d", len(profiles))
+	}
+	profile := profiles[0]
+	if profile.CNPJ != "11.222.333/0001-81" || profile.Role != "Imobiliária" {
+		t.Fatalf("unexpected normalized profile %+v", profile)
+	}
+	if profile.PrimaryColor != (RGB{0x1A, 0x2B, 0x3C}) {
+		t.Fatalf("unexpected primary color %+v", profile.PrimaryColor)
+	}
+	if got := strings.Join(profile.footerLines(), "\n"); !strings.Contains(got, "CNPJ: 11.222.333/0001-81 | CRECI: J-1234") {
+		t.Fatalf("expected registration footer line, got %q", got)
+	}
+}
+
+func TestLoadBrandingProfilesRejectsInvalidLogo(t *testing.T) {
+	path := writeBrandingProfilesFile(t, `[{"id":"x","legal_name":"X Ltda","logo_base64":"`+base64.StdEncoding.EncodeToString([]byte("not an image"))+`"}]`)
+
+	if _, err := LoadBrandingProfiles(path); err == nil {
+		t.Fatal("expected non-image logo to be rejected")
+	}
+}
+
+func TestGenerateProposalUsesRequestedBrandingProfile(t *testing.T) {
+	svc := NewPDFService(WithBrandingProfiles(BrandingProfile{
+		ID:          "parceira",
+		LegalName:   "Parceira Negocios Ltda",
+		DisplayName: "Parceira Imoveis",
+		Role:        "Imobiliaria parceira",
+		Address:     "Av. Central, 100",
+	}))
+
+	doc, err := svc.GenerateProposal(domain.ProposalRequest{
+		ClientName:            "Ana Silva",
+		PropertyAddressLegacy: "Rua A, 10, Centro, Goiânia, GO",
+		TotalValue:            100,
+		Payment:               domain.PaymentBreakdown{Cash: 100},
+		BrandingProfileID:     "parceira",
+	})
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	text := extractPDFText(doc.PDF)
+	if !strings.Contains(text, "PARCEIRA NEGOCIOS LTDA") || !strings.Contains(text, "Av. Central, 100") {
+		t.Fatal("expected partner branding in the proposal")
+	}
+	if strings.Contains(text, "Abel Pereira de Castro") {
+		t.Fatal("expected default footer address to be replaced")
+	}
+}
+
+func TestGenerateProposalRejectsUnknownBrandingProfile(t *testing.T) {
+	_, err := NewPDFService().GenerateProposal(domain.ProposalRequest{
+		ClientName:            "Ana Silva",
+		PropertyAddressLegacy: "Rua A, 10",
+		TotalValue:            100,
+		Payment:               domain.PaymentBreakdown{Cash: 100},
+		BrandingProfileID:     "missing",
+	})
+	requireFieldError(t, err, "/branding_profile_id", domain.CodeInvalidChoice)
+}
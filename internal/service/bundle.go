@@ -0,0 +1,263 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"pdf-service/internal/domain"
+	"pdf-service/internal/pdfmerge"
+)
+
+// bundleCoverEntryHeight is the line height of the table of contents.
+// With at most 20 parts the cover always fits on one page, so the parts
+// start on known pages before the cover is drawn.
+const bundleCoverEntryHeight = 7.0
+
+// GenerateBundle renders the inline documents of the request, in order
+// with the uploaded files, behind a cover page listing every part with
+// the page it starts on, and bookmarks each part. Inline requests without
+// a branding profile or issue date take the bundle's. The parts carry no
+// signature or verification code of their own; sign and the verification
+// code apply to the bundle as a whole.
+func (s *PDFService) GenerateBundle(req domain.BundleRequest) (domain.GeneratedDocument, error) {
+	if err := req.Validate(); err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	if err := s.checkSigning(req.Sign); err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	brand, err := s.brandingProfile(req.BrandingProfileID)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	created := s.documentDate(req.IssueDate)
+
+	// Only the bundle is handed out, so only the bundle gets a verification
+	// code and record; its parts are rendered without them.
+	unregistered := *s
+	unregistered.registry = nil
+	parts := make([]pdfmerge.Part, 0, len(req.Parts)+1)
+	contractID := ""
+	for i, part := range req.Parts {
+		doc, err := unregistered.bundlePart(req, part, created)
+		if err != nil {
+			return domain.GeneratedDocument{}, bundlePartError(i, part, err)
+		}
+		parts = append(parts, pdfmerge.Part{Title: part.ResolvedTitle(), Document: doc})
+		if part.Contract != nil && contractID == "" {
+			contractID = part.Contract.ContractID
+		}
+	}
+
+	key, err := renderCacheKey("bundle", req, brand, bundleCoverRenderer)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	verificationID, withVerification, err := s.verificationOption(key, created, req.Sign)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	cover, err := writeBundleCover(req, brand, parts, created, withVerification)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	merged, err := pdfmerge.Merge(append([]pdfmerge.Part{{Title: "Sumário", Document: cover}}, parts...), pdfmerge.Info{
+		Title:    req.ResolvedTitle(),
+		Producer: "pdf-service",
+		Created:  created,
+	})
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+
+	out, err := s.finishDocument(merged, req.Sign)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	return s.registerDocument(domain.GeneratedDocument{PDF: out, Template: bundleCoverRenderer}, verificationID, "bundle", contractID)
+}
+
+// bundlePart renders or reads one part.
+func (s *PDFService) bundlePart(req domain.BundleRequest, part domain.BundlePart, created time.Time) (*pdfmerge.Document, error) {
+	var doc domain.GeneratedDocument
+	var err error
+	switch {
+	case part.Proposal != nil:
+		inline := *part.Proposal
+		inheritBundleDefaults(&inline.BrandingProfileID, &inline.IssueDate, req)
+		doc, err = s.GenerateProposal(inline)
+	case part.Contract != nil:
+		inline := *part.Contract
+		inheritBundleDefaults(&inline.BrandingProfileID, &inline.IssueDate, req)
+		doc, err = s.GenerateContract(inline)
+	case part.Receipt != nil:
+		inline := *part.Receipt
+		inheritBundleDefaults(&inline.BrandingProfileID, &inline.IssueDate, req)
+		doc, err = s.GenerateReceipt(inline)
+	case part.FinancingSimulation != nil:
+		inline := *part.FinancingSimulation
+		inheritBundleDefaults(&inline.BrandingProfileID, &inline.IssueDate, req)
+		doc, err = s.GenerateFinancingSimulation(inline)
+	default:
+		return openUpload(part, created)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pdfmerge.Open(doc.PDF)
+}
+
+func inheritBundleDefaults(brandingProfileID, issueDate *string, req domain.BundleRequest) {
+	if *brandingProfileID == "" {
+		*brandingProfileID = req.BrandingProfileID
+	}
+	if *issueDate == "" {
+		*issueDate = req.IssueDate
+	}
+}
+
+// errUnreadableUpload marks an uploaded file that could not be read.
+var errUnreadableUpload = errors.New("unreadable upload")
+
+// openUpload reads an uploaded PDF, or lays an uploaded image out on a
+// page of its own.
+func openUpload(part domain.BundlePart, created time.Time) (*pdfmerge.Document, error) {
+	data := part.Data
+	if format := part.UploadFormat(); format != domain.UploadFormatPDF {
+		var err error
+		if data, err = imageDocument(data, format, created); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUnreadableUpload, err)
+		}
+	}
+	doc, err := pdfmerge.Open(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errUnreadableUpload, err)
+	}
+	return doc, nil
+}
+
+// imageDocument places an image on an A4 page turned to match it, scaled
+// to fit within the margins and centred.
+func imageDocument(data []byte, format string, created time.Time) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCatalogSort(true)
+	pdf.SetCreationDate(created)
+	pdf.SetModificationDate(created)
+	pdf.SetCompression(false)
+	pdf.SetAutoPageBreak(false, 0)
+	info := pdf.RegisterImageOptionsReader("upload", gofpdf.ImageOptions{ImageType: format}, bytes.NewReader(data))
+	if err := pdf.Error(); err != nil {
+		return nil, err
+	}
+
+	orientation := "P"
+	if info.Width() > info.Height() {
+		orientation = "L"
+	}
+	pdf.AddPageFormat(orientation, pdf.GetPageSizeStr("A4"))
+	const margin = 20.0
+	pageWidth, pageHeight := pdf.GetPageSize()
+	scale := min((pageWidth-2*margin)/info.Width(), (pageHeight-2*margin)/info.Height())
+	width, height := info.Width()*scale, info.Height()*scale
+	pdf.ImageOptions("upload", (pageWidth-width)/2, (pageHeight-height)/2, width, height, false, gofpdf.ImageOptions{ImageType: format}, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// bundlePartError reports a failed part under its path: problems of an
+// inline request at "/parts/N/request/…", an unreadable upload at
+// "/parts/N/data".
+func bundlePartError(index int, part domain.BundlePart, err error) error {
+	path := "/parts/" + strconv.Itoa(index)
+	var fieldErrs domain.ValidationErrors
+	switch {
+	case errors.As(err, &fieldErrs):
+		prefixed := make(domain.ValidationErrors, len(fieldErrs))
+		for i, fieldErr := range fieldErrs {
+			fieldErr.Path = path + "/request" + fieldErr.Path
+			prefixed[i] = fieldErr
+		}
+		return prefixed
+	case errors.Is(err, pdfmerge.ErrEncrypted):
+		return domain.ValidationErrors{{Path: path + "/data", Code: domain.CodeInvalidFormat, Message: "O PDF está protegido por senha; envie uma cópia sem senha."}}
+	case errors.Is(err, errUnreadableUpload):
+		return domain.ValidationErrors{{Path: path + "/data", Code: domain.CodeInvalidFormat, Message: fmt.Sprintf("Não foi possível ler o arquivo %s enviado.", strings.ToUpper(part.UploadFormat()))}}
+	default:
+		return fmt.Errorf("part %d: %w", index, err)
+	}
+}
+
+// writeBundleCover draws the cover page: the bundle title and date, then
+// one line per part with its title and first page.
+func writeBundleCover(req domain.BundleRequest, brand BrandingProfile, parts []pdfmerge.Part, created time.Time, options ...documentOption) (*pdfmerge.Document, error) {
+	pdf := newDocument(bundleCoverRenderer, created, options...)
+	leftMargin, _, rightMargin, _ := pdf.GetMargins()
+	pageWidth, _ := pdf.GetPageSize()
+	contentWidth := pageWidth - leftMargin - rightMargin
+
+	if len(brand.Logo) > 0 {
+		registerBrandLogo(pdf, brand)
+		const logoSize = 24.0
+		pdf.Image(brand.logoImageName(), leftMargin+(contentWidth-logoSize)/2, pdf.GetY(), logoSize, logoSize, false, "", 0, "")
+		pdf.SetY(pdf.GetY() + logoSize + 6)
+	}
+
+	pdf.SetFont(documentFontFamily, "B", 18)
+	pdf.SetTextColor(brand.PrimaryColor.R, brand.PrimaryColor.G, brand.PrimaryColor.B)
+	pdf.MultiCell(0, 10, req.ResolvedTitle(), "", "C", false)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont(documentFontFamily, "", 10)
+	pdf.CellFormat(0, 6, buildRunningHeaderBrandLine(brand), "", 1, "C", false, 0, "")
+	pdf.CellFormat(0, 6, "Emitido em "+created.Format("02/01/2006"), "", 1, "C", false, 0, "")
+	pdf.Ln(10)
+
+	pdf.SetFont(documentFontFamily, "B", 13)
+	pdf.CellFormat(0, 8, "SUMÁRIO", "", 1, "L", false, 0, "")
+	pdf.SetDrawColor(brand.AccentColor.R, brand.AccentColor.G, brand.AccentColor.B)
+	pdf.Line(leftMargin, pdf.GetY(), pageWidth-rightMargin, pdf.GetY())
+	pdf.Ln(3)
+
+	pdf.SetFont(documentFontFamily, "", 11)
+	const pageColumn = 14.0
+	page := 2
+	for i, part := range parts {
+		title := fmt.Sprintf("%d. %s", i+1, part.Title)
+		pdf.CellFormat(contentWidth-pageColumn, bundleCoverEntryHeight, withDotLeader(pdf, title, contentWidth-pageColumn), "", 0, "L", false, 0, "")
+		pdf.CellFormat(pageColumn, bundleCoverEntryHeight, strconv.Itoa(page), "", 1, "R", false, 0, "")
+		page += part.Document.PageCount()
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return pdfmerge.Open(buf.Bytes())
+}
+
+// withDotLeader shortens text to fit width and fills the rest with dots,
+// leading the eye to the page number.
+func withDotLeader(pdf *gofpdf.Fpdf, text string, width float64) string {
+	runes := []rune(text)
+	for len(runes) > 1 && pdf.GetStringWidth(string(runes)+" …") > width {
+		runes = runes[:len(runes)-1]
+	}
+	if len(runes) < len([]rune(text)) {
+		return string(runes) + "…"
+	}
+	dot := pdf.GetStringWidth(".")
+	dots := int((width - pdf.GetStringWidth(text+" ") - 2) / dot)
+	if dots < 3 {
+		return text
+	}
+	return text + " " + strings.Repeat(".", dots)
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"pdf-service/internal/domain"
+	"pdf-service/internal/pdfmerge"
+	"pdf-service/internal/signing"
+	"pdf-service/internal/verification"
+)
+
+func bundleProposalRequest() *domain.ProposalRequest {
+	return &domain.ProposalRequest{
+		ProposalID:      "P-2026-0042",
+		ClientName:      "Ana Silva",
+		PropertyAddress: domain.FlexibleAddress{Raw: "Rua A, 10, Goiânia, GO"},
+		TotalValue:      100000,
+		Payment:         domain.PaymentBreakdown{Cash: 100000},
+	}
+}
+
+// bundleReceiptPDF renders a receipt to stand in for an uploaded PDF.
+func bundleReceiptPDF(t *testing.T) []byte {
+	t.Helper()
+	doc, err := NewPDFService().GenerateReceipt(domain.ReceiptRequest{
+		ProposalID:    "proposal-1",
+		Payer:         domain.ContractParty{Name: "Ana Silva"},
+		Payee:         domain.ContractParty{Name: "Carlos Souza"},
+		Amount:        25000,
+		PaymentMethod: "PIX",
+		PaymentDate:   "2026-03-15",
+	})
+	if err != nil {
+		t.Fatalf("GenerateReceipt() error = %v", err)
+	}
+	return doc.PDF
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := range width {
+		img.Set(x, height/2, color.Black)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGenerateBundleMergesPartsBehindATableOfContents(t *testing.T) {
+	contract := stateContractRequest(domain.DocumentStateFinal)
+	req := domain.BundleRequest{
+		Title:     "Dossiê Casa de teste",
+		IssueDate: "2026-03-15",
+		Parts: []domain.BundlePart{
+			{Type: domain.BundlePartProposal, Proposal: bundleProposalRequest()},
+			{Type: domain.BundlePartContract, Contract: &contract},
+			{Type: domain.BundlePartUpload, Title: "Recibo assinado", Data: bundleReceiptPDF(t)},
+			{Type: domain.BundlePartUpload, Title: "Planta baixa", Data: testPNG(t, 300, 200)},
+		},
+	}
+
+	doc, err := NewPDFService().GenerateBundle(req)
+	if err != nil {
+		t.Fatalf("GenerateBundle() error = %v", err)
+	}
+	again, err := NewPDFService().GenerateBundle(req)
+	if err != nil || !bytes.Equal(doc.PDF, again.PDF) {
+		t.Fatalf("expected the same bundle for the same request, error = %v", err)
+	}
+
+	merged, err := pdfmerge.Open(doc.PDF)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	contractDoc, err := NewPDFService().GenerateContract(contract)
+	if err != nil {
+		t.Fatalf("GenerateContract() error = %v", err)
+	}
+	contractPages := bytes.Count(contractDoc.PDF, []byte("/Type /Page\n"))
+	if want := 1 + 1 + contractPages + 1 + 1; merged.PageCount() != want {
+		t.Fatalf("expected %d pages, got %d", want, merged.PageCount())
+	}
+
+	text := extractPDFText(doc.PDF)
+	for _, want := range []string{"Dossiê Casa de teste", "Emitido em 15/03/2026", "Proposta nº P-2026-0042", "Contrato nº contract-1"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in the bundle, got %q", want, text)
+		}
+	}
+	lines := strings.Split(text, "\n")
+	for _, entry := range []struct {
+		title string
+		page  int
+	}{
+		{"1. Proposta", 2},
+		{"2. Contrato", 3},
+		{"3. Recibo assinado", 3 + contractPages},
+		{"4. Planta baixa", 4 + contractPages},
+	} {
+		at := slices.IndexFunc(lines, func(line string) bool { return strings.HasPrefix(line, entry.title+" ..") })
+		if at < 0 || lines[at+1] != strconv.Itoa(entry.page) {
+			t.Fatalf("expected %q on page %d in the table of contents, got %q", entry.title, entry.page, text)
+		}
+	}
+	if !strings.Contains(text, "Página 1 de 1") {
+		t.Fatalf("expected each part to keep its own page numbers, got %q", text)
+	}
+}
+
+func TestGenerateBundleReportsPartProblemsUnderTheirPath(t *testing.T) {
+	encrypted := bytes.Replace(
+		bundleReceiptPDF(t),
+		[]byte("\ntrailer\n<<\n"),
+		[]byte("\ntrailer\n<<\n/Encrypt 99 0 R\n"),
+		1,
+	)
+	_, err := NewPDFService().GenerateBundle(domain.BundleRequest{Parts: []domain.BundlePart{
+		{Type: domain.BundlePartProposal, Proposal: bundleProposalRequest()},
+		{Type: domain.BundlePartUpload, Data: encrypted},
+	}})
+	var fieldErrs domain.ValidationErrors
+	if !errors.As(err, &fieldErrs) || fieldErrs[0].Path != "/parts/1/data" || fieldErrs[0].Code != domain.CodeInvalidFormat {
+		t.Fatalf("expected an invalid_format error at /parts/1/data, got %v", err)
+	}
+
+	_, err = NewPDFService().GenerateBundle(domain.BundleRequest{Parts: []domain.BundlePart{
+		{Type: domain.BundlePartUpload, Data: []byte("%PDF-1.4\nnot really a pdf")},
+	}})
+	if !errors.As(err, &fieldErrs) || fieldErrs[0].Path != "/parts/0/data" {
+		t.Fatalf("expected an error at /parts/0/data, got %v", err)
+	}
+
+	proposal := bundleProposalRequest()
+	proposal.BrandingProfileID = "unknown"
+	_, err = NewPDFService().GenerateBundle(domain.BundleRequest{Parts: []domain.BundlePart{
+		{Type: domain.BundlePartProposal, Proposal: proposal},
+	}})
+	requireFieldError(t, err, "/parts/0/request/branding_profile_id", domain.CodeInvalidChoice)
+}
+
+func TestGenerateBundleSignsTheMergedFile(t *testing.T) {
+	doc, err := NewPDFService(WithSigner(newTestSigner(t))).GenerateBundle(domain.BundleRequest{
+		Sign:  true,
+		Parts: []domain.BundlePart{{Type: domain.BundlePartProposal, Proposal: bundleProposalRequest()}},
+	})
+	if err != nil {
+		t.Fatalf("GenerateBundle() error = %v", err)
+	}
+	if _, err := signing.Verify(doc.PDF); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+}
+
+func TestGenerateBundleRegistersOnlyTheBundle(t *testing.T) {
+	registry := verification.NewMemoryRegistry()
+	contract := stateContractRequest(domain.DocumentStateFinal)
+	doc, err := NewPDFService(WithVerification(registry, "")).GenerateBundle(domain.BundleRequest{Parts: []domain.BundlePart{
+		{Type: domain.BundlePartProposal, Proposal: bundleProposalRequest()},
+		{Type: domain.BundlePartContract, Contract: &contract},
+	}})
+	if err != nil {
+		t.Fatalf("GenerateBundle() error = %v", err)
+	}
+
+	record, err := registry.Lookup(doc.VerificationID)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	sum := sha256.Sum256(doc.PDF)
+	if record.DocumentType != "bundle" || record.SHA256 != hex.EncodeToString(sum[:]) || record.ContractID != "contract-1" {
+		t.Fatalf("unexpected bundle record %+v", record)
+	}
+	text := extractPDFText(doc.PDF)
+	if got := strings.Count(text, "Código de verificação"); got != 1 {
+		t.Fatalf("expected only the cover to print a verification code, got %d codes in %q", got, text)
+	}
+	if !strings.Contains(text, verification.ShortCode(doc.VerificationID)) {
+		t.Fatalf("expected the bundle's code on the cover, got %q", text)
+	}
+}
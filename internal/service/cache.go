@@ -0,0 +1,193 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"pdf-service/internal/cache"
+	"pdf-service/internal/domain"
+)
+
+// renderCacheKeyPrefix namespaces and versions cache keys; bump it when
+// the layout code changes in a way the template provenance does not show.
+const renderCacheKeyPrefix = "pdf-service:render:v2:"
+
+var errCorruptCacheEntry = errors.New("corrupt render cache entry")
+
+// CachedPDFService serves repeated requests for the same document from a
+// cache instead of rendering them again. Store failures are treated as
+// misses, so a cache outage only costs rendering time.
+type CachedPDFService struct {
+	*PDFService
+	store cache.Store
+	ttl   time.Duration
+}
+
+// NewCachedPDFService puts store in front of the generators of s, keeping
+// each PDF for ttl. Previews are not cached.
+func NewCachedPDFService(s *PDFService, store cache.Store, ttl time.Duration) *CachedPDFService {
+	return &CachedPDFService{PDFService: s, store: store, ttl: ttl}
+}
+
+func (c *CachedPDFService) GenerateProposal(req domain.ProposalRequest) (domain.GeneratedDocument, error) {
+	key, err := c.proposalCacheKey(req)
+	return c.cached(key, err, func() (domain.GeneratedDocument, error) { return c.PDFService.GenerateProposal(req) })
+}
+
+func (c *CachedPDFService) GenerateContract(req domain.ContractRequest) (domain.GeneratedDocument, error) {
+	key, err := c.contractCacheKey(req)
+	return c.cached(key, err, func() (domain.GeneratedDocument, error) { return c.PDFService.GenerateContract(req) })
+}
+
+func (c *CachedPDFService) GenerateReceipt(req domain.ReceiptRequest) (domain.GeneratedDocument, error) {
+	key, err := c.receiptCacheKey(req)
+	return c.cached(key, err, func() (domain.GeneratedDocument, error) { return c.PDFService.GenerateReceipt(req) })
+}
+
+func (c *CachedPDFService) GenerateFinancingSimulation(req domain.FinancingSimulationRequest) (domain.GeneratedDocument, error) {
+	key, err := c.financingSimulationCacheKey(req)
+	return c.cached(key, err, func() (domain.GeneratedDocument, error) { return c.PDFService.GenerateFinancingSimulation(req) })
+}
+
+// cached looks key up and renders on a miss. When the key could not be
+// built the request is invalid, so render runs uncached and reports why.
+func (c *CachedPDFService) cached(key string, keyErr error, render func() (domain.GeneratedDocument, error)) (domain.GeneratedDocument, error) {
+	if keyErr != nil {
+		return render()
+	}
+	if data, ok, err := c.store.Get(key); err == nil && ok {
+		if doc, err := decodeCachedDocument(data); err == nil {
+			doc.Cache = domain.CacheHit
+			return doc, nil
+		}
+	}
+
+	doc, err := render()
+	if err != nil {
+		return doc, err
+	}
+	if data, err := encodeCachedDocument(doc); err == nil {
+		_ = c.store.Set(key, data, c.ttl)
+	}
+	doc.Cache = domain.CacheMiss
+	return doc, nil
+}
+
+// The cache keys below hash the request as the renderer sees it: sanitized
+// by Validate, with the branding profile and template version replaced by
+// the profile and template revision they resolve to. Requests that only
+// differ in how they name the default profile or latest template share a
+// key, and editing a profile or template invalidates the old entries.
+
+func (s *PDFService) proposalCacheKey(req domain.ProposalRequest) (string, error) {
+	if err := req.Validate(); err != nil {
+		return "", err
+	}
+	brand, err := s.brandingProfile(req.BrandingProfileID)
+	if err != nil {
+		return "", err
+	}
+	tpl, err := s.documentTemplate(templateDocumentProposal, req.ResolvedDealType(), req.TemplateVersion)
+	if err != nil {
+		return "", err
+	}
+	req.BrandingProfileID, req.TemplateVersion = "", ""
+	return renderCacheKey("proposal", req, brand, tpl.Provenance())
+}
+
+func (s *PDFService) contractCacheKey(req domain.ContractRequest) (string, error) {
+	if err := req.Validate(); err != nil {
+		return "", err
+	}
+	brand, err := s.brandingProfile(req.BrandingProfileID)
+	if err != nil {
+		return "", err
+	}
+	tpl, err := s.documentTemplate(templateDocumentContract, req.DealType, req.TemplateVersion)
+	if err != nil {
+		return "", err
+	}
+	req.BrandingProfileID, req.TemplateVersion = "", ""
+	return renderCacheKey("contract", req, brand, tpl.Provenance())
+}
+
+func (s *PDFService) receiptCacheKey(req domain.ReceiptRequest) (string, error) {
+	if err := req.Validate(); err != nil {
+		return "", err
+	}
+	brand, err := s.brandingProfile(req.BrandingProfileID)
+	if err != nil {
+		return "", err
+	}
+	provenance, err := resolveBuiltInRenderer(receiptRenderer, req.TemplateVersion)
+	if err != nil {
+		return "", err
+	}
+	req.BrandingProfileID, req.TemplateVersion = "", ""
+	return renderCacheKey("receipt", req, brand, provenance)
+}
+
+func (s *PDFService) financingSimulationCacheKey(req domain.FinancingSimulationRequest) (string, error) {
+	if err := req.Validate(); err != nil {
+		return "", err
+	}
+	brand, err := s.brandingProfile(req.BrandingProfileID)
+	if err != nil {
+		return "", err
+	}
+	provenance, err := resolveBuiltInRenderer(financingSimulationRenderer, req.TemplateVersion)
+	if err != nil {
+		return "", err
+	}
+	req.BrandingProfileID, req.TemplateVersion = "", ""
+	return renderCacheKey("financing_simulation", req, brand, provenance)
+}
+
+func renderCacheKey(document string, req any, brand BrandingProfile, provenance domain.TemplateProvenance) (string, error) {
+	canonical, err := json.Marshal(struct {
+		Document string                    `json:"document"`
+		Request  any                       `json:"request"`
+		Brand    BrandingProfile           `json:"brand"`
+		Template domain.TemplateProvenance `json:"template"`
+	}{document, req, brand, provenance})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return renderCacheKeyPrefix + hex.EncodeToString(sum[:]), nil
+}
+
+// cachedDocumentHeader is what a cache entry keeps besides the PDF.
+type cachedDocumentHeader struct {
+	Template       domain.TemplateProvenance `json:"template"`
+	VerificationID string                    `json:"verification_id,omitempty"`
+}
+
+// encodeCachedDocument stores the template provenance and verification ID
+// as a JSON line followed by the PDF bytes.
+func encodeCachedDocument(doc domain.GeneratedDocument) ([]byte, error) {
+	header, err := json.Marshal(cachedDocumentHeader{Template: doc.Template, VerificationID: doc.VerificationID})
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 0, len(header)+1+len(doc.PDF))
+	data = append(data, header...)
+	data = append(data, '\n')
+	return append(data, doc.PDF...), nil
+}
+
+func decodeCachedDocument(data []byte) (domain.GeneratedDocument, error) {
+	header, pdf, ok := bytes.Cut(data, []byte("\n"))
+	if !ok {
+		return domain.GeneratedDocument{}, errCorruptCacheEntry
+	}
+	var decoded cachedDocumentHeader
+	if err := json.Unmarshal(header, &decoded); err != nil {
+		return domain.GeneratedDocument{}, errCorruptCacheEntry
+	}
+	return domain.GeneratedDocument{PDF: pdf, Template: decoded.Template, VerificationID: decoded.VerificationID}, nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"pdf-service/internal/cache"
+	"pdf-service/internal/domain"
+)
+
+// failingStore is a cache that is down.
+type failingStore struct{}
+
+func (failingStore) Get(string) ([]byte, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+
+func (failingStore) Set(string, []byte, time.Duration) error {
+	return errors.New("connection refused")
+}
+
+func TestCachedPDFServiceServesRepeatedRequestsFromCache(t *testing.T) {
+	s := NewCachedPDFService(NewPDFService(), cache.NewLRU(8), time.Hour)
+	req := domain.ProposalRequest{
+		ClientName:            "Ana Silva",
+		PropertyAddressLegacy: "Rua A, 10",
+		TotalValue:            150000,
+		Payment:               domain.PaymentBreakdown{Cash: 150000},
+	}
+
+	first, err := s.GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	if first.Cache != domain.CacheMiss {
+		t.Fatalf("expected a miss, got %q", first.Cache)
+	}
+
+	pinned := req
+	pinned.ClientName = "  Ana Silva "
+	pinned.BrandingProfileID = DefaultBrandingProfileID
+	pinned.TemplateVersion = first.Template.Version
+	second, err := s.GenerateProposal(pinned)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	if second.Cache != domain.CacheHit || !bytes.Equal(second.PDF, first.PDF) || second.Template != first.Template {
+		t.Fatalf("expected the same document from cache, got %q %+v", second.Cache, second.Template)
+	}
+
+	changed := req
+	changed.TotalValue, changed.Payment.Cash = 160000, 160000
+	third, err := s.GenerateProposal(changed)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	if third.Cache != domain.CacheMiss {
+		t.Fatalf("expected a different request to miss, got %q", third.Cache)
+	}
+}
+
+func TestCachedPDFServiceRendersWhenStoreIsDown(t *testing.T) {
+	s := NewCachedPDFService(NewPDFService(), failingStore{}, time.Hour)
+	req := domain.ReceiptRequest{
+		ProposalID:    "proposal-1",
+		Payer:         domain.ContractParty{Name: "Ana Silva"},
+		Payee:         domain.ContractParty{Name: "Carlos Souza"},
+		Amount:        10000,
+		PaymentMethod: "PIX",
+		PaymentDate:   "2026-03-15",
+	}
+
+	doc, err := s.GenerateReceipt(req)
+	if err != nil || len(doc.PDF) == 0 || doc.Cache != domain.CacheMiss {
+		t.Fatalf("expected a rendered receipt, got %q, %v", doc.Cache, err)
+	}
+
+	req.Amount = 0
+	var fieldErrs domain.ValidationErrors
+	if _, err := s.GenerateReceipt(req); !errors.As(err, &fieldErrs) {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"pdf-service/internal/domain"
+	"pdf-service/internal/qrcode"
+)
+
+// pageChrome is what every page carries around its content: the state
+// marks and running header at the top, and the page number, verification
+// code and provenance line at the bottom. gofpdf keeps a single header and
+// a single footer callback, so the document options fill this in and
+// newDocument installs one callback drawing all of it.
+type pageChrome struct {
+	provenance   domain.TemplateProvenance
+	state        string
+	signers      int
+	running      *runningHeader
+	verification *verificationMark
+}
+
+// runningHeader names the document at the top of every page.
+type runningHeader struct {
+	title     string
+	reference string
+	brand     BrandingProfile
+}
+
+// verificationMark is the verification ID of a document with the QR code
+// pointing to its target.
+type verificationMark struct {
+	id     string
+	target string
+	code   *qrcode.Code
+}
+
+// pageNumberSample sizes the page number column. The total is only known
+// when the document is closed, so the number is left-aligned in a column
+// wide enough for it instead of being right-aligned on a placeholder.
+const pageNumberSample = "Página 000 de 000"
+
+// withPageChrome prints the title and reference of the document with the
+// brand line above the top margin of every page, and "Página X de Y" in
+// its footer.
+func withPageChrome(title, reference string, brand BrandingProfile) documentOption {
+	return func(c *pageChrome) {
+		c.running = &runningHeader{title: title, reference: reference, brand: brand}
+	}
+}
+
+// documentReference labels the ID of a document, as in "Contrato nº 123",
+// or is empty without an ID.
+func documentReference(label, id string) string {
+	if id == "" {
+		return ""
+	}
+	return label + " nº " + id
+}
+
+// install sets the page callbacks. It runs before the fonts are
+// registered: with the page count alias set, gofpdf keeps the digits that
+// replace it in the embedded font subsets.
+func (c *pageChrome) install(pdf *gofpdf.Fpdf) {
+	if c.running != nil {
+		pdf.AliasNbPages("")
+	}
+	pdf.SetHeaderFuncMode(func() { c.writeHeader(pdf) }, true)
+	pdf.SetFooterFunc(func() { c.writeFooter(pdf) })
+}
+
+// writeHeader draws the state marks first, so the watermark stays behind
+// everything else on the page.
+func (c *pageChrome) writeHeader(pdf *gofpdf.Fpdf) {
+	switch c.state {
+	case domain.DocumentStateDraft:
+		writeDraftWatermark(pdf)
+	case domain.DocumentStateForSignature:
+		writeInitialsBoxes(pdf, c.signers)
+	}
+	if c.running != nil {
+		writeRunningHeader(pdf, *c.running)
+	}
+}
+
+func (c *pageChrome) writeFooter(pdf *gofpdf.Fpdf) {
+	if c.verification != nil {
+		writeVerificationFooter(pdf, c.verification.code, c.verification.id, c.verification.target)
+	}
+	if c.running != nil {
+		writePageNumber(pdf)
+	}
+	writeProvenanceFooter(pdf, c.provenance)
+}
+
+// writeRunningHeader draws the title and reference on the left and the
+// brand line on the right, above a rule in the accent colour, all within
+// the top margin.
+func writeRunningHeader(pdf *gofpdf.Fpdf, header runningHeader) {
+	leftMargin, topMargin, rightMargin, _ := pdf.GetMargins()
+	pageWidth, _ := pdf.GetPageSize()
+	contentWidth := pageWidth - leftMargin - rightMargin
+
+	label := header.title
+	if header.reference != "" {
+		label += " · " + header.reference
+	}
+	brandLine := buildRunningHeaderBrandLine(header.brand)
+
+	pdf.SetFont(documentFontFamily, "", 7)
+	pdf.SetTextColor(128, 128, 128)
+	brandWidth := pdf.GetStringWidth(brandLine) + 2
+	pdf.SetXY(leftMargin, topMargin-10)
+	pdf.CellFormat(contentWidth-brandWidth, 4, label, "", 0, "L", false, 0, "")
+	pdf.CellFormat(brandWidth, 4, brandLine, "", 0, "R", false, 0, "")
+
+	accent := header.brand.AccentColor
+	pdf.SetDrawColor(accent.R, accent.G, accent.B)
+	pdf.SetLineWidth(0.2)
+	pdf.Line(leftMargin, topMargin-5, pageWidth-rightMargin, topMargin-5)
+}
+
+func buildRunningHeaderBrandLine(brand BrandingProfile) string {
+	line := fallback(brand.DisplayName, brand.LegalName)
+	if brand.CRECI != "" {
+		line += " · CRECI " + brand.CRECI
+	}
+	return line
+}
+
+// writePageNumber draws "Página X de Y" at the bottom right, above the
+// provenance line.
+func writePageNumber(pdf *gofpdf.Fpdf) {
+	_, _, rightMargin, _ := pdf.GetMargins()
+	pageWidth, _ := pdf.GetPageSize()
+	pdf.SetFont(documentFontFamily, "", 7)
+	width := pdf.GetStringWidth(pageNumberSample)
+	pdf.SetTextColor(128, 128, 128)
+	pdf.SetY(-17)
+	pdf.SetX(pageWidth - rightMargin - width)
+	pdf.CellFormat(width, 4, fmt.Sprintf("Página %d de {nb}", pdf.PageNo()), "", 0, "L", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"pdf-service/internal/domain"
+)
+
+func TestGenerateContractNumbersAndHeadsEveryPage(t *testing.T) {
+	doc, err := NewPDFService().GenerateContract(stateContractRequest(domain.DocumentStateFinal))
+	if err != nil {
+		t.Fatalf("GenerateContract() error = %v", err)
+	}
+	text := extractPDFText(doc.PDF)
+	pages := bytes.Count(doc.PDF, []byte("/Type /Page\n"))
+	if pages < 2 {
+		t.Fatalf("expected a multi-page contract, got %d pages", pages)
+	}
+
+	for page := 1; page <= pages; page++ {
+		if want := fmt.Sprintf("Página %d de %d", page, pages); !strings.Contains(text, want) {
+			t.Fatalf("expected %q, got %q", want, text)
+		}
+	}
+	if strings.Contains(text, "{nb}") {
+		t.Fatal("expected the page count alias to be replaced")
+	}
+	header := "CONTRATO DE COMPRA E VENDA · Contrato nº contract-1"
+	if got := strings.Count(text, header); got != pages {
+		t.Fatalf("expected the running header on each of %d pages, got %d", pages, got)
+	}
+	if got := strings.Count(text, "Encontre Aqui Imóveis"); got < pages {
+		t.Fatalf("expected the brand line on each of %d pages, got %d", pages, got)
+	}
+}
+
+func TestGenerateProposalPrintsProposalIDUnlessTemplateOptsOut(t *testing.T) {
+	req := domain.ProposalRequest{
+		ProposalID:      "P-2026-0042",
+		ClientName:      "Ana Silva",
+		PropertyAddress: domain.FlexibleAddress{Raw: "Rua A, 10, Goiânia, GO"},
+		TotalValue:      100000,
+		Payment:         domain.PaymentBreakdown{Cash: 100000},
+	}
+	doc, err := NewPDFService().GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	text := extractPDFText(doc.PDF)
+	if !strings.Contains(text, "PROPOSTA DE COMPRA DE IMÓVEL · Proposta nº P-2026-0042") || !strings.Contains(text, "Página 1 de 1") {
+		t.Fatalf("expected the page chrome, got %q", text)
+	}
+
+	tpl, err := ParseDocumentTemplate([]byte(`{
+		"id": "proposal-sale-timbrado",
+		"version": "2.0.0",
+		"document": "proposal",
+		"deal_type": "sale",
+		"page_chrome": false,
+		"blocks": [{"type": "title", "text": "PROPOSTA EM PAPEL TIMBRADO"}]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseDocumentTemplate() error = %v", err)
+	}
+	doc, err = NewPDFService(WithDocumentTemplates(tpl)).GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	text = extractPDFText(doc.PDF)
+	if strings.Contains(text, "Página") || strings.Contains(text, "P-2026-0042") {
+		t.Fatalf("expected no page chrome, got %q", text)
+	}
+}
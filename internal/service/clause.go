@@ -0,0 +1,126 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// clause is a contract clause before numbering. The caput opens the clause,
+// items are lettered sub-items of the caput and paragraphs follow it as
+// "Parágrafo único" or "§ 1º", "§ 2º"...
+type clause struct {
+	title      string
+	caput      string
+	items      []string
+	paragraphs []string
+}
+
+// clauseSet collects clauses in document order. Optional clauses are added
+// through addIf so that numbering never skips a number.
+type clauseSet struct {
+	clauses []clause
+}
+
+type numberedClause struct {
+	heading    string
+	caput      string
+	items      []string
+	paragraphs []string
+}
+
+var (
+	clauseOrdinalUnits = []string{"", "PRIMEIRA", "SEGUNDA", "TERCEIRA", "QUARTA", "QUINTA", "SEXTA", "SÉTIMA", "OITAVA", "NONA"}
+	clauseOrdinalTens  = []string{"", "DÉCIMA", "VIGÉSIMA", "TRIGÉSIMA", "QUADRAGÉSIMA", "QUINQUAGÉSIMA", "SEXAGÉSIMA", "SEPTUAGÉSIMA", "OCTOGÉSIMA", "NONAGÉSIMA"}
+)
+
+func (s *clauseSet) add(c clause) {
+	s.clauses = append(s.clauses, c)
+}
+
+func (s *clauseSet) addIf(condition bool, c clause) {
+	if condition {
+		s.add(c)
+	}
+}
+
+// numbered assigns the ordinal headings and paragraph markers.
+func (s *clauseSet) numbered() []numberedClause {
+	numbered := make([]numberedClause, 0, len(s.clauses))
+	for i, c := range s.clauses {
+		heading := "CLÁUSULA " + clauseOrdinal(i+1)
+		if c.title != "" {
+			heading += " – " + c.title
+		}
+
+		items := make([]string, 0, len(c.items))
+		for j, item := range c.items {
+			items = append(items, fmt.Sprintf("%s) %s", itemLetter(j), item))
+		}
+
+		paragraphs := make([]string, 0, len(c.paragraphs))
+		for j, paragraph := range c.paragraphs {
+			if len(c.paragraphs) == 1 {
+				paragraphs = append(paragraphs, "Parágrafo único. "+paragraph)
+				continue
+			}
+			paragraphs = append(paragraphs, fmt.Sprintf("§ %dº %s", j+1, paragraph))
+		}
+
+		numbered = append(numbered, numberedClause{
+			heading:    heading,
+			caput:      c.caput,
+			items:      items,
+			paragraphs: paragraphs,
+		})
+	}
+	return numbered
+}
+
+// clauseOrdinal writes feminine ordinals ("PRIMEIRA", "DÉCIMA SEGUNDA")
+// for the 1-99 range used by contracts.
+func clauseOrdinal(n int) string {
+	if n <= 0 || n >= 100 {
+		return fmt.Sprintf("%dª", n)
+	}
+	tens, units := clauseOrdinalTens[n/10], clauseOrdinalUnits[n%10]
+	switch {
+	case tens == "":
+		return units
+	case units == "":
+		return tens
+	default:
+		return tens + " " + units
+	}
+}
+
+func itemLetter(index int) string {
+	if index < 26 {
+		return string(rune('a' + index))
+	}
+	return fmt.Sprintf("%d", index+1)
+}
+
+func writeClauses(pdf *gofpdf.Fpdf, clauses []numberedClause) {
+	leftMargin, _, rightMargin, _ := pdf.GetMargins()
+	pageWidth, _ := pdf.GetPageSize()
+	indent := 6.0
+	contentWidth := pageWidth - leftMargin - rightMargin
+
+	for _, c := range clauses {
+		pdf.SetFont(documentFontFamily, "B", 11)
+		pdf.MultiCell(0, 6, c.heading, "", "L", false)
+		pdf.SetFont(documentFontFamily, "", 11)
+		if c.caput != "" {
+			pdf.MultiCell(0, 6, c.caput, "", "J", false)
+		}
+		for _, item := range c.items {
+			pdf.SetX(leftMargin + indent)
+			pdf.MultiCell(contentWidth-indent, 6, item, "", "J", false)
+		}
+		for _, paragraph := range c.paragraphs {
+			pdf.MultiCell(0, 6, paragraph, "", "J", false)
+		}
+		pdf.Ln(3)
+	}
+}
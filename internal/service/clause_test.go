@@ -0,0 +1,104 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"pdf-service/internal/domain"
+)
+
+func clauseText(clauses []numberedClause) string {
+	var lines []string
+	for _, c := range clauses {
+		lines = append(lines, c.heading, c.caput)
+		lines = append(lines, c.items...)
+		lines = append(lines, c.paragraphs...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestClauseOrdinal(t *testing.T) {
+	cases := map[int]string{
+		1:  "PRIMEIRA",
+		7:  "SÉTIMA",
+		10: "DÉCIMA",
+		12: "DÉCIMA SEGUNDA",
+		20: "VIGÉSIMA",
+		21: "VIGÉSIMA PRIMEIRA",
+		99: "NONAGÉSIMA NONA",
+	}
+	for n, want := range cases {
+		if got := clauseOrdinal(n); got != want {
+			t.Errorf("clauseOrdinal(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestClauseSetNumbersClausesItemsAndParagraphs(t *testing.T) {
+	clauses := &clauseSet{}
+	clauses.add(clause{title: "DO OBJETO", caput: "Objeto.", paragraphs: []string{"Único."}})
+	clauses.addIf(false, clause{title: "OMITIDA"})
+	clauses.add(clause{title: "DO PREÇO", items: []string{"um;", "dois."}, paragraphs: []string{"Primeiro.", "Segundo."}})
+
+	numbered := clauses.numbered()
+	if len(numbered) != 2 {
+		t.Fatalf("expected omitted clause to be skipped, got %d clauses", len(numbered))
+	}
+	if numbered[0].heading != "CLÁUSULA PRIMEIRA – DO OBJETO" || numbered[1].heading != "CLÁUSULA SEGUNDA – DO PREÇO" {
+		t.Fatalf("unexpected headings %q and %q", numbered[0].heading, numbered[1].heading)
+	}
+	if numbered[0].paragraphs[0] != "Parágrafo único. Único." {
+		t.Fatalf("expected single paragraph to be named único, got %q", numbered[0].paragraphs[0])
+	}
+	if numbered[1].items[1] != "b) dois." {
+		t.Fatalf("expected lettered items, got %q", numbered[1].items)
+	}
+	if numbered[1].paragraphs[0] != "§ 1º Primeiro." || numbered[1].paragraphs[1] != "§ 2º Segundo." {
+		t.Fatalf("expected numbered paragraphs, got %q", numbered[1].paragraphs)
+	}
+}
+
+func TestContractTemplateKeepsNumberingContinuousWithoutOptionalTerms(t *testing.T) {
+	text := clauseText(renderContractClauses(t, domain.ContractRequest{
+		DealType:      "rent",
+		PropertyTitle: "Casa",
+		RentalTerms:   domain.RentalTerms{MonthlyRent: 1500, CondominiumResponsibility: "Locatário"},
+	}))
+
+	for _, expected := range []string{
+		"CLÁUSULA PRIMEIRA – DO OBJETO",
+		"CLÁUSULA SEGUNDA – DO ALUGUEL",
+		"CLÁUSULA TERCEIRA – DOS ENCARGOS",
+		"a) condomínio: Locatário.",
+		"CLÁUSULA QUARTA – DAS DISPOSIÇÕES GERAIS",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Fatalf("expected clauses to contain %q, got %q", expected, text)
+		}
+	}
+	for _, forbidden := range []string{"DO PRAZO", "DA GARANTIA", "QUINTA"} {
+		if strings.Contains(text, forbidden) {
+			t.Fatalf("expected %q to be omitted, got %q", forbidden, text)
+		}
+	}
+}
+
+func TestContractTemplateListsSalePaymentItems(t *testing.T) {
+	text := clauseText(renderContractClauses(t, domain.ContractRequest{
+		DealType:      "sale",
+		PropertyTitle: "Casa",
+		SaleTerms:     domain.PaymentBreakdown{Cash: 50000, Financing: 200000},
+	}))
+
+	for _, expected := range []string{
+		"CLÁUSULA SEGUNDA – DO PREÇO E DA FORMA DE PAGAMENTO",
+		"é de R$ 250.000,00 (duzentos e cinquenta mil reais)",
+		"a) em dinheiro, a título de sinal e princípio de pagamento, R$ 50.000,00 (cinquenta mil reais);",
+		"b) mediante financiamento, R$ 200.000,00 (duzentos mil reais).",
+		"Parágrafo único. O valor financiado",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Fatalf("expected clauses to contain %q, got %q", expected, text)
+		}
+	}
+}
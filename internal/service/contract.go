@@ -1,112 +1,284 @@
 package service
 
 import (
-	"bytes"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/jung-kurt/gofpdf"
 
 	"pdf-service/internal/domain"
 )
 
-// GenerateContract produces a non-signed draft. The backend records its
-// template provenance and controls who may retrieve it.
-func (s *PDFService) GenerateContract(req domain.ContractRequest) ([]byte, error) {
-	if err := req.Validate(); err != nil {
-		return nil, err
+// GenerateContract renders the contract in its document state: drafts are
+// watermarked, documents for signature carry an initials box per signer,
+// and final documents neither. Only final documents may be requested with
+// sign, which adds a PAdES signature. The returned provenance is what the
+// backend records for it; the backend also controls who may retrieve it.
+func (s *PDFService) GenerateContract(req domain.ContractRequest) (domain.GeneratedDocument, error) {
+	if err := domain.JoinValidationErrors(req.Validate(), s.CheckReferences(req.References())); err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	if err := s.checkSigning(req.Sign); err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	brand, err := s.brandingProfile(req.BrandingProfileID)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
 	}
 
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.SetMargins(20, 20, 20)
-	pdf.SetAutoPageBreak(true, 20)
-	pdf.SetCompression(false)
-	pdf.AddPage()
-	tr := pdf.UnicodeTranslatorFromDescriptor("")
-
-	title := "MINUTA DE CONTRATO DE COMPRA E VENDA"
-	sellerRole, buyerRole := "VENDEDOR", "COMPRADOR"
+	dealType := "sale"
 	if req.DealType == "rent" {
-		title = "MINUTA DE CONTRATO DE LOCAÇÃO"
-		sellerRole, buyerRole = "LOCADOR", "LOCATÁRIO"
+		dealType = "rent"
+	}
+	tpl, err := s.documentTemplate(templateDocumentContract, dealType, req.TemplateVersion)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	provenance := tpl.Provenance()
+	blocks, err := tpl.render(newContractTemplateData(req, brand))
+	if err != nil {
+		return domain.GeneratedDocument{}, err
 	}
 
-	pdf.SetFont("Arial", "B", 16)
-	pdf.CellFormat(0, 10, tr(title), "", 1, "C", false, 0, "")
-	pdf.Ln(5)
+	signers := len(buildContractSigners("", req.ResolvedSellers())) + len(buildContractSigners("", req.ResolvedBuyers()))
+	key, err := s.contractCacheKey(req)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	created := s.documentDate(req.IssueDate)
+	verificationID, withVerification, err := s.verificationOption(key, created, req.Sign)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	options := []documentOption{withDocumentState(req.ResolvedDocumentState(), signers), withVerification}
+	if tpl.hasPageChrome() {
+		options = append(options, withPageChrome(renderedTitle(blocks), documentReference("Contrato", req.ContractID), brand))
+	}
+	pdf := newDocument(provenance, created, options...)
+	registerBrandLogo(pdf, brand)
+	drawBlocks(pdf, blocks, documentContent{
+		brand:        brand,
+		installments: req.ResolvedSalePayments().Installments,
+		sellers:      req.ResolvedSellers(),
+		buyers:       req.ResolvedBuyers(),
+	})
 
-	pdf.SetFont("Arial", "", 11)
-	pdf.MultiCell(0, 6, tr(fmt.Sprintf(
-		"MINUTA NÃO ASSINADA. Imóvel: %s. Endereço: %s.",
-		req.PropertyTitle,
-		req.PropertyAddress,
-	)), "", "J", false)
-	pdf.Ln(3)
-
-	writeContractParty(pdf, tr, sellerRole, req.Seller)
-	writeContractParty(pdf, tr, buyerRole, req.Buyer)
-
-	pdf.SetFont("Arial", "B", 12)
-	pdf.CellFormat(0, 7, tr("CLÁUSULAS COMERCIAIS"), "", 1, "L", false, 0, "")
-	pdf.SetFont("Arial", "", 11)
-	for _, line := range buildContractCommercialTerms(req) {
-		pdf.MultiCell(0, 6, tr(line), "", "J", false)
-	}
-	pdf.Ln(3)
-	pdf.MultiCell(0, 6, tr("As partes reconhecem que esta minuta deverá ser revisada pela imobiliária e formalizada presencialmente, em papel, antes de produzir efeitos definitivos."), "", "J", false)
-	pdf.Ln(18)
-	pdf.Line(25, pdf.GetY(), 90, pdf.GetY())
-	pdf.Line(120, pdf.GetY(), 185, pdf.GetY())
-	pdf.SetFont("Arial", "", 9)
-	pdf.CellFormat(65, 5, tr(sellerRole), "", 0, "C", false, 0, "")
-	pdf.CellFormat(30, 5, "", "", 0, "C", false, 0, "")
-	pdf.CellFormat(65, 5, tr(buyerRole), "", 1, "C", false, 0, "")
-
-	var out bytes.Buffer
-	if err := pdf.Output(&out); err != nil {
-		return nil, err
-	}
-	return out.Bytes(), nil
+	out, err := s.outputDocument(pdf, req.Sign)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	return s.registerDocument(domain.GeneratedDocument{PDF: out, Template: provenance}, verificationID, "contract", req.ContractID)
 }
 
-func writeContractParty(pdf *gofpdf.Fpdf, tr func(string) string, role string, party domain.ContractParty) {
-	pdf.SetFont("Arial", "B", 12)
-	pdf.CellFormat(0, 7, tr(role), "", 1, "L", false, 0, "")
-	pdf.SetFont("Arial", "", 11)
-	pdf.MultiCell(0, 6, tr(fmt.Sprintf("Nome: %s\nCPF: %s\nE-mail: %s\nTelefone: %s", fallback(party.Name, "______________________"), fallback(party.CPF, "______________________"), fallback(party.Email, "______________________"), fallback(party.Phone, "______________________"))), "", "L", false)
-	pdf.Ln(3)
+// spouseSignerRole labels a consenting spouse's signature line unless the
+// template names it differently.
+const spouseSignerRole = "CÔNJUGE ANUENTE"
+
+type contractRole struct {
+	singular string
+	plural   string
 }
 
-func buildContractCommercialTerms(req domain.ContractRequest) []string {
-	if req.DealType == "rent" {
-		terms := req.RentalTerms
-		lines := []string{fmt.Sprintf("1. Valor mensal da locação: %s.", formatBRL(terms.MonthlyRent))}
-		if terms.GuaranteeType != "" {
-			line := fmt.Sprintf("2. Garantia locatícia: %s", terms.GuaranteeType)
-			if terms.GuaranteeAmount > 0 {
-				line += fmt.Sprintf(" no valor de %s", formatBRL(terms.GuaranteeAmount))
+// contractSigner is one signature line: a party, a company representative
+// or a consenting spouse.
+type contractSigner struct {
+	name string
+	role string
+}
+
+func writeContractParties(pdf *gofpdf.Fpdf, role contractRole, parties []domain.ContractParty) {
+	heading := role.singular
+	if len(parties) > 1 {
+		heading = role.plural
+	}
+	pdf.SetFont(documentFontFamily, "B", 12)
+	pdf.CellFormat(0, 7, heading, "", 1, "L", false, 0, "")
+	pdf.SetFont(documentFontFamily, "", 11)
+	for _, party := range parties {
+		pdf.MultiCell(0, 6, buildContractPartyQualification(party), "", "J", false)
+		pdf.Ln(2)
+	}
+	pdf.Ln(1)
+}
+
+func buildContractSigners(role string, parties []domain.ContractParty) []contractSigner {
+	signers := make([]contractSigner, 0, len(parties))
+	for _, party := range parties {
+		if party.ResolvedKind() == domain.PartyKindCompany && len(party.Representatives) > 0 {
+			for _, representative := range party.Representatives {
+				signers = append(signers, contractSigner{
+					name: fmt.Sprintf("%s p/ %s", representative.Name, party.ResolvedName()),
+					role: role,
+				})
 			}
-			lines = append(lines, line+".")
+			continue
+		}
+		signers = append(signers, contractSigner{name: party.ResolvedName(), role: role})
+		if party.Spouse != nil && party.Spouse.Name != "" {
+			signers = append(signers, contractSigner{name: party.Spouse.Name, role: spouseSignerRole})
+		}
+	}
+	return signers
+}
+
+// writeContractSignatures lays out the signature lines two per row, starting
+// a new page whenever a row would not fit above the bottom margin.
+func writeContractSignatures(pdf *gofpdf.Fpdf, signers []contractSigner) {
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottomMargin := pdf.GetMargins()
+	columns := []float64{25, 120}
+	lineWidth := 65.0
+
+	for i := 0; i < len(signers); i += 2 {
+		if pdf.GetY()+30 > pageHeight-bottomMargin {
+			pdf.AddPage()
 		}
-		if terms.LeaseTermMonths > 0 {
-			lines = append(lines, fmt.Sprintf("3. Prazo de locação: %d meses.", terms.LeaseTermMonths))
+		pdf.Ln(18)
+		lineY := pdf.GetY()
+		pdf.SetFont(documentFontFamily, "", 9)
+		for column := 0; column < 2 && i+column < len(signers); column++ {
+			signer := signers[i+column]
+			x := columns[column]
+			pdf.Line(x, lineY, x+lineWidth, lineY)
+			pdf.SetXY(x, lineY+1)
+			pdf.CellFormat(lineWidth, 5, signer.name, "", 2, "C", false, 0, "")
+			pdf.CellFormat(lineWidth, 5, signer.role, "", 0, "C", false, 0, "")
+		}
+		pdf.SetY(lineY + 11)
+	}
+}
+
+// buildContractPartyQualification writes the "qualificação" paragraph that
+// identifies a party, in the form expected for individuals or companies.
+func buildContractPartyQualification(party domain.ContractParty) string {
+	blank := "______________________"
+	contact := fmt.Sprintf("E-mail: %s. Telefone: %s.", fallback(party.Email, blank), fallback(party.Phone, blank))
+
+	if party.ResolvedKind() != domain.PartyKindCompany {
+		return buildIndividualQualification(party) + ". " + contact
+	}
+
+	name := fallback(party.ResolvedName(), blank)
+	if party.TradeName != "" && party.TradeName != name {
+		name += fmt.Sprintf(" (nome fantasia %s)", party.TradeName)
+	}
+	qualification := fmt.Sprintf(
+		"%s, pessoa jurídica de direito privado, inscrita no CNPJ sob nº %s, com sede à %s",
+		name,
+		fallback(party.CNPJ, blank),
+		fallback(party.RegisteredAddress.String(), blank),
+	)
+
+	representatives := make([]string, 0, len(party.Representatives))
+	for _, representative := range party.Representatives {
+		text := representative.Name
+		if representative.Role != "" {
+			text += ", " + representative.Role
 		}
-		if terms.ExpectedStartDate != "" {
-			lines = append(lines, fmt.Sprintf("4. Início previsto: %s.", formatISODateForDisplay(terms.ExpectedStartDate)))
+		text += fmt.Sprintf(", inscrito(a) no CPF sob nº %s", fallback(representative.CPF, blank))
+		representatives = append(representatives, text)
+	}
+	if len(representatives) > 0 {
+		qualification += ", neste ato representada por " + strings.Join(representatives, "; e por ")
+	}
+	return qualification + ". " + contact
+}
+
+var maritalStatusLabels = map[string]string{
+	domain.MaritalStatusSingle:      "solteiro(a)",
+	domain.MaritalStatusMarried:     "casado(a)",
+	domain.MaritalStatusStableUnion: "convivente em união estável",
+	domain.MaritalStatusDivorced:    "divorciado(a)",
+	domain.MaritalStatusSeparated:   "separado(a) judicialmente",
+	domain.MaritalStatusWidowed:     "viúvo(a)",
+}
+
+var propertyRegimeLabels = map[string]string{
+	domain.PropertyRegimePartialCommunity:    "comunhão parcial de bens",
+	domain.PropertyRegimeUniversalCommunity:  "comunhão universal de bens",
+	domain.PropertyRegimeTotalSeparation:     "separação total de bens",
+	domain.PropertyRegimeMandatorySeparation: "separação obrigatória de bens",
+	domain.PropertyRegimeFinalParticipation:  "participação final nos aquestos",
+}
+
+func buildIndividualQualification(party domain.ContractParty) string {
+	blank := "______________________"
+	parts := []string{fallback(party.Name, blank)}
+	if party.Nationality == "" && party.MaritalStatus == "" && party.Profession == "" && party.RG == "" {
+		parts = append(parts, "pessoa física")
+	}
+	if party.Nationality != "" {
+		parts = append(parts, party.Nationality)
+	}
+	if label, ok := maritalStatusLabels[party.MaritalStatus]; ok {
+		if regime, ok := propertyRegimeLabels[party.ResolvedPropertyRegime()]; ok {
+			label += " sob o regime da " + regime
 		}
-		if terms.MonthlyDueDay > 0 {
-			lines = append(lines, fmt.Sprintf("5. Vencimento mensal: dia %d.", terms.MonthlyDueDay))
+		parts = append(parts, label)
+	}
+	if party.Profession != "" {
+		parts = append(parts, party.Profession)
+	}
+	if party.RG != "" {
+		parts = append(parts, fmt.Sprintf("portador(a) do RG nº %s", party.RG))
+	}
+	parts = append(parts, fmt.Sprintf("inscrito(a) no CPF sob nº %s", fallback(party.CPF, blank)))
+
+	if spouse := party.Spouse; spouse != nil && spouse.Name != "" {
+		spouseParts := []string{"e seu(sua) cônjuge " + spouse.Name}
+		if spouse.Nationality != "" {
+			spouseParts = append(spouseParts, spouse.Nationality)
 		}
-		if terms.CondominiumResponsibility != "" {
-			lines = append(lines, fmt.Sprintf("Responsabilidade pelo condomínio: %s.", terms.CondominiumResponsibility))
+		if spouse.Profession != "" {
+			spouseParts = append(spouseParts, spouse.Profession)
 		}
-		if terms.PropertyTaxResponsibility != "" {
-			lines = append(lines, fmt.Sprintf("Responsabilidade pelo IPTU: %s.", terms.PropertyTaxResponsibility))
+		if spouse.RG != "" {
+			spouseParts = append(spouseParts, fmt.Sprintf("portador(a) do RG nº %s", spouse.RG))
 		}
-		return lines
+		spouseParts = append(spouseParts, fmt.Sprintf("inscrito(a) no CPF sob nº %s", fallback(spouse.CPF, blank)))
+		parts = append(parts, strings.Join(spouseParts, ", "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// buildContractObjectClause identifies the property as the registry does:
+// description, location, areas, matrícula, cartório and municipal record.
+func buildContractObjectClause(req domain.ContractRequest) string {
+	blank := "______________________"
+	property := req.Property
+
+	subject := "o imóvel"
+	if property.Type != "" {
+		subject += " do tipo " + strings.ToLower(property.Type)
 	}
+	parts := []string{fmt.Sprintf(
+		"O presente contrato tem por objeto %s denominado %s, situado à %s",
+		subject,
+		req.PropertyTitle,
+		req.ResolvedPropertyAddress(),
+	)}
+	if property.BuiltArea > 0 {
+		parts = append(parts, fmt.Sprintf("com área construída de %s", formatArea(property.BuiltArea)))
+	}
+	if property.LandArea > 0 {
+		parts = append(parts, fmt.Sprintf("área de terreno de %s", formatArea(property.LandArea)))
+	}
+	if property.ParkingSpaces > 0 {
+		parts = append(parts, formatCountWithWords(property.ParkingSpaces, feminine, "vaga de garagem", "vagas de garagem"))
+	}
+	parts = append(parts,
+		fmt.Sprintf("matriculado sob o nº %s no %s", fallback(property.RegistryNumber, blank), fallback(property.RegistryOffice, "Cartório de Registro de Imóveis competente")),
+		fmt.Sprintf("com inscrição imobiliária (IPTU) nº %s", fallback(property.MunicipalRegistration, blank)),
+	)
+
+	clause := strings.Join(parts, ", ") + "."
+	if property.Description != "" {
+		clause += " " + strings.TrimSuffix(property.Description, ".") + "."
+	}
+	return clause
+}
 
-	proposal := domain.ProposalRequest{Payment: req.SaleTerms}
-	payment := proposal.ResolvedPayments()
-	total := payment.Cash + payment.TradeIn + payment.Financing + payment.Others
-	return buildSaleProposalTerms(total, payment)
+func formatArea(value float64) string {
+	return strings.Replace(strconv.FormatFloat(value, 'f', 2, 64), ".", ",", 1) + " m²"
 }
@@ -8,7 +8,7 @@ import (
 )
 
 func TestGenerateContractUsesRentalTerminology(t *testing.T) {
-	pdf, err := NewPDFService().GenerateContract(domain.ContractRequest{
+	doc, err := NewPDFService().GenerateContract(domain.ContractRequest{
 		ContractID:      "contract-1",
 		DealType:        "rent",
 		PropertyTitle:   "Casa de teste",
@@ -20,14 +20,14 @@ func TestGenerateContractUsesRentalTerminology(t *testing.T) {
 	if err != nil {
 		t.Fatalf("GenerateContract() error = %v", err)
 	}
-	text := string(pdf)
+	text := extractPDFText(doc.PDF)
 	if !strings.Contains(text, "MINUTA DE CONTRATO DE LOCA") || strings.Contains(text, "COMPRA E VENDA") {
 		t.Fatalf("expected rental-only contract text, got %q", text)
 	}
 }
 
 func TestGenerateContractUsesSaleTerminology(t *testing.T) {
-	pdf, err := NewPDFService().GenerateContract(domain.ContractRequest{
+	doc, err := NewPDFService().GenerateContract(domain.ContractRequest{
 		ContractID:      "contract-1",
 		DealType:        "sale",
 		PropertyTitle:   "Casa de teste",
@@ -39,8 +39,165 @@ func TestGenerateContractUsesSaleTerminology(t *testing.T) {
 	if err != nil {
 		t.Fatalf("GenerateContract() error = %v", err)
 	}
-	text := string(pdf)
+	text := extractPDFText(doc.PDF)
 	if !strings.Contains(text, "MINUTA DE CONTRATO DE COMPRA E VENDA") || strings.Contains(text, "LOCA") {
 		t.Fatalf("expected sale-only contract text, got %q", text)
 	}
 }
+
+func TestBuildContractPartyQualificationForCompany(t *testing.T) {
+	got := buildContractPartyQualification(domain.ContractParty{
+		Kind:              domain.PartyKindCompany,
+		LegalName:         "Holding Patrimonial Ltda",
+		TradeName:         "HP Imóveis",
+		CNPJ:              "11.222.333/0001-81",
+		RegisteredAddress: domain.FlexibleAddress{Street: "Rua B", Number: "20", City: "Rio Verde", State: "GO"},
+		Representatives: []domain.LegalRepresentative{
+			{Name: "Maria Souza", CPF: "529.982.247-25", Role: "sócia administradora"},
+		},
+	})
+
+	for _, expected := range []string{
+		"Holding Patrimonial Ltda (nome fantasia HP Imóveis), pessoa jurídica de direito privado",
+		"inscrita no CNPJ sob nº 11.222.333/0001-81",
+		"com sede à Rua B, Nº 20, Rio Verde, GO",
+		"neste ato representada por Maria Souza, sócia administradora, inscrito(a) no CPF sob nº 529.982.247-25",
+	} {
+		if !strings.Contains(got, expected) {
+			t.Fatalf("expected company qualification to contain %q, got %q", expected, got)
+		}
+	}
+}
+
+func TestBuildContractPartyQualificationForPerson(t *testing.T) {
+	got := buildContractPartyQualification(domain.ContractParty{Name: "Ana Silva", CPF: "529.982.247-25"})
+
+	if !strings.HasPrefix(got, "Ana Silva, pessoa física, inscrito(a) no CPF sob nº 529.982.247-25.") {
+		t.Fatalf("unexpected person qualification %q", got)
+	}
+	if strings.Contains(got, "pessoa jurídica") {
+		t.Fatalf("person qualification must not use company wording: %q", got)
+	}
+}
+
+func TestBuildIndividualQualificationIncludesMaritalRegimeAndSpouse(t *testing.T) {
+	got := buildContractPartyQualification(domain.ContractParty{
+		Name:          "João Pereira",
+		CPF:           "529.982.247-25",
+		Nationality:   "brasileiro",
+		MaritalStatus: domain.MaritalStatusMarried,
+		Profession:    "engenheiro",
+		RG:            "1234567 SSP/GO",
+		Spouse:        &domain.ContractSpouse{Name: "Maria Pereira", CPF: "111.444.777-35", Nationality: "brasileira"},
+	})
+
+	expected := "João Pereira, brasileiro, casado(a) sob o regime da comunhão parcial de bens, engenheiro, portador(a) do RG nº 1234567 SSP/GO, inscrito(a) no CPF sob nº 529.982.247-25, e seu(sua) cônjuge Maria Pereira, brasileira, inscrito(a) no CPF sob nº 111.444.777-35."
+	if !strings.HasPrefix(got, expected) {
+		t.Fatalf("unexpected individual qualification:\n got %q\nwant prefix %q", got, expected)
+	}
+}
+
+func TestBuildContractSignersAddsOneLinePerSigner(t *testing.T) {
+	signers := buildContractSigners("VENDEDOR", []domain.ContractParty{
+		{Name: "João Pereira", Spouse: &domain.ContractSpouse{Name: "Maria Pereira"}},
+		{
+			Kind:      domain.PartyKindCompany,
+			LegalName: "Holding Ltda",
+			Representatives: []domain.LegalRepresentative{
+				{Name: "Sócio Um"},
+				{Name: "Sócio Dois"},
+			},
+		},
+	})
+
+	want := []contractSigner{
+		{name: "João Pereira", role: "VENDEDOR"},
+		{name: "Maria Pereira", role: "CÔNJUGE ANUENTE"},
+		{name: "Sócio Um p/ Holding Ltda", role: "VENDEDOR"},
+		{name: "Sócio Dois p/ Holding Ltda", role: "VENDEDOR"},
+	}
+	if len(signers) != len(want) {
+		t.Fatalf("expected %d signers, got %+v", len(want), signers)
+	}
+	for i := range want {
+		if signers[i] != want[i] {
+			t.Fatalf("signer %d = %+v, want %+v", i, signers[i], want[i])
+		}
+	}
+}
+
+func TestGenerateContractRendersEveryBuyer(t *testing.T) {
+	doc, err := NewPDFService().GenerateContract(domain.ContractRequest{
+		DealType:        "sale",
+		PropertyTitle:   "Casa de teste",
+		PropertyAddress: "Rua A, 10, Goiânia, GO",
+		Seller:          domain.ContractParty{Name: "Vendedor"},
+		Buyers: []domain.ContractParty{
+			{Name: "Primeiro Comprador"},
+			{Name: "Segundo Comprador"},
+		},
+		SaleTerms: domain.PaymentBreakdown{Cash: 100000},
+	})
+	if err != nil {
+		t.Fatalf("GenerateContract() error = %v", err)
+	}
+	text := extractPDFText(doc.PDF)
+	for _, expected := range []string{"COMPRADORES", "Primeiro Comprador", "Segundo Comprador"} {
+		if !strings.Contains(text, expected) {
+			t.Fatalf("expected contract to contain %q", expected)
+		}
+	}
+}
+
+func TestBuildContractObjectClauseIdentifiesRegistry(t *testing.T) {
+	got := buildContractObjectClause(domain.ContractRequest{
+		PropertyTitle: "Casa Jardim América",
+		Property: domain.ContractProperty{
+			Type:                  "Casa",
+			RegistryNumber:        "12.345",
+			RegistryOffice:        "Cartório de Registro de Imóveis de Rio Verde",
+			MunicipalRegistration: "001.002.0003",
+			BuiltArea:             120.5,
+			LandArea:              360,
+			ParkingSpaces:         2,
+			Address:               domain.FlexibleAddress{Street: "Rua B", Number: "20", City: "Rio Verde", State: "GO"},
+		},
+	})
+
+	for _, expected := range []string{
+		"o imóvel do tipo casa denominado Casa Jardim América, situado à Rua B, Nº 20, Rio Verde, GO",
+		"com área construída de 120,50 m², área de terreno de 360,00 m², 2 (duas) vagas de garagem",
+		"matriculado sob o nº 12.345 no Cartório de Registro de Imóveis de Rio Verde",
+		"com inscrição imobiliária (IPTU) nº 001.002.0003.",
+	} {
+		if !strings.Contains(got, expected) {
+			t.Fatalf("expected object clause to contain %q, got %q", expected, got)
+		}
+	}
+}
+
+func TestGenerateContractRendersInstallmentSchedule(t *testing.T) {
+	doc, err := NewPDFService().GenerateContract(domain.ContractRequest{
+		DealType:        "sale",
+		PropertyTitle:   "Casa de teste",
+		PropertyAddress: "Rua A, 10, Goiânia, GO",
+		Seller:          domain.ContractParty{Name: "Vendedor"},
+		Buyer:           domain.ContractParty{Name: "Comprador"},
+		SaleTerms: domain.PaymentBreakdown{
+			Cash: 50000,
+			Installments: []domain.Installment{
+				{DueDate: "2026-05-10", Amount: 25000, Description: "Parcela mensal", Index: "INCC"},
+				{DueDate: "2026-12-10", Amount: 75000, Description: "Balão"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("GenerateContract() error = %v", err)
+	}
+	text := extractPDFText(doc.PDF)
+	for _, expected := range []string{"CRONOGRAMA DE PAGAMENTO", "10/12/2026", "R$ 75.000,00", "INCC", "Parcela mensal"} {
+		if !strings.Contains(text, expected) {
+			t.Fatalf("expected contract to contain %q", expected)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"pdf-service/internal/domain"
+)
+
+// documentFontFamily is the embedded DejaVu Sans Condensed family (Bitstream
+// Vera license, see assets/fonts/LICENSE). It is registered as a UTF-8 font
+// so names such as "Łukasz" or "Ñúñez" render without the cp1252 fallback.
+const documentFontFamily = "DejaVuSansCondensed"
+
+var (
+	//go:embed assets/fonts/DejaVuSansCondensed.ttf
+	dejaVuRegularTTF []byte
+	//go:embed assets/fonts/DejaVuSansCondensed-Bold.ttf
+	dejaVuBoldTTF []byte
+	//go:embed assets/fonts/DejaVuSansCondensed-Oblique.ttf
+	dejaVuItalicTTF []byte
+	//go:embed assets/fonts/DejaVuSansCondensed-BoldOblique.ttf
+	dejaVuBoldItalicTTF []byte
+)
+
+// Documents laid out in Go code rather than by a template file still carry
+// an identifier and version; bump the version whenever their wording or
+// layout changes.
+var (
+	receiptRenderer             = builtInRenderer("receipt", "1.0.0")
+	financingSimulationRenderer = builtInRenderer("financing-simulation", "1.0.0")
+	bundleCoverRenderer         = builtInRenderer("bundle-cover", "1.0.0")
+)
+
+// builtInRenderer identifies a Go-coded layout. With no template source to
+// hash, the hash covers the identifier and version.
+func builtInRenderer(id, version string) domain.TemplateProvenance {
+	provenance := domain.TemplateProvenance{ID: id, Version: version}
+	sum := sha256.Sum256([]byte(provenance.String()))
+	provenance.Hash = hex.EncodeToString(sum[:])
+	return provenance
+}
+
+// resolveBuiltInRenderer checks a pinned version against the only version
+// a Go-coded layout has.
+func resolveBuiltInRenderer(renderer domain.TemplateProvenance, pinned string) (domain.TemplateProvenance, error) {
+	if pinned != "" && pinned != renderer.Version {
+		return domain.TemplateProvenance{}, domain.UnknownTemplateVersionError(renderer.ID + " " + pinned)
+	}
+	return renderer, nil
+}
+
+// CheckReferences reports a branding profile or template version that refs
+// names but the service does not have, as field errors. Other failures to
+// resolve them are left for rendering to report.
+func (s *PDFService) CheckReferences(refs domain.DocumentReferences) error {
+	_, brandErr := s.brandingProfile(refs.BrandingProfileID)
+	var templateErr error
+	switch refs.Document {
+	case domain.DocumentTypeProposal, domain.DocumentTypeContract:
+		_, templateErr = s.documentTemplate(refs.Document, refs.DealType, refs.TemplateVersion)
+	case domain.DocumentTypeReceipt:
+		_, templateErr = resolveBuiltInRenderer(receiptRenderer, refs.TemplateVersion)
+	case domain.DocumentTypeFinancingSimulation:
+		_, templateErr = resolveBuiltInRenderer(financingSimulationRenderer, refs.TemplateVersion)
+	}
+	var problems []error
+	for _, err := range []error{brandErr, templateErr} {
+		var fieldErrs domain.ValidationErrors
+		if errors.As(err, &fieldErrs) {
+			problems = append(problems, fieldErrs)
+		}
+	}
+	return domain.JoinValidationErrors(problems...)
+}
+
+// documentTimeZone dates documents in Brasília time, which has had no
+// daylight saving since 2019.
+var documentTimeZone = time.FixedZone("BRT", -3*60*60)
+
+// documentDate is the creation date written into a document: the issue
+// date of the request when it has one, otherwise the day of the service
+// clock. Either way it is midnight, so the same request renders the same
+// bytes all day.
+func (s *PDFService) documentDate(issueDate string) time.Time {
+	if issued, err := time.ParseInLocation("2006-01-02", issueDate, documentTimeZone); err == nil {
+		return issued
+	}
+	year, month, day := s.now().In(documentTimeZone).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, documentTimeZone)
+}
+
+// documentOption adds to the chrome drawn around the content of every
+// page, which must be in place before the first page is added.
+type documentOption func(*pageChrome)
+
+// newDocument returns an A4 portrait document with the shared margins, the
+// embedded font family registered, the template provenance stamped and the
+// first page already added. Catalog sorting and the fixed dates keep the
+// output identical for identical input.
+func newDocument(provenance domain.TemplateProvenance, created time.Time, options ...documentOption) *gofpdf.Fpdf {
+	chrome := &pageChrome{provenance: provenance}
+	for _, option := range options {
+		option(chrome)
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCatalogSort(true)
+	pdf.SetCreationDate(created)
+	pdf.SetModificationDate(created)
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.SetCompression(false)
+	chrome.install(pdf)
+	registerDocumentFonts(pdf)
+	stampProvenance(pdf, provenance)
+	pdf.AddPage()
+	return pdf
+}
+
+// stampProvenance records the template in the document metadata; the page
+// chrome repeats it in a small line at the foot of every page, below the
+// bottom margin.
+func stampProvenance(pdf *gofpdf.Fpdf, provenance domain.TemplateProvenance) {
+	pdf.SetCreator("pdf-service", false)
+	pdf.SetKeywords(fmt.Sprintf("template_id=%s template_version=%s template_sha256=%s", provenance.ID, provenance.Version, provenance.Hash), false)
+}
+
+func writeProvenanceFooter(pdf *gofpdf.Fpdf, provenance domain.TemplateProvenance) {
+	pdf.SetY(-12)
+	pdf.SetFont(documentFontFamily, "", 6)
+	pdf.SetTextColor(128, 128, 128)
+	pdf.CellFormat(0, 4, buildProvenanceLabel(provenance), "", 0, "R", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+}
+
+// outputDocument serializes the document and finishes it.
+func (s *PDFService) outputDocument(pdf *gofpdf.Fpdf, sign bool) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return s.finishDocument(buf.Bytes(), sign)
+}
+
+// finishDocument gives a serialized document a file identifier derived
+// from its content. When sign is set, the signature of the configured
+// certificate is appended as an incremental update.
+func (s *PDFService) finishDocument(data []byte, sign bool) ([]byte, error) {
+	out := stampDocumentID(data)
+	if !sign {
+		return out, nil
+	}
+	if err := s.checkSigning(sign); err != nil {
+		return nil, err
+	}
+	signed, err := s.signer.Sign(out)
+	if err != nil {
+		return nil, fmt.Errorf("sign document: %w", err)
+	}
+	return signed, nil
+}
+
+// stampDocumentID adds the trailer /ID entry gofpdf leaves out, using the
+// first half of the SHA-256 of the file for both of its parts. The trailer
+// follows the cross-reference table, so no offsets move.
+func stampDocumentID(data []byte) []byte {
+	const trailer = "\ntrailer\n<<\n"
+	at := bytes.LastIndex(data, []byte(trailer))
+	if at < 0 {
+		return data
+	}
+	at += len(trailer)
+	sum := sha256.Sum256(data)
+	id := hex.EncodeToString(sum[:16])
+
+	stamped := make([]byte, 0, len(data)+80)
+	stamped = append(stamped, data[:at]...)
+	stamped = append(stamped, "/ID [<"+id+"> <"+id+">]\n"...)
+	return append(stamped, data[at:]...)
+}
+
+func buildProvenanceLabel(provenance domain.TemplateProvenance) string {
+	hash := provenance.Hash
+	if len(hash) > 12 {
+		hash = hash[:12]
+	}
+	return fmt.Sprintf("Modelo %s v%s · %s", provenance.ID, provenance.Version, hash)
+}
+
+func registerDocumentFonts(pdf *gofpdf.Fpdf) {
+	pdf.AddUTF8FontFromBytes(documentFontFamily, "", dejaVuRegularTTF)
+	pdf.AddUTF8FontFromBytes(documentFontFamily, "B", dejaVuBoldTTF)
+	pdf.AddUTF8FontFromBytes(documentFontFamily, "I", dejaVuItalicTTF)
+	pdf.AddUTF8FontFromBytes(documentFontFamily, "BI", dejaVuBoldItalicTTF)
+}
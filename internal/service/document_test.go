@@ -0,0 +1,103 @@
+package service
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"pdf-service/internal/domain"
+)
+
+func TestGenerateProposalKeepsCharactersOutsideCP1252(t *testing.T) {
+	doc, err := NewPDFService().GenerateProposal(domain.ProposalRequest{
+		ClientName: "Łukasz Őry Ñúñez",
+		ClientCPF:  "529.982.247-25",
+		PropertyAddress: domain.FlexibleAddress{
+			Street: "Ulica Żółta",
+			Number: "10",
+			City:   "Goiânia",
+			State:  "GO",
+		},
+		TotalValue:   150000,
+		Payment:      domain.PaymentBreakdown{Cash: 150000},
+		ValidityDays: 10,
+	})
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+
+	text := extractPDFText(doc.PDF)
+	for _, want := range []string{"Łukasz", "Őry", "Ñúñez", "Żółta"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q to survive into the PDF text, got %q", want, text)
+		}
+	}
+	if !bytes.Contains(doc.PDF, []byte("/FontFile2")) {
+		t.Fatal("expected the TrueType font program to be embedded")
+	}
+}
+
+func TestGenerateContractKeepsCharactersOutsideCP1252(t *testing.T) {
+	doc, err := NewPDFService().GenerateContract(domain.ContractRequest{
+		ContractID:      "contract-1",
+		DealType:        "sale",
+		PropertyTitle:   "Casa de teste",
+		PropertyAddress: "Rua A, 10, Goiânia, GO",
+		Seller:          domain.ContractParty{Name: "Władysław Ñandú"},
+		Buyer:           domain.ContractParty{Name: "Zoltán Kővári"},
+		SaleTerms:       domain.PaymentBreakdown{Cash: 100000},
+	})
+	if err != nil {
+		t.Fatalf("GenerateContract() error = %v", err)
+	}
+
+	text := extractPDFText(doc.PDF)
+	for _, want := range []string{"Władysław", "Ñandú", "Kővári"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q to survive into the PDF text, got %q", want, text)
+		}
+	}
+}
+
+func TestGenerateProposalIsByteForByteDeterministic(t *testing.T) {
+	req := domain.ProposalRequest{
+		ClientName:            "Ana Silva",
+		PropertyAddressLegacy: "Rua A, 10, Centro, Goiânia, GO",
+		TotalValue:            150000,
+		Payment:               domain.PaymentBreakdown{Cash: 100000, Installments: []domain.Installment{{DueDate: "2026-05-10", Amount: 50000}}},
+	}
+	clock := func() time.Time { return time.Date(2026, 3, 15, 14, 30, 0, 0, time.UTC) }
+
+	first, err := NewPDFService(WithClock(clock)).GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	second, err := NewPDFService(WithClock(clock)).GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	if !bytes.Equal(first.PDF, second.PDF) {
+		t.Fatal("expected identical requests to render identical bytes")
+	}
+	if !bytes.Contains(first.PDF, []byte("/CreationDate (D:20260315000000")) {
+		t.Fatal("expected the creation date to come from the clock's day")
+	}
+	if !regexp.MustCompile(`trailer\n<<\n/ID \[<[0-9a-f]{32}> <[0-9a-f]{32}>\]`).Match(first.PDF) {
+		t.Fatal("expected a content derived /ID in the trailer")
+	}
+
+	req.IssueDate = "2026-01-20"
+	issued, err := NewPDFService(WithClock(clock)).GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	if !bytes.Contains(issued.PDF, []byte("/CreationDate (D:20260120000000")) {
+		t.Fatal("expected the creation date to come from the issue date")
+	}
+	if bytes.Equal(issued.PDF, first.PDF) {
+		t.Fatal("expected a different issue date to change the document")
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// grammaticalGender selects the agreement of "um/uma", "dois/duas" and the
+// hundreds ("duzentos/duzentas") with the noun being counted.
+type grammaticalGender int
+
+const (
+	masculine grammaticalGender = iota
+	feminine
+)
+
+var (
+	unitWords = []string{
+		"", "um", "dois", "três", "quatro", "cinco", "seis", "sete", "oito", "nove",
+		"dez", "onze", "doze", "treze", "quatorze", "quinze", "dezesseis", "dezessete", "dezoito", "dezenove",
+	}
+	tensWords     = []string{"", "", "vinte", "trinta", "quarenta", "cinquenta", "sessenta", "setenta", "oitenta", "noventa"}
+	hundredsWords = []string{"", "cento", "duzentos", "trezentos", "quatrocentos", "quinhentos", "seiscentos", "setecentos", "oitocentos", "novecentos"}
+)
+
+// formatBRLWithWords renders the numeric value followed by its written form,
+// e.g. "R$ 250.000,00 (duzentos e cinquenta mil reais)".
+func formatBRLWithWords(value float64) string {
+	return fmt.Sprintf("%s (%s)", formatBRL(value), formatBRLInWords(value))
+}
+
+// formatCountWithWords renders counts such as "30 (trinta) meses" or
+// "12 (doze) parcelas", agreeing with the gender of the noun.
+func formatCountWithWords(value int, gender grammaticalGender, singular, plural string) string {
+	noun := plural
+	if value == 1 {
+		noun = singular
+	}
+	return fmt.Sprintf("%d (%s) %s", value, integerInWords(int64(value), gender), noun)
+}
+
+// formatBRLInWords writes a monetary value out in Portuguese, as required
+// next to the numeric value in receipts and contracts.
+func formatBRLInWords(value float64) string {
+	cents := int64(math.Round(math.Abs(value) * 100))
+	reais := cents / 100
+	centavos := cents % 100
+
+	var parts []string
+	if reais > 0 {
+		parts = append(parts, integerInWords(reais, masculine)+currencyConnector(reais)+pluralize(reais, "real", "reais"))
+	}
+	if centavos > 0 {
+		parts = append(parts, integerInWords(centavos, masculine)+" "+pluralize(centavos, "centavo", "centavos"))
+	}
+	if len(parts) == 0 {
+		return "zero reais"
+	}
+	return strings.Join(parts, " e ")
+}
+
+// currencyConnector returns " de " for round millions and above
+// ("um milhão de reais") and a plain space otherwise ("mil reais").
+func currencyConnector(value int64) string {
+	if value >= 1_000_000 && value%1_000_000 == 0 {
+		return " de "
+	}
+	return " "
+}
+
+func pluralize(value int64, singular, plural string) string {
+	if value == 1 {
+		return singular
+	}
+	return plural
+}
+
+// integerInWords writes a non-negative integer in Portuguese. The gender
+// applies to units, hundreds and the thousands multiplier; millions and above
+// are masculine nouns themselves ("duas mil", but "dois milhões").
+func integerInWords(value int64, gender grammaticalGender) string {
+	if value == 0 {
+		return "zero"
+	}
+
+	scales := []struct {
+		singular string
+		plural   string
+	}{
+		{"", ""},
+		{"mil", "mil"},
+		{"milhão", "milhões"},
+		{"bilhão", "bilhões"},
+		{"trilhão", "trilhões"},
+	}
+
+	var groups []int64
+	for remaining := value; remaining > 0; remaining /= 1000 {
+		groups = append(groups, remaining%1000)
+	}
+
+	var words []string
+	var lastGroup int64
+	for i := len(groups) - 1; i >= 0; i-- {
+		group := groups[i]
+		if group == 0 {
+			continue
+		}
+
+		var text string
+		switch {
+		case i == 0:
+			text = hundredsInWords(group, gender)
+		case i == 1 && group == 1:
+			text = "mil"
+		case i == 1:
+			text = hundredsInWords(group, gender) + " mil"
+		default:
+			text = hundredsInWords(group, masculine) + " " + pluralize(group, scales[i].singular, scales[i].plural)
+		}
+		words = append(words, text)
+		lastGroup = group
+	}
+
+	if len(words) == 1 {
+		return words[0]
+	}
+
+	head := strings.Join(words[:len(words)-1], ", ")
+	if lastGroup < 100 || lastGroup%100 == 0 {
+		return head + " e " + words[len(words)-1]
+	}
+	return head + ", " + words[len(words)-1]
+}
+
+func hundredsInWords(value int64, gender grammaticalGender) string {
+	if value == 100 {
+		return "cem"
+	}
+
+	var parts []string
+	if hundreds := value / 100; hundreds > 0 {
+		word := hundredsWords[hundreds]
+		if gender == feminine && hundreds > 1 {
+			word = strings.TrimSuffix(word, "os") + "as"
+		}
+		parts = append(parts, word)
+	}
+	rest := value % 100
+	switch {
+	case rest == 0:
+	case rest < 20:
+		parts = append(parts, unitInWords(rest, gender))
+	default:
+		parts = append(parts, tensWords[rest/10])
+		if unit := rest % 10; unit > 0 {
+			parts = append(parts, unitInWords(unit, gender))
+		}
+	}
+	return strings.Join(parts, " e ")
+}
+
+func unitInWords(value int64, gender grammaticalGender) string {
+	if gender == feminine {
+		switch value {
+		case 1:
+			return "uma"
+		case 2:
+			return "duas"
+		}
+	}
+	return unitWords[value]
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"pdf-service/internal/domain"
+)
+
+func TestFormatBRLInWords(t *testing.T) {
+	cases := []struct {
+		value float64
+		want  string
+	}{
+		{0, "zero reais"},
+		{0.01, "um centavo"},
+		{0.5, "cinquenta centavos"},
+		{1, "um real"},
+		{2, "dois reais"},
+		{1.01, "um real e um centavo"},
+		{100, "cem reais"},
+		{101, "cento e um reais"},
+		{1000, "mil reais"},
+		{1001, "mil e um reais"},
+		{1100, "mil e cem reais"},
+		{1250, "mil, duzentos e cinquenta reais"},
+		{21000, "vinte e um mil reais"},
+		{250000, "duzentos e cinquenta mil reais"},
+		{1000000, "um milhão de reais"},
+		{2000000, "dois milhões de reais"},
+		{1000001, "um milhão e um reais"},
+		{1200000, "um milhão e duzentos mil reais"},
+		{1234567.89, "um milhão, duzentos e trinta e quatro mil, quinhentos e sessenta e sete reais e oitenta e nove centavos"},
+		{1000000000, "um bilhão de reais"},
+		{3500000000, "três bilhões e quinhentos milhões de reais"},
+		{2500000000.1, "dois bilhões e quinhentos milhões de reais e dez centavos"},
+	}
+
+	for _, tc := range cases {
+		if got := formatBRLInWords(tc.value); got != tc.want {
+			t.Errorf("formatBRLInWords(%v) = %q, want %q", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestIntegerInWordsAgreesWithGender(t *testing.T) {
+	cases := []struct {
+		value  int64
+		gender grammaticalGender
+		want   string
+	}{
+		{1, masculine, "um"},
+		{1, feminine, "uma"},
+		{2, feminine, "duas"},
+		{12, feminine, "doze"},
+		{22, feminine, "vinte e duas"},
+		{200, feminine, "duzentas"},
+		{201, feminine, "duzentas e uma"},
+		{2000, feminine, "duas mil"},
+		{2000000, feminine, "dois milhões"},
+		{500, masculine, "quinhentos"},
+	}
+
+	for _, tc := range cases {
+		if got := integerInWords(tc.value, tc.gender); got != tc.want {
+			t.Errorf("integerInWords(%d, %v) = %q, want %q", tc.value, tc.gender, got, tc.want)
+		}
+	}
+}
+
+func TestFormatCountWithWordsUsesSingularNoun(t *testing.T) {
+	if got := formatCountWithWords(1, feminine, "parcela", "parcelas"); got != "1 (uma) parcela" {
+		t.Fatalf("unexpected singular count %q", got)
+	}
+	if got := formatCountWithWords(12, feminine, "parcela", "parcelas"); got != "12 (doze) parcelas" {
+		t.Fatalf("unexpected plural count %q", got)
+	}
+}
+
+func TestSaleProposalTermsStateValuesInWords(t *testing.T) {
+	blocks := renderProposalBlocks(t, domain.ProposalRequest{
+		TotalValue: 250000,
+		Payment:    domain.PaymentBreakdown{Cash: 50000, Financing: 200000},
+	})
+	joined := strings.Join(findRenderedBlock(t, blocks, blockList).items, "\n")
+
+	for _, expected := range []string{
+		"Valor total da proposta: R$ 250.000,00 (duzentos e cinquenta mil reais)",
+		"Valor em dinheiro (Sinal/Entrada): R$ 50.000,00 (cinquenta mil reais)",
+		"Financiamento: R$ 200.000,00 (duzentos mil reais)",
+	} {
+		if !strings.Contains(joined, expected) {
+			t.Fatalf("expected sale terms to contain %q, got %q", expected, joined)
+		}
+	}
+}
+
+func TestBuildContractCommercialTermsStatesRentInWords(t *testing.T) {
+	joined := clauseText(renderContractClauses(t, domain.ContractRequest{
+		DealType:    "rent",
+		RentalTerms: domain.RentalTerms{MonthlyRent: 1500, GuaranteeType: "Caução", GuaranteeAmount: 4500},
+	}))
+
+	for _, expected := range []string{
+		"R$ 1.500,00 (mil e quinhentos reais)",
+		"no valor de R$ 4.500,00 (quatro mil e quinhentos reais)",
+	} {
+		if !strings.Contains(joined, expected) {
+			t.Fatalf("expected contract terms to contain %q, got %q", expected, joined)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"pdf-service/internal/domain"
+)
+
+// amortizationRow is one month of a financing schedule.
+type amortizationRow struct {
+	number       int
+	payment      float64
+	interest     float64
+	amortization float64
+	balance      float64
+}
+
+// monthlyRateFromAnnual converts an effective annual rate in percent to the
+// equivalent effective monthly rate, as Brazilian banks quote financing.
+func monthlyRateFromAnnual(annualPercent float64) float64 {
+	return math.Pow(1+annualPercent/100, 1.0/12) - 1
+}
+
+// buildAmortizationSchedule computes the SAC (constant amortization) or Price
+// (constant payment) schedule for the given principal.
+func buildAmortizationSchedule(principal, monthlyRate float64, months int, system string) []amortizationRow {
+	rows := make([]amortizationRow, 0, months)
+	balance := principal
+
+	pricePayment := principal / float64(months)
+	if system == domain.AmortizationPrice && monthlyRate > 0 {
+		pricePayment = principal * monthlyRate / (1 - math.Pow(1+monthlyRate, -float64(months)))
+	}
+
+	for number := 1; number <= months; number++ {
+		interest := balance * monthlyRate
+		var amortization float64
+		if system == domain.AmortizationPrice {
+			amortization = pricePayment - interest
+		} else {
+			amortization = principal / float64(months)
+		}
+		if number == months {
+			amortization = balance
+		}
+		balance -= amortization
+		if math.Abs(balance) < 0.005 {
+			balance = 0
+		}
+		rows = append(rows, amortizationRow{
+			number:       number,
+			payment:      amortization + interest,
+			interest:     interest,
+			amortization: amortization,
+			balance:      balance,
+		})
+	}
+	return rows
+}
+
+// GenerateFinancingSimulation renders an illustrative bank financing table
+// so brokers don't have to build one in a spreadsheet.
+func (s *PDFService) GenerateFinancingSimulation(req domain.FinancingSimulationRequest) (domain.GeneratedDocument, error) {
+	if err := domain.JoinValidationErrors(req.Validate(), s.CheckReferences(req.References())); err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	if err := s.checkSigning(req.Sign); err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	brand, err := s.brandingProfile(req.BrandingProfileID)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	provenance, err := resolveBuiltInRenderer(financingSimulationRenderer, req.TemplateVersion)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+
+	system := req.ResolvedSystem()
+	monthlyRate := monthlyRateFromAnnual(req.AnnualInterestRate)
+	rows := buildAmortizationSchedule(req.FinancedAmount(), monthlyRate, req.TermMonths, system)
+
+	key, err := s.financingSimulationCacheKey(req)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	created := s.documentDate(req.IssueDate)
+	verificationID := s.newVerificationID(key, created, req.Sign)
+	pdf := newDocument(provenance, created)
+
+	registerBrandLogo(pdf, brand)
+
+	pdf.SetFont(documentFontFamily, "B", 16)
+	pdf.SetTextColor(brand.PrimaryColor.R, brand.PrimaryColor.G, brand.PrimaryColor.B)
+	pdf.CellFormat(0, 10, "SIMULAÇÃO DE FINANCIAMENTO IMOBILIÁRIO", "", 1, "C", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(4)
+
+	pdf.SetFont(documentFontFamily, "", 11)
+	for _, line := range buildFinancingSummary(req, monthlyRate, rows) {
+		pdf.MultiCell(0, 6, line, "", "L", false)
+	}
+	pdf.Ln(4)
+
+	writeAmortizationTable(pdf, rows, brand.AccentColor)
+
+	pdf.Ln(4)
+	pdf.SetFont(documentFontFamily, "I", 9)
+	pdf.MultiCell(0, 5, "Simulação meramente ilustrativa, sem valor de proposta de crédito. Taxas, seguros (MIP e DFI), tarifas e a aprovação do financiamento dependem da instituição financeira.", "", "J", false)
+
+	writeBrandFooter(pdf, brand)
+
+	out, err := s.outputDocument(pdf, req.Sign)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	return s.registerDocument(domain.GeneratedDocument{PDF: out, Template: provenance}, verificationID, "financing_simulation", "")
+}
+
+func buildFinancingSummary(req domain.FinancingSimulationRequest, monthlyRate float64, rows []amortizationRow) []string {
+	totalInterest, totalPaid := 0.0, 0.0
+	for _, row := range rows {
+		totalInterest += row.interest
+		totalPaid += row.payment
+	}
+
+	systemLabel := "SAC (Sistema de Amortização Constante)"
+	if req.ResolvedSystem() == domain.AmortizationPrice {
+		systemLabel = "Price (Tabela Price)"
+	}
+
+	var lines []string
+	if req.ClientName != "" {
+		lines = append(lines, fmt.Sprintf("Cliente: %s", req.ClientName))
+	}
+	if req.PropertyTitle != "" {
+		lines = append(lines, fmt.Sprintf("Imóvel: %s", req.PropertyTitle))
+	}
+	lines = append(lines,
+		fmt.Sprintf("Valor do imóvel: %s", formatBRL(req.PropertyValue)),
+		fmt.Sprintf("Entrada: %s", formatBRL(req.ResolvedEntry())),
+		fmt.Sprintf("Valor financiado: %s", formatBRL(req.FinancedAmount())),
+		fmt.Sprintf("Taxa de juros: %s%% a.a. (%s%% a.m.)", formatPercent(req.AnnualInterestRate, 2), formatPercent(monthlyRate*100, 4)),
+		fmt.Sprintf("Prazo: %s", formatCountWithWords(req.TermMonths, masculine, "mês", "meses")),
+		fmt.Sprintf("Sistema de amortização: %s", systemLabel),
+		fmt.Sprintf("Primeira prestação: %s", formatBRL(rows[0].payment)),
+		fmt.Sprintf("Última prestação: %s", formatBRL(rows[len(rows)-1].payment)),
+		fmt.Sprintf("Total de juros pagos: %s", formatBRL(totalInterest)),
+		fmt.Sprintf("Total pago ao banco: %s", formatBRL(totalPaid)),
+	)
+	return lines
+}
+
+func formatPercent(value float64, decimals int) string {
+	return strings.Replace(strconv.FormatFloat(value, 'f', decimals, 64), ".", ",", 1)
+}
+
+// writeAmortizationTable draws the schedule across as many pages as needed,
+// repeating the header row on each page and closing with the totals.
+func writeAmortizationTable(pdf *gofpdf.Fpdf, rows []amortizationRow, headerFill RGB) {
+	widths := []float64{14, 38, 38, 38, 42}
+	headers := []string{"Mês", "Prestação", "Juros", "Amortização", "Saldo devedor"}
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottomMargin := pdf.GetMargins()
+	rowHeight := 6.0
+
+	writeHeader := func() {
+		pdf.SetFont(documentFontFamily, "B", 9)
+		pdf.SetFillColor(headerFill.R, headerFill.G, headerFill.B)
+		for i, header := range headers {
+			pdf.CellFormat(widths[i], rowHeight, header, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont(documentFontFamily, "", 9)
+	}
+
+	writeHeader()
+	totalPayment, totalInterest, totalAmortization := 0.0, 0.0, 0.0
+	for _, row := range rows {
+		if pdf.GetY()+rowHeight > pageHeight-bottomMargin {
+			pdf.AddPage()
+			writeHeader()
+		}
+		totalPayment += row.payment
+		totalInterest += row.interest
+		totalAmortization += row.amortization
+		pdf.CellFormat(widths[0], rowHeight, strconv.Itoa(row.number), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(widths[1], rowHeight, formatBRL(row.payment), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[2], rowHeight, formatBRL(row.interest), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], rowHeight, formatBRL(row.amortization), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], rowHeight, formatBRL(row.balance), "1", 1, "R", false, 0, "")
+	}
+
+	if pdf.GetY()+rowHeight > pageHeight-bottomMargin {
+		pdf.AddPage()
+		writeHeader()
+	}
+	pdf.SetFont(documentFontFamily, "B", 9)
+	pdf.CellFormat(widths[0], rowHeight, "Total", "1", 0, "C", false, 0, "")
+	pdf.CellFormat(widths[1], rowHeight, formatBRL(totalPayment), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[2], rowHeight, formatBRL(totalInterest), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[3], rowHeight, formatBRL(totalAmortization), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[4], rowHeight, "", "1", 1, "R", false, 0, "")
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"pdf-service/internal/domain"
+)
+
+func TestMonthlyRateFromAnnualIsEquivalentRate(t *testing.T) {
+	monthly := monthlyRateFromAnnual(12)
+	if annual := math.Pow(1+monthly, 12) - 1; math.Abs(annual-0.12) > 1e-12 {
+		t.Fatalf("expected monthly rate to compound to 12%% a.a., got %v", annual)
+	}
+}
+
+func TestBuildAmortizationScheduleSAC(t *testing.T) {
+	rows := buildAmortizationSchedule(120000, 0.01, 12, domain.AmortizationSAC)
+
+	if len(rows) != 12 {
+		t.Fatalf("expected 12 rows, got %d", len(rows))
+	}
+	if rows[0].amortization != 10000 || rows[0].interest != 1200 || rows[0].payment != 11200 {
+		t.Fatalf("unexpected first SAC row %+v", rows[0])
+	}
+	if math.Abs(rows[11].interest-100) > 1e-9 || rows[11].balance != 0 {
+		t.Fatalf("unexpected last SAC row %+v", rows[11])
+	}
+}
+
+func TestBuildAmortizationSchedulePrice(t *testing.T) {
+	rows := buildAmortizationSchedule(100000, 0.01, 12, domain.AmortizationPrice)
+
+	amortized := 0.0
+	for _, row := range rows {
+		if math.Abs(row.payment-8884.88) > 0.01 {
+			t.Fatalf("expected constant Price payment of 8884.88, got %+v", row)
+		}
+		amortized += row.amortization
+	}
+	if math.Abs(amortized-100000) > 1e-6 || rows[11].balance != 0 {
+		t.Fatalf("expected principal to be fully amortized, got %v (balance %v)", amortized, rows[11].balance)
+	}
+}
+
+func TestBuildAmortizationScheduleWithoutInterest(t *testing.T) {
+	rows := buildAmortizationSchedule(1200, 0, 12, domain.AmortizationPrice)
+
+	if rows[0].payment != 100 || rows[11].balance != 0 {
+		t.Fatalf("unexpected zero-interest schedule %+v ... %+v", rows[0], rows[11])
+	}
+}
+
+func TestGenerateFinancingSimulationRendersMultiPageTable(t *testing.T) {
+	doc, err := NewPDFService().GenerateFinancingSimulation(domain.FinancingSimulationRequest{
+		ClientName:         "Ana Silva",
+		PropertyValue:      400000,
+		Entry:              80000,
+		AnnualInterestRate: 10.5,
+		TermMonths:         360,
+		System:             "sac",
+	})
+	if err != nil {
+		t.Fatalf("GenerateFinancingSimulation() error = %v", err)
+	}
+	if !bytes.HasPrefix(doc.PDF, []byte("%PDF")) {
+		t.Fatalf("expected PDF signature prefix, got %q", doc.PDF[:4])
+	}
+	text := extractPDFText(doc.PDF)
+	if !strings.Contains(text, "Total de juros pagos") {
+		t.Fatal("expected financing summary with total interest")
+	}
+	if pages := bytes.Count(doc.PDF, []byte("/Type /Page\n")); pages < 5 {
+		t.Fatalf("expected a multi-page schedule, got %d pages", pages)
+	}
+}
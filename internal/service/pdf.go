@@ -7,94 +7,148 @@ import (
 	"math"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jung-kurt/gofpdf"
 
 	"pdf-service/internal/domain"
+	"pdf-service/internal/signing"
+	"pdf-service/internal/verification"
 )
 
 //go:embed assets/branding/encontre_imagem.png
 var encontreLogoPNG []byte
 
-type PDFService struct{}
+type PDFService struct {
+	brandingProfiles  map[string]BrandingProfile
+	defaultBrandingID string
+	templates         map[string][]*DocumentTemplate
+	now               func() time.Time
+	signer            *signing.Signer
 
-const (
-	institutionalPartyName = "Encontre Aqui Imóveis Ltda"
-	institutionalPartyRole = "Imobiliária"
-)
+	registry            verification.Registry
+	verificationBaseURL string
+	verificationSecret  []byte
+}
+
+// Option customizes a PDFService at construction time.
+type Option func(*PDFService)
 
-func NewPDFService() *PDFService {
-	return &PDFService{}
+// WithBrandingProfiles registers additional branding profiles, replacing
+// the built-in one when a profile reuses its ID.
+func WithBrandingProfiles(profiles ...BrandingProfile) Option {
+	return func(s *PDFService) {
+		for _, profile := range profiles {
+			s.brandingProfiles[profile.ID] = profile
+		}
+	}
 }
 
-func (s *PDFService) GenerateProposal(req domain.ProposalRequest) ([]byte, error) {
-	if err := req.Validate(); err != nil {
-		return nil, err
+// WithDefaultBrandingProfile selects the profile used when a request does
+// not name one.
+func WithDefaultBrandingProfile(id string) Option {
+	return func(s *PDFService) {
+		if strings.TrimSpace(id) != "" {
+			s.defaultBrandingID = strings.TrimSpace(id)
+		}
 	}
+}
 
-	clientName := req.ResolvedClientName()
-	address, city, state := resolveIntroLocation(req)
-	validityDays := req.ResolvedValidityDays()
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.SetMargins(20, 20, 20)
-	pdf.SetAutoPageBreak(true, 20)
-	pdf.SetCompression(false)
-	pdf.AddPage()
-	tr := pdf.UnicodeTranslatorFromDescriptor("")
-
-	logoOpts := gofpdf.ImageOptions{ImageType: "PNG", ReadDpi: true}
-	_ = pdf.RegisterImageOptionsReader("ea_logo", logoOpts, bytes.NewReader(encontreLogoPNG))
-
-	// Header
-	pdf.SetFont("Arial", "B", 18)
-	pdf.CellFormat(0, 10, tr(buildProposalTitle(req)), "", 1, "C", false, 0, "")
-	pdf.Ln(8)
-
-	// Addressee
-	pdf.SetFont("Arial", "B", 13)
-	pdf.CellFormat(0, 7, tr("ILMO(A). SR(A).:"), "", 1, "L", false, 0, "")
-	pdf.SetFont("Arial", "BI", 13)
-	pdf.CellFormat(0, 7, tr(buildInstitutionalAddresseeLabel()), "", 1, "L", false, 0, "")
-	pdf.Ln(12)
-
-	// Intro paragraph
-	intro := buildIntroParagraph(req, address, city, state)
-	pdf.SetFont("Arial", "", 12)
-	pdf.MultiCell(0, 7, tr(intro), "", "J", false)
-	pdf.Ln(2)
-
-	for _, line := range buildProposalTerms(req) {
-		pdf.MultiCell(0, 7, tr(line), "", "L", false)
+// WithDocumentTemplates registers document template revisions. Requests
+// use the newest version of their document and deal type unless they pin
+// one; a revision with the same version as a built-in one replaces it.
+func WithDocumentTemplates(templates ...*DocumentTemplate) Option {
+	return func(s *PDFService) {
+		for _, tpl := range templates {
+			s.registerTemplate(tpl)
+		}
 	}
+}
 
-	pdf.Ln(2)
-	pdf.SetFont("Arial", "", 12)
-	pdf.MultiCell(0, 7, tr(fmt.Sprintf("Esta proposta é válida por %d dias.", validityDays)), "", "L", false)
+// WithClock replaces time.Now as the source of the creation date of
+// documents whose request has no issue date.
+func WithClock(now func() time.Time) Option {
+	return func(s *PDFService) {
+		s.now = now
+	}
+}
 
-	currentY := pdf.GetY()
-	if currentY > 240 {
-		pdf.AddPage()
-		currentY = 40
+func NewPDFService(options ...Option) *PDFService {
+	builtIn := defaultBrandingProfile()
+	s := &PDFService{
+		brandingProfiles:  map[string]BrandingProfile{builtIn.ID: builtIn},
+		defaultBrandingID: builtIn.ID,
+		templates:         map[string][]*DocumentTemplate{},
+		now:               time.Now,
+	}
+	for _, tpl := range defaultDocumentTemplates() {
+		s.registerTemplate(tpl)
 	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
 
-	signatureY := currentY + 24
-	leftMargin, _, rightMargin, _ := pdf.GetMargins()
-	pageWidth, _ := pdf.GetPageSize()
-	contentWidth := pageWidth - leftMargin - rightMargin
-	gap := 18.0
-	lineWidth := (contentWidth - gap) / 2
-	leftX := leftMargin
-	rightX := leftX + lineWidth + gap
+func (s *PDFService) GenerateProposal(req domain.ProposalRequest) (domain.GeneratedDocument, error) {
+	if err := domain.JoinValidationErrors(req.Validate(), s.CheckReferences(req.References())); err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	if err := s.checkSigning(req.Sign); err != nil {
+		return domain.GeneratedDocument{}, err
+	}
 
-	pdf.Line(leftX, signatureY, leftX+lineWidth, signatureY)
-	pdf.Line(rightX, signatureY, rightX+lineWidth, signatureY)
+	brand, err := s.brandingProfile(req.BrandingProfileID)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+
+	tpl, err := s.documentTemplate(templateDocumentProposal, req.ResolvedDealType(), req.TemplateVersion)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	provenance := tpl.Provenance()
+	blocks, err := tpl.render(newProposalTemplateData(req, brand))
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+
+	key, err := s.proposalCacheKey(req)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	created := s.documentDate(req.IssueDate)
+	verificationID, withVerification, err := s.verificationOption(key, created, req.Sign)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	// The proposal is signed by the client and the agency.
+	options := []documentOption{withDocumentState(req.ResolvedDocumentState(), 2), withVerification}
+	if tpl.hasPageChrome() {
+		options = append(options, withPageChrome(renderedTitle(blocks), documentReference("Proposta", req.ProposalID), brand))
+	}
+	pdf := newDocument(provenance, created, options...)
+	registerBrandLogo(pdf, brand)
+	drawBlocks(pdf, blocks, documentContent{brand: brand, installments: req.ResolvedPayments().Installments})
 
-	pdf.SetFont("Arial", "", 11)
-	pdf.SetXY(leftX, signatureY+2)
-	pdf.CellFormat(lineWidth, 6, tr(buildProponentSignatureLabel(clientName)), "", 0, "C", false, 0, "")
-	pdf.SetXY(rightX, signatureY+2)
-	pdf.CellFormat(lineWidth, 6, tr(buildInstitutionalSignatureLabel()), "", 0, "C", false, 0, "")
+	out, err := s.outputDocument(pdf, req.Sign)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	return s.registerDocument(domain.GeneratedDocument{PDF: out, Template: provenance}, verificationID, "proposal", "")
+}
+
+func registerBrandLogo(pdf *gofpdf.Fpdf, brand BrandingProfile) {
+	if len(brand.Logo) == 0 {
+		return
+	}
+	logoOpts := gofpdf.ImageOptions{ImageType: logoImageType(brand.Logo), ReadDpi: true}
+	_ = pdf.RegisterImageOptionsReader(brand.logoImageName(), logoOpts, bytes.NewReader(brand.Logo))
+}
 
+// writeBrandFooter draws the institutional footer below the current content,
+// pushing it to a new page when there is not enough room left.
+func writeBrandFooter(pdf *gofpdf.Fpdf, brand BrandingProfile) {
 	lmF, _, rmF, bmF := pdf.GetMargins()
 	pwF, phF := pdf.GetPageSize()
 	footerMinY := phF - bmF - 44
@@ -107,7 +161,7 @@ func (s *PDFService) GenerateProposal(req domain.ProposalRequest) ([]byte, error
 	footerY := math.Max(pdf.GetY()+12, phF-bmF-38)
 	pdf.SetY(footerY)
 
-	pdf.SetDrawColor(220, 220, 220)
+	pdf.SetDrawColor(brand.AccentColor.R, brand.AccentColor.G, brand.AccentColor.B)
 	pdf.Line(lmF, pdf.GetY(), pwF-rmF, pdf.GetY())
 	pdf.Ln(4)
 
@@ -115,79 +169,18 @@ func (s *PDFService) GenerateProposal(req domain.ProposalRequest) ([]byte, error
 	logoW := 18.0
 	logoH := 18.0
 	logoX := lmF + (contentWF-logoW)/2
-	pdf.Image("ea_logo", logoX, brandRowY, logoW, logoH, false, "", 0, "")
+	if len(brand.Logo) > 0 {
+		pdf.Image(brand.logoImageName(), logoX, brandRowY, logoW, logoH, false, "", 0, "")
+	}
 	pdf.SetY(brandRowY + logoH + 1)
-	pdf.SetFont("Arial", "B", 9)
-	pdf.CellFormat(0, 5, tr(buildFooterBrandLabel()), "", 1, "C", false, 0, "")
+	pdf.SetFont(documentFontFamily, "B", 9)
+	pdf.CellFormat(0, 5, buildFooterBrandLabel(brand), "", 1, "C", false, 0, "")
 	pdf.Ln(1)
 
-	pdf.SetFont("Arial", "", 8)
-	footerLine1 := tr("Rua Abel Pereira de Castro, 838, Centro, Rio Verde – GO | CEP: 75.901-060")
-	footerLine2 := tr("64 3050-0118 | Instagram: @encontre.aquiimoveis")
-	pdf.MultiCell(0, 4, footerLine1, "", "C", false)
-	pdf.MultiCell(0, 4, footerLine2, "", "C", false)
-
-	var out bytes.Buffer
-	if err := pdf.Output(&out); err != nil {
-		return nil, err
+	pdf.SetFont(documentFontFamily, "", 8)
+	for _, line := range brand.footerLines() {
+		pdf.MultiCell(0, 4, line, "", "C", false)
 	}
-	return out.Bytes(), nil
-}
-
-func buildProposalTerms(req domain.ProposalRequest) []string {
-	if req.ResolvedDealType() == "rent" {
-		return buildRentalProposalTerms(req.ResolvedRentalTerms())
-	}
-	return buildSaleProposalTerms(req.ResolvedTotalValue(), req.ResolvedPayments())
-}
-
-func buildSaleProposalTerms(totalValue float64, payment domain.PaymentValues) []string {
-	lines := []string{
-		fmt.Sprintf("• Valor total da proposta: %s", formatBRL(totalValue)),
-		fmt.Sprintf("• Valor em dinheiro (Sinal/Entrada): %s", formatBRL(payment.Cash)),
-	}
-	if payment.TradeIn > 0 {
-		lines = append(lines, fmt.Sprintf("• Permuta: %s", formatBRL(payment.TradeIn)))
-	}
-	if payment.Financing > 0 {
-		lines = append(lines, fmt.Sprintf("• Financiamento: %s", formatBRL(payment.Financing)))
-	}
-	if payment.Others > 0 {
-		lines = append(lines, fmt.Sprintf("• Outros: %s", formatBRL(payment.Others)))
-	}
-	return lines
-}
-
-func buildRentalProposalTerms(terms domain.RentalTerms) []string {
-	lines := []string{
-		fmt.Sprintf("• Valor mensal do aluguel: %s", formatBRL(terms.MonthlyRent)),
-	}
-	if terms.GuaranteeType != "" {
-		guarantee := fmt.Sprintf("• Garantia locatícia: %s", terms.GuaranteeType)
-		if terms.GuaranteeAmount > 0 {
-			guarantee += fmt.Sprintf(" (%s)", formatBRL(terms.GuaranteeAmount))
-		}
-		lines = append(lines, guarantee)
-	}
-	if terms.LeaseTermMonths > 0 {
-		lines = append(lines, fmt.Sprintf("• Prazo de locação: %d meses", terms.LeaseTermMonths))
-	}
-	if terms.ExpectedStartDate != "" {
-		lines = append(lines, fmt.Sprintf("• Início previsto da locação: %s", formatISODateForDisplay(terms.ExpectedStartDate)))
-	}
-	if terms.MonthlyDueDay > 0 {
-		lines = append(lines, fmt.Sprintf("• Vencimento mensal: dia %d", terms.MonthlyDueDay))
-	}
-	if terms.CondominiumResponsibility != "" {
-		lines = append(lines, fmt.Sprintf("• Responsabilidade pelo condomínio: %s", terms.CondominiumResponsibility))
-	}
-	if terms.PropertyTaxResponsibility != "" {
-		lines = append(lines, fmt.Sprintf("• Responsabilidade pelo IPTU: %s", terms.PropertyTaxResponsibility))
-	}
-	if terms.Observations != "" {
-		lines = append(lines, fmt.Sprintf("• Observações: %s", terms.Observations))
-	}
-	return lines
 }
 
 func formatISODateForDisplay(value string) string {
@@ -224,45 +217,8 @@ func formatBRL(value float64) string {
 	return fmt.Sprintf("R$ %s%s,%02d", sign, grouped.String(), decPart)
 }
 
-func buildProponentSignatureLabel(clientName string) string {
-	return fmt.Sprintf("%s (Proponente)", clientName)
-}
-
-func buildInstitutionalSignatureLabel() string {
-	return fmt.Sprintf("%s (%s)", institutionalPartyName, institutionalPartyRole)
-}
-
-func buildInstitutionalAddresseeLabel() string {
-	return strings.ToUpper(institutionalPartyName)
-}
-
-func buildFooterBrandLabel() string {
-	return "Encontre Aqui Imóveis"
-}
-
-func buildProposalTitle(req domain.ProposalRequest) string {
-	if req.ResolvedDealType() == "rent" {
-		return "PROPOSTA DE LOCAÇÃO DE IMÓVEL"
-	}
-	return "PROPOSTA DE COMPRA DE IMÓVEL"
-}
-
-func buildIntroParagraph(req domain.ProposalRequest, address, city, state string) string {
-	if req.ResolvedDealType() == "rent" {
-		return fmt.Sprintf(
-			"Esta proposta tem por finalidade assegurar uma oferta de locação de um imóvel de sua propriedade, situado à %s, na cidade de %s - %s, por parte do locatário, nas seguintes condições:",
-			address,
-			city,
-			state,
-		)
-	}
-
-	return fmt.Sprintf(
-		"Esta proposta tem por finalidade assegurar uma oferta de compra de um imóvel de sua propriedade, situado à %s, na cidade de %s - %s, por parte do comprador, nas seguintes condições:",
-		address,
-		city,
-		state,
-	)
+func buildFooterBrandLabel(brand BrandingProfile) string {
+	return brand.DisplayName
 }
 
 func fallback(value, fallbackValue string) string {
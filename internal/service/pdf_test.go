@@ -2,6 +2,7 @@ package service
 
 import (
 	"bytes"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -9,12 +10,27 @@ import (
 	"pdf-service/internal/domain"
 )
 
+// requireFieldError fails unless err reports code at path.
+func requireFieldError(t *testing.T, err error, path, code string) {
+	t.Helper()
+	var fieldErrs domain.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	for _, fieldErr := range fieldErrs {
+		if fieldErr.Path == path && fieldErr.Code == code {
+			return
+		}
+	}
+	t.Fatalf("expected %s at %s, got %v", code, path, err)
+}
+
 func TestGenerateProposalReturnsPDFBytesForValidRequest(t *testing.T) {
 	svc := NewPDFService()
 
 	req := domain.ProposalRequest{
 		ClientName: "Ana Silva",
-		ClientCPF:  "123.456.789-00",
+		ClientCPF:  "529.982.247-25",
 		BrokerName: "Pedro Souza",
 		PropertyAddress: domain.FlexibleAddress{
 			Street:       "Rua A",
@@ -33,15 +49,15 @@ func TestGenerateProposalReturnsPDFBytesForValidRequest(t *testing.T) {
 		ValidityDays: 10,
 	}
 
-	pdfBytes, err := svc.GenerateProposal(req)
+	doc, err := svc.GenerateProposal(req)
 	if err != nil {
 		t.Fatalf("expected valid PDF generation, got error: %v", err)
 	}
-	if len(pdfBytes) == 0 {
+	if len(doc.PDF) == 0 {
 		t.Fatal("expected generated PDF bytes, got empty output")
 	}
-	if !bytes.HasPrefix(pdfBytes, []byte("%PDF")) {
-		t.Fatalf("expected PDF signature prefix, got %q", pdfBytes[:4])
+	if !bytes.HasPrefix(doc.PDF, []byte("%PDF")) {
+		t.Fatalf("expected PDF signature prefix, got %q", doc.PDF[:4])
 	}
 }
 
@@ -50,7 +66,7 @@ func TestGenerateProposalCompletesWithinNominalBudget(t *testing.T) {
 
 	req := domain.ProposalRequest{
 		ClientName: "Ana Silva",
-		ClientCPF:  "123.456.789-00",
+		ClientCPF:  "529.982.247-25",
 		BrokerName: "Pedro Souza",
 		PropertyAddress: domain.FlexibleAddress{
 			Street:       "Rua A",
@@ -81,16 +97,16 @@ func TestGenerateProposalCompletesWithinNominalBudget(t *testing.T) {
 	}
 }
 
-func TestBuildProponentSignatureLabelUsesClientName(t *testing.T) {
-	got := buildProponentSignatureLabel("Joana Pereira")
+func TestProposalSignatureLabelUsesClientName(t *testing.T) {
+	got := findRenderedBlock(t, renderProposalBlocks(t, domain.ProposalRequest{ClientName: "Joana Pereira"}), blockSignaturePair).items[0]
 
 	if got != "Joana Pereira (Proponente)" {
 		t.Fatalf("expected proponent signature label to use client name, got %q", got)
 	}
 }
 
-func TestBuildInstitutionalSignatureLabelUsesInstitutionalParty(t *testing.T) {
-	got := buildInstitutionalSignatureLabel()
+func TestProposalSignatureLabelUsesInstitutionalParty(t *testing.T) {
+	got := findRenderedBlock(t, renderProposalBlocks(t, domain.ProposalRequest{}), blockSignaturePair).items[1]
 
 	if got != "Encontre Aqui Imóveis Ltda (Imobiliária)" {
 		t.Fatalf("expected institutional signature label, got %q", got)
@@ -104,7 +120,7 @@ func TestGenerateProposalDoesNotEmbedBrokerNameInPDFBytes(t *testing.T) {
 
 	req := domain.ProposalRequest{
 		ClientName: "Comprador Proponente",
-		ClientCPF:  "123.456.789-00",
+		ClientCPF:  "529.982.247-25",
 		BrokerName: uniqueBroker,
 		PropertyAddress: domain.FlexibleAddress{
 			Street:       "Rua A",
@@ -123,49 +139,50 @@ func TestGenerateProposalDoesNotEmbedBrokerNameInPDFBytes(t *testing.T) {
 		ValidityDays: 10,
 	}
 
-	pdfBytes, err := svc.GenerateProposal(req)
+	doc, err := svc.GenerateProposal(req)
 	if err != nil {
 		t.Fatalf("expected valid PDF generation, got error: %v", err)
 	}
-	if bytes.Contains(pdfBytes, []byte(uniqueBroker)) {
+	if strings.Contains(extractPDFText(doc.PDF), uniqueBroker) {
 		t.Fatalf("PDF must not contain broker string %q (template must stay non-leaky for legacy vendedor/captador)", uniqueBroker)
 	}
 }
 
 func TestBuildFooterBrandLabelUsesSeparatedBrandName(t *testing.T) {
-	got := buildFooterBrandLabel()
+	got := buildFooterBrandLabel(defaultBrandingProfile())
 
 	if got != "Encontre Aqui Imóveis" {
 		t.Fatalf("expected footer brand label to be separated, got %q", got)
 	}
 }
 
-func TestBuildInstitutionalAddresseeLabelUsesUppercaseBrand(t *testing.T) {
-	got := buildInstitutionalAddresseeLabel()
+func TestProposalAddresseeLabelUsesUppercaseBrand(t *testing.T) {
+	got := renderedLines(renderProposalBlocks(t, domain.ProposalRequest{}))[2]
 
 	if got != "ENCONTRE AQUI IMÓVEIS LTDA" {
 		t.Fatalf("expected addressee label to be uppercase, got %q", got)
 	}
 }
 
-func TestBuildProposalTitleUsesRentForRentalProposals(t *testing.T) {
+func TestProposalTitleUsesRentForRentalProposals(t *testing.T) {
 	req := domain.ProposalRequest{
 		DealType: "rent",
 	}
 
-	got := buildProposalTitle(req)
+	got := findRenderedBlock(t, renderProposalBlocks(t, req), blockTitle).text
 
 	if got != "PROPOSTA DE LOCAÇÃO DE IMÓVEL" {
 		t.Fatalf("expected rent proposal title, got %q", got)
 	}
 }
 
-func TestBuildIntroParagraphUsesRentVocabularyForRentalProposals(t *testing.T) {
+func TestProposalIntroParagraphUsesRentVocabularyForRentalProposals(t *testing.T) {
 	req := domain.ProposalRequest{
-		DealType: "rent",
+		DealType:        "rent",
+		PropertyAddress: domain.FlexibleAddress{Raw: "Rua A, 10, Rio Verde, GO"},
 	}
 
-	got := buildIntroParagraph(req, "Rua A, 10", "Rio Verde", "GO")
+	got := findRenderedBlock(t, renderProposalBlocks(t, req), blockParagraph).text
 
 	if !strings.Contains(got, "oferta de locação") {
 		t.Fatalf("expected rental intro paragraph, got %q", got)
@@ -175,8 +192,8 @@ func TestBuildIntroParagraphUsesRentVocabularyForRentalProposals(t *testing.T) {
 	}
 }
 
-func TestBuildRentalProposalTermsUsesOnlyRentalVocabulary(t *testing.T) {
-	lines := buildRentalProposalTerms(domain.RentalTerms{
+func TestRentalProposalTermsUseOnlyRentalVocabulary(t *testing.T) {
+	blocks := renderProposalBlocks(t, domain.ProposalRequest{DealType: "rent", RentalTerms: domain.RentalTerms{
 		MonthlyRent:               2500,
 		GuaranteeType:             "Seguro-fiança",
 		GuaranteeAmount:           2500,
@@ -186,12 +203,12 @@ func TestBuildRentalProposalTermsUsesOnlyRentalVocabulary(t *testing.T) {
 		CondominiumResponsibility: "Locatário",
 		PropertyTaxResponsibility: "Locador",
 		Observations:              "Sem animais.",
-	})
-	joined := strings.Join(lines, "\n")
+	}})
+	joined := strings.Join(findRenderedBlock(t, blocks, blockList).items, "\n")
 
 	for _, expected := range []string{
 		"Valor mensal do aluguel: R$ 2.500,00",
-		"Garantia locatícia: Seguro-fiança (R$ 2.500,00)",
+		"Garantia locatícia: Seguro-fiança, no valor de R$ 2.500,00 (dois mil e quinhentos reais)",
 		"Prazo de locação: 30 meses",
 		"Início previsto da locação: 01/08/2026",
 		"Vencimento mensal: dia 10",
@@ -213,7 +230,7 @@ func TestGenerateProposalRendersRentalTermsWithoutSaleTerms(t *testing.T) {
 	svc := NewPDFService()
 	req := domain.ProposalRequest{
 		ClientName:      "Ana Silva",
-		ClientCPF:       "123.456.789-00",
+		ClientCPF:       "529.982.247-25",
 		BrokerName:      "Pedro Souza",
 		DealType:        "rent",
 		PropertyAddress: domain.FlexibleAddress{Raw: "Rua A, 10, Centro, Goiânia, GO"},
@@ -225,11 +242,11 @@ func TestGenerateProposalRendersRentalTermsWithoutSaleTerms(t *testing.T) {
 		ValidityDays: 10,
 	}
 
-	pdfBytes, err := svc.GenerateProposal(req)
+	doc, err := svc.GenerateProposal(req)
 	if err != nil {
 		t.Fatalf("expected rental PDF generation, got error: %v", err)
 	}
-	pdfText := string(pdfBytes)
+	pdfText := extractPDFText(doc.PDF)
 	if !strings.Contains(pdfText, "Valor mensal do aluguel") {
 		t.Fatalf("expected rental PDF content, got %q", pdfText)
 	}
@@ -239,3 +256,22 @@ func TestGenerateProposalRendersRentalTermsWithoutSaleTerms(t *testing.T) {
 		}
 	}
 }
+
+func TestSaleProposalTermsSummarizeInstallments(t *testing.T) {
+	blocks := renderProposalBlocks(t, domain.ProposalRequest{
+		TotalValue: 100000,
+		Payment: domain.PaymentBreakdown{
+			Cash: 40000,
+			Installments: []domain.Installment{
+				{DueDate: "2026-05-10", Amount: 30000},
+				{DueDate: "2026-06-10", Amount: 30000},
+			},
+		},
+	})
+	joined := strings.Join(findRenderedBlock(t, blocks, blockList).items, "\n")
+
+	expected := "• Parcelamento: 2 (duas) parcelas, totalizando R$ 60.000,00 (sessenta mil reais), conforme cronograma de pagamento abaixo"
+	if !strings.Contains(joined, expected) {
+		t.Fatalf("expected sale terms to contain %q, got %q", expected, joined)
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf16"
+)
+
+// extractPDFText returns the text drawn by the uncompressed page content
+// streams, one line per BT/ET block. Strings written with the embedded UTF-8
+// font are UTF-16BE, so they are decoded back before being compared.
+func extractPDFText(pdf []byte) string {
+	var text strings.Builder
+	rest := pdf
+	for {
+		start := bytes.Index(rest, []byte("stream\n"))
+		if start < 0 {
+			break
+		}
+		end := bytes.Index(rest[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		dictionary := rest[:start]
+		if objStart := bytes.LastIndex(dictionary, []byte("obj")); objStart >= 0 {
+			dictionary = dictionary[objStart:]
+		}
+		content := rest[start+len("stream\n") : start+end]
+		rest = rest[start+end+len("endstream"):]
+		if bytes.Contains(dictionary, []byte("/Filter")) || bytes.Contains(dictionary, []byte("/Subtype")) {
+			continue
+		}
+		writeContentStreamText(&text, content)
+	}
+	return text.String()
+}
+
+func writeContentStreamText(text *strings.Builder, content []byte) {
+	inText := false
+	for i := 0; i < len(content); i++ {
+		switch {
+		case !inText && bytes.HasPrefix(content[i:], []byte("BT")):
+			inText = true
+			i++
+		case inText && bytes.HasPrefix(content[i:], []byte("ET")):
+			inText = false
+			text.WriteByte('\n')
+			i++
+		case inText && content[i] == '(':
+			literal, next := readPDFStringLiteral(content, i+1)
+			text.WriteString(decodePDFString(literal))
+			i = next
+		}
+	}
+}
+
+// readPDFStringLiteral unescapes a literal string starting right after its
+// opening parenthesis and returns the index of the closing one.
+func readPDFStringLiteral(content []byte, i int) ([]byte, int) {
+	var literal []byte
+	depth := 1
+	for ; i < len(content); i++ {
+		c := content[i]
+		switch c {
+		case '\\':
+			i++
+			if i >= len(content) {
+				return literal, i
+			}
+			switch content[i] {
+			case 'n':
+				literal = append(literal, '\n')
+			case 'r':
+				literal = append(literal, '\r')
+			case 't':
+				literal = append(literal, '\t')
+			default:
+				literal = append(literal, content[i])
+			}
+		case '(':
+			depth++
+			literal = append(literal, c)
+		case ')':
+			depth--
+			if depth == 0 {
+				return literal, i
+			}
+			literal = append(literal, c)
+		default:
+			literal = append(literal, c)
+		}
+	}
+	return literal, i
+}
+
+func decodePDFString(literal []byte) string {
+	if len(literal)%2 != 0 {
+		return string(literal)
+	}
+	units := make([]uint16, 0, len(literal)/2)
+	for i := 0; i < len(literal); i += 2 {
+		units = append(units, uint16(literal[i])<<8|uint16(literal[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,63 @@
+package service
+
+import "pdf-service/internal/domain"
+
+// PreviewProposal validates and resolves a proposal the way GenerateProposal
+// does, including the branding profile and template selection, and returns
+// the resolved model instead of a PDF.
+func (s *PDFService) PreviewProposal(req domain.ProposalRequest) (domain.ProposalPreview, error) {
+	if err := domain.JoinValidationErrors(req.Validate(), s.CheckReferences(req.References())); err != nil {
+		return domain.ProposalPreview{}, err
+	}
+	brand, err := s.brandingProfile(req.BrandingProfileID)
+	if err != nil {
+		return domain.ProposalPreview{}, err
+	}
+	tpl, err := s.documentTemplate(templateDocumentProposal, req.ResolvedDealType(), req.TemplateVersion)
+	if err != nil {
+		return domain.ProposalPreview{}, err
+	}
+
+	address, city, state := resolveIntroLocation(req)
+	return domain.ProposalPreview{
+		Proposal:          req.Resolve(),
+		IntroLocation:     domain.IntroLocation{Address: address, City: city, State: state},
+		BrandingProfileID: brand.ID,
+		Template:          tpl.Provenance(),
+	}, nil
+}
+
+// PreviewContract validates and resolves a contract the way GenerateContract
+// does and returns the resolved model instead of a PDF.
+func (s *PDFService) PreviewContract(req domain.ContractRequest) (domain.ContractPreview, error) {
+	if err := domain.JoinValidationErrors(req.Validate(), s.CheckReferences(req.References())); err != nil {
+		return domain.ContractPreview{}, err
+	}
+	brand, err := s.brandingProfile(req.BrandingProfileID)
+	if err != nil {
+		return domain.ContractPreview{}, err
+	}
+	tpl, err := s.documentTemplate(templateDocumentContract, req.DealType, req.TemplateVersion)
+	if err != nil {
+		return domain.ContractPreview{}, err
+	}
+
+	return domain.ContractPreview{
+		Contract:          req.Resolve(),
+		ObjectDescription: buildContractObjectClause(req),
+		Qualifications: domain.PartyQualifications{
+			Sellers: buildContractPartyQualifications(req.ResolvedSellers()),
+			Buyers:  buildContractPartyQualifications(req.ResolvedBuyers()),
+		},
+		BrandingProfileID: brand.ID,
+		Template:          tpl.Provenance(),
+	}, nil
+}
+
+func buildContractPartyQualifications(parties []domain.ContractParty) []string {
+	qualifications := make([]string, len(parties))
+	for i, party := range parties {
+		qualifications[i] = buildContractPartyQualification(party)
+	}
+	return qualifications
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"pdf-service/internal/domain"
+)
+
+func TestPreviewProposalMatchesWhatTheDocumentPrints(t *testing.T) {
+	req := domain.ProposalRequest{
+		ClientName:            "Ana Silva",
+		PropertyAddressLegacy: "Rua A, 10, Centro, Goiânia, GO",
+		TotalValueLegacy:      150000,
+		Payment:               domain.PaymentBreakdown{Dinheiro: 150000},
+	}
+
+	preview, err := NewPDFService().PreviewProposal(req)
+	if err != nil {
+		t.Fatalf("PreviewProposal() error = %v", err)
+	}
+
+	if got := preview.IntroLocation; got != (domain.IntroLocation{Address: "Rua A, 10, Centro", City: "Goiânia", State: "GO"}) {
+		t.Fatalf("unexpected intro location %+v", got)
+	}
+	if got := preview.Proposal.Payment.Cash; got.Value != 150000 || got.Source != "/payment/dinheiro" {
+		t.Fatalf("expected cash from the dinheiro alias, got %+v", got)
+	}
+	if preview.BrandingProfileID != DefaultBrandingProfileID || preview.Template.ID != "proposal-sale" {
+		t.Fatalf("expected default brand and sale template, got %q and %+v", preview.BrandingProfileID, preview.Template)
+	}
+
+	doc, err := NewPDFService().GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	if doc.Template != preview.Template {
+		t.Fatalf("expected preview template %+v to match the document's %+v", preview.Template, doc.Template)
+	}
+	if text := extractPDFText(doc.PDF); !strings.Contains(text, preview.IntroLocation.Address) {
+		t.Fatalf("expected %q in the document, got %q", preview.IntroLocation.Address, text)
+	}
+}
+
+func TestPreviewContractResolvesPartiesAndQualifications(t *testing.T) {
+	preview, err := NewPDFService().PreviewContract(domain.ContractRequest{
+		DealType:        "sale",
+		PropertyTitle:   "Casa",
+		PropertyAddress: "Rua A, 10",
+		Seller: domain.ContractParty{
+			LegalName:       "Holding Ltda",
+			CNPJ:            "11222333000181",
+			Representatives: []domain.LegalRepresentative{{Name: "Maria Souza"}},
+		},
+		Buyers:    []domain.ContractParty{{Name: "Comprador", MaritalStatus: "casado", Spouse: &domain.ContractSpouse{Name: "Cônjuge", CPF: "52998224725"}}},
+		SaleTerms: domain.PaymentBreakdown{Cash: 100000},
+	})
+	if err != nil {
+		t.Fatalf("PreviewContract() error = %v", err)
+	}
+
+	sellers := preview.Contract.Sellers
+	if sellers.Source != "/seller" || sellers.Value[0].Kind.Source != "/seller/cnpj" || sellers.Value[0].Name.Source != "/seller/legal_name" {
+		t.Fatalf("expected the legacy seller resolved as a company, got %+v", sellers)
+	}
+	buyer := preview.Contract.Buyers.Value[0]
+	if buyer.PropertyRegime.Value != domain.PropertyRegimePartialCommunity || buyer.PropertyRegime.Source != domain.SourceDefault || !buyer.RequiresSpouseConsent {
+		t.Fatalf("expected the default regime to require spouse consent, got %+v", buyer)
+	}
+	if got := preview.Contract.SaleValue; got == nil || got.Value != 100000 || got.Source != "/sale_terms" {
+		t.Fatalf("expected the sale value derived from sale_terms, got %+v", got)
+	}
+	if !strings.Contains(preview.Qualifications.Sellers[0], "11.222.333/0001-81") {
+		t.Fatalf("expected the normalized CNPJ in the qualification, got %q", preview.Qualifications.Sellers[0])
+	}
+	if !strings.Contains(preview.ObjectDescription, "Rua A, 10") {
+		t.Fatalf("expected the address in the object description, got %q", preview.ObjectDescription)
+	}
+}
+
+func TestPreviewRejectsUnknownTemplateVersion(t *testing.T) {
+	_, err := NewPDFService().PreviewProposal(domain.ProposalRequest{
+		ClientName:      "Ana Silva",
+		PropertyAddress: domain.FlexibleAddress{Raw: "Rua A, 10"},
+		TotalValue:      100,
+		Payment:         domain.PaymentBreakdown{Cash: 100},
+		TemplateVersion: "9.9.9",
+	})
+	requireFieldError(t, err, "/template_version", domain.CodeInvalidChoice)
+}
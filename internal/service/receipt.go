@@ -0,0 +1,118 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"pdf-service/internal/domain"
+)
+
+// GenerateReceipt renders the earnest money (sinal/arras) receipt issued
+// once a buyer pays after a proposal is accepted.
+func (s *PDFService) GenerateReceipt(req domain.ReceiptRequest) (domain.GeneratedDocument, error) {
+	if err := domain.JoinValidationErrors(req.Validate(), s.CheckReferences(req.References())); err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	if err := s.checkSigning(req.Sign); err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	brand, err := s.brandingProfile(req.BrandingProfileID)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	provenance, err := resolveBuiltInRenderer(receiptRenderer, req.TemplateVersion)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+
+	key, err := s.receiptCacheKey(req)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	created := s.documentDate(req.IssueDate)
+	verificationID := s.newVerificationID(key, created, req.Sign)
+	pdf := newDocument(provenance, created)
+
+	registerBrandLogo(pdf, brand)
+
+	pdf.SetFont(documentFontFamily, "B", 16)
+	pdf.SetTextColor(brand.PrimaryColor.R, brand.PrimaryColor.G, brand.PrimaryColor.B)
+	pdf.CellFormat(0, 10, "RECIBO DE SINAL E PRINCÍPIO DE PAGAMENTO", "", 1, "C", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	if req.ReceiptID != "" {
+		pdf.SetFont(documentFontFamily, "", 10)
+		pdf.CellFormat(0, 6, fmt.Sprintf("Recibo nº %s", req.ReceiptID), "", 1, "C", false, 0, "")
+	}
+	pdf.Ln(6)
+
+	pdf.SetFont(documentFontFamily, "B", 14)
+	pdf.CellFormat(0, 10, fmt.Sprintf("Valor: %s", formatBRL(req.Amount)), "1", 1, "C", false, 0, "")
+	pdf.Ln(6)
+
+	pdf.SetFont(documentFontFamily, "", 12)
+	pdf.MultiCell(0, 7, buildReceiptParagraph(req), "", "J", false)
+	pdf.Ln(4)
+	pdf.MultiCell(0, 7, "Por ser expressão da verdade, firmo o presente recibo, dando plena quitação do valor acima.", "", "J", false)
+	pdf.Ln(6)
+	pdf.CellFormat(0, 7, buildReceiptPlaceAndDate(req), "", 1, "R", false, 0, "")
+
+	pdf.Ln(22)
+	leftMargin, _, rightMargin, _ := pdf.GetMargins()
+	pageWidth, _ := pdf.GetPageSize()
+	lineWidth := 90.0
+	lineX := leftMargin + (pageWidth-leftMargin-rightMargin-lineWidth)/2
+	pdf.Line(lineX, pdf.GetY(), lineX+lineWidth, pdf.GetY())
+	pdf.Ln(2)
+	pdf.SetFont(documentFontFamily, "", 11)
+	pdf.CellFormat(0, 6, fmt.Sprintf("%s (Recebedor)", req.Payee.Name), "", 1, "C", false, 0, "")
+	if req.Payee.CPF != "" {
+		pdf.CellFormat(0, 6, fmt.Sprintf("CPF: %s", req.Payee.CPF), "", 1, "C", false, 0, "")
+	}
+
+	writeBrandFooter(pdf, brand)
+
+	out, err := s.outputDocument(pdf, req.Sign)
+	if err != nil {
+		return domain.GeneratedDocument{}, err
+	}
+	return s.registerDocument(domain.GeneratedDocument{PDF: out, Template: provenance}, verificationID, "receipt", req.ContractID)
+}
+
+func buildReceiptParagraph(req domain.ReceiptRequest) string {
+	payer := req.Payer.Name
+	if req.Payer.CPF != "" {
+		payer += fmt.Sprintf(", inscrito(a) no CPF sob nº %s", req.Payer.CPF)
+	}
+
+	paragraph := fmt.Sprintf(
+		"Recebi de %s a importância de %s, paga por meio de %s em %s, a título de sinal e princípio de pagamento (arras), referente %s",
+		payer,
+		formatBRLWithWords(req.Amount),
+		req.PaymentMethod,
+		formatISODateForDisplay(req.PaymentDate),
+		buildReceiptReference(req),
+	)
+	if req.PropertyAddress != "" {
+		paragraph += fmt.Sprintf(", tendo por objeto o imóvel situado à %s", req.PropertyAddress)
+	}
+	return paragraph + "."
+}
+
+func buildReceiptReference(req domain.ReceiptRequest) string {
+	var refs []string
+	if req.ProposalID != "" {
+		refs = append(refs, fmt.Sprintf("à proposta nº %s", req.ProposalID))
+	}
+	if req.ContractID != "" {
+		refs = append(refs, fmt.Sprintf("ao contrato nº %s", req.ContractID))
+	}
+	return strings.Join(refs, " e ")
+}
+
+func buildReceiptPlaceAndDate(req domain.ReceiptRequest) string {
+	date := formatISODateForDisplay(req.PaymentDate)
+	if req.City == "" {
+		return date
+	}
+	return fmt.Sprintf("%s, %s.", req.City, date)
+}
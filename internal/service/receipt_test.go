@@ -0,0 +1,79 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"pdf-service/internal/domain"
+)
+
+func TestGenerateReceiptReturnsPDFBytesForValidRequest(t *testing.T) {
+	doc, err := NewPDFService().GenerateReceipt(domain.ReceiptRequest{
+		ProposalID:    "proposal-1",
+		Payer:         domain.ContractParty{Name: "Ana Silva", CPF: "529.982.247-25"},
+		Payee:         domain.ContractParty{Name: "Carlos Souza"},
+		Amount:        25000,
+		PaymentMethod: "PIX",
+		PaymentDate:   "2026-03-15",
+		City:          "Rio Verde",
+	})
+	if err != nil {
+		t.Fatalf("GenerateReceipt() error = %v", err)
+	}
+	if !bytes.HasPrefix(doc.PDF, []byte("%PDF")) {
+		t.Fatalf("expected PDF signature prefix, got %q", doc.PDF[:4])
+	}
+	if !strings.Contains(extractPDFText(doc.PDF), "vinte e cinco mil reais") {
+		t.Fatal("expected receipt to state the amount in words")
+	}
+}
+
+func TestGenerateReceiptRejectsMissingReference(t *testing.T) {
+	_, err := NewPDFService().GenerateReceipt(domain.ReceiptRequest{
+		Payer:         domain.ContractParty{Name: "Ana Silva"},
+		Payee:         domain.ContractParty{Name: "Carlos Souza"},
+		Amount:        25000,
+		PaymentMethod: "PIX",
+		PaymentDate:   "2026-03-15",
+	})
+	if err == nil {
+		t.Fatal("expected receipt without proposal_id or contract_id to be rejected")
+	}
+}
+
+func TestBuildReceiptParagraphStatesAmountAndReference(t *testing.T) {
+	got := buildReceiptParagraph(domain.ReceiptRequest{
+		ContractID:    "contract-9",
+		Payer:         domain.ContractParty{Name: "Ana Silva", CPF: "529.982.247-25"},
+		Amount:        1500.5,
+		PaymentMethod: "transferência bancária",
+		PaymentDate:   "2026-03-15",
+	})
+
+	for _, expected := range []string{
+		"Ana Silva, inscrito(a) no CPF sob nº 529.982.247-25",
+		"R$ 1.500,50 (mil e quinhentos reais e cinquenta centavos)",
+		"em 15/03/2026",
+		"contrato nº contract-9",
+	} {
+		if !strings.Contains(got, expected) {
+			t.Fatalf("expected receipt paragraph to contain %q, got %q", expected, got)
+		}
+	}
+}
+
+func TestBuildReceiptParagraphUsesTheArticleOfEachReference(t *testing.T) {
+	for _, tc := range []struct {
+		req  domain.ReceiptRequest
+		want string
+	}{
+		{domain.ReceiptRequest{ContractID: "contract-9"}, "referente ao contrato nº contract-9."},
+		{domain.ReceiptRequest{ProposalID: "P-1"}, "referente à proposta nº P-1."},
+		{domain.ReceiptRequest{ProposalID: "P-1", ContractID: "contract-9"}, "referente à proposta nº P-1 e ao contrato nº contract-9."},
+	} {
+		if got := buildReceiptParagraph(tc.req); !strings.HasSuffix(got, tc.want) {
+			t.Fatalf("expected receipt paragraph to end with %q, got %q", tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"pdf-service/internal/domain"
+)
+
+// proposalTemplateData is what proposal templates render against.
+type proposalTemplateData struct {
+	ClientName   string
+	Address      string
+	City         string
+	State        string
+	ValidityDays int
+	TotalValue   float64
+	Payment      domain.PaymentValues
+	Rental       domain.RentalTerms
+	Brand        BrandingProfile
+	Draft        bool
+}
+
+// contractTemplateData is what contract templates render against. The
+// registry-style property identification stays in Go (see
+// buildContractObjectClause) and is exposed as ObjectDescription.
+type contractTemplateData struct {
+	PropertyTitle          string
+	PropertyAddress        string
+	Property               domain.ContractProperty
+	ObjectDescription      string
+	SaleValue              float64
+	Payment                domain.PaymentValues
+	HasIndexedInstallments bool
+	Rental                 domain.RentalTerms
+	Brand                  BrandingProfile
+	Draft                  bool
+}
+
+func newProposalTemplateData(req domain.ProposalRequest, brand BrandingProfile) proposalTemplateData {
+	address, city, state := resolveIntroLocation(req)
+	return proposalTemplateData{
+		ClientName:   req.ResolvedClientName(),
+		Address:      address,
+		City:         city,
+		State:        state,
+		ValidityDays: req.ResolvedValidityDays(),
+		TotalValue:   req.ResolvedTotalValue(),
+		Payment:      req.ResolvedPayments(),
+		Rental:       req.ResolvedRentalTerms(),
+		Brand:        brand,
+		Draft:        req.ResolvedDocumentState() == domain.DocumentStateDraft,
+	}
+}
+
+func newContractTemplateData(req domain.ContractRequest, brand BrandingProfile) contractTemplateData {
+	payment := req.ResolvedSalePayments()
+	indexed := false
+	for _, installment := range payment.Installments {
+		if installment.Index != "" {
+			indexed = true
+			break
+		}
+	}
+	return contractTemplateData{
+		PropertyTitle:          req.PropertyTitle,
+		PropertyAddress:        req.ResolvedPropertyAddress(),
+		Property:               req.Property,
+		ObjectDescription:      buildContractObjectClause(req),
+		SaleValue:              req.ResolvedSaleValue(),
+		Payment:                payment,
+		HasIndexedInstallments: indexed,
+		Rental:                 req.RentalTerms,
+		Brand:                  brand,
+		Draft:                  req.ResolvedDocumentState() == domain.DocumentStateDraft,
+	}
+}
+
+// renderedBlock is a template block whose texts and conditions have been
+// evaluated, ready to be drawn.
+type renderedBlock struct {
+	*templateBlock
+	text    string
+	items   []string
+	clauses []numberedClause
+}
+
+// documentContent carries what non-textual blocks draw besides the
+// rendered texts.
+type documentContent struct {
+	brand        BrandingProfile
+	installments []domain.Installment
+	sellers      []domain.ContractParty
+	buyers       []domain.ContractParty
+}
+
+// renderedTitle is the text of the first title block, which names the
+// document in its running header.
+func renderedTitle(blocks []renderedBlock) string {
+	for _, block := range blocks {
+		if block.Type == blockTitle {
+			return block.text
+		}
+	}
+	return ""
+}
+
+// render evaluates the template against data, dropping the blocks, items
+// and clauses whose conditions are empty.
+func (t *DocumentTemplate) render(data any) ([]renderedBlock, error) {
+	blocks := make([]renderedBlock, 0, len(t.Blocks))
+	for i := range t.Blocks {
+		block := &t.Blocks[i]
+		include, err := evaluateCondition(block.condition, data)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", t.ID, err)
+		}
+		if !include {
+			continue
+		}
+
+		rendered := renderedBlock{templateBlock: block}
+		if rendered.text, err = executeTemplateText(block.text, data); err != nil {
+			return nil, fmt.Errorf("template %s: %w", t.ID, err)
+		}
+		if rendered.items, err = renderTemplateTexts(block.Items, data); err != nil {
+			return nil, fmt.Errorf("template %s: %w", t.ID, err)
+		}
+		if len(block.Clauses) > 0 {
+			clauses, err := renderTemplateClauses(block.Clauses, data)
+			if err != nil {
+				return nil, fmt.Errorf("template %s: %w", t.ID, err)
+			}
+			rendered.clauses = clauses.numbered()
+		}
+		blocks = append(blocks, rendered)
+	}
+	return blocks, nil
+}
+
+func renderTemplateClauses(clauses []templateClause, data any) (*clauseSet, error) {
+	set := &clauseSet{}
+	for _, c := range clauses {
+		include, err := evaluateCondition(c.condition, data)
+		if err != nil {
+			return nil, err
+		}
+		rendered := clause{title: c.Title}
+		if rendered.caput, err = executeTemplateText(c.caput, data); err != nil {
+			return nil, err
+		}
+		if rendered.items, err = renderTemplateTexts(c.Items, data); err != nil {
+			return nil, err
+		}
+		if rendered.paragraphs, err = renderTemplateTexts(c.Paragraphs, data); err != nil {
+			return nil, err
+		}
+		// Items are written as a ";"-separated enumeration that closes with
+		// a period, whichever of them made it into the clause.
+		if n := len(rendered.items); n > 0 {
+			rendered.items[n-1] = strings.TrimSuffix(rendered.items[n-1], ";") + "."
+		}
+		set.addIf(include, rendered)
+	}
+	return set, nil
+}
+
+func renderTemplateTexts(texts []templateText, data any) ([]string, error) {
+	rendered := make([]string, 0, len(texts))
+	for _, text := range texts {
+		include, err := evaluateCondition(text.condition, data)
+		if err != nil {
+			return nil, err
+		}
+		if !include {
+			continue
+		}
+		value, err := executeTemplateText(text.text, data)
+		if err != nil {
+			return nil, err
+		}
+		if value != "" {
+			rendered = append(rendered, value)
+		}
+	}
+	return rendered, nil
+}
+
+func evaluateCondition(condition *template.Template, data any) (bool, error) {
+	if condition == nil {
+		return true, nil
+	}
+	value, err := executeTemplateText(condition, data)
+	return value != "", err
+}
+
+func executeTemplateText(tpl *template.Template, data any) (string, error) {
+	if tpl == nil {
+		return "", nil
+	}
+	var out strings.Builder
+	if err := tpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// drawBlocks lays the rendered blocks out on the document.
+func drawBlocks(pdf *gofpdf.Fpdf, blocks []renderedBlock, content documentContent) {
+	for _, block := range blocks {
+		if block.SpaceBefore > 0 {
+			pdf.Ln(block.SpaceBefore)
+		}
+		switch block.Type {
+		case blockTitle:
+			block.setFont(pdf, "B", 16)
+			color := content.brand.PrimaryColor
+			pdf.SetTextColor(color.R, color.G, color.B)
+			pdf.CellFormat(0, block.lineHeight(10), block.text, "", 1, block.align("C"), false, 0, "")
+			pdf.SetTextColor(0, 0, 0)
+		case blockText:
+			block.setFont(pdf, "", 11)
+			pdf.CellFormat(0, block.lineHeight(7), block.text, "", 1, block.align("L"), false, 0, "")
+		case blockParagraph:
+			block.setFont(pdf, "", 11)
+			pdf.MultiCell(0, block.lineHeight(6), block.text, "", block.align("J"), false)
+		case blockList:
+			block.setFont(pdf, "", 11)
+			for _, item := range block.items {
+				pdf.MultiCell(0, block.lineHeight(6), item, "", block.align("L"), false)
+			}
+		case blockClauses:
+			writeClauses(pdf, block.clauses)
+		case blockParties:
+			parties := content.sellers
+			if block.Parties == "buyers" {
+				parties = content.buyers
+			}
+			writeContractParties(pdf, contractRole{block.Label, fallback(block.PluralLabel, block.Label)}, parties)
+		case blockInstallmentSchedule:
+			if len(content.installments) == 0 {
+				break
+			}
+			if block.text != "" {
+				block.setFont(pdf, "B", 11)
+				pdf.CellFormat(0, block.lineHeight(7), block.text, "", 1, block.align("L"), false, 0, "")
+			}
+			writeInstallmentTable(pdf, content.installments, content.brand.AccentColor)
+		case blockSignaturePair:
+			block.setFont(pdf, "", 11)
+			labels := append(block.items, "", "")
+			writeSignaturePair(pdf, labels[0], labels[1])
+		case blockPartySignatures:
+			signers := append(
+				buildContractSigners(block.Labels["sellers"], content.sellers),
+				buildContractSigners(block.Labels["buyers"], content.buyers)...,
+			)
+			for i := range signers {
+				if signers[i].role == spouseSignerRole && block.Labels["spouse"] != "" {
+					signers[i].role = block.Labels["spouse"]
+				}
+			}
+			writeContractSignatures(pdf, signers)
+		case blockBrandFooter:
+			writeBrandFooter(pdf, content.brand)
+		}
+		if block.SpaceAfter > 0 {
+			pdf.Ln(block.SpaceAfter)
+		}
+	}
+}
+
+func (b renderedBlock) setFont(pdf *gofpdf.Fpdf, defaultStyle string, defaultSize float64) {
+	style := b.Style
+	if style == "" {
+		style = defaultStyle
+	}
+	size := b.FontSize
+	if size == 0 {
+		size = defaultSize
+	}
+	pdf.SetFont(documentFontFamily, style, size)
+}
+
+func (b renderedBlock) lineHeight(defaultHeight float64) float64 {
+	if b.LineHeight > 0 {
+		return b.LineHeight
+	}
+	return defaultHeight
+}
+
+func (b renderedBlock) align(defaultAlign string) string {
+	return fallback(b.Align, defaultAlign)
+}
+
+// writeSignaturePair draws two signature lines side by side, as closing
+// the proposal between the proponent and the agency.
+func writeSignaturePair(pdf *gofpdf.Fpdf, leftLabel, rightLabel string) {
+	currentY := pdf.GetY()
+	if currentY > 240 {
+		pdf.AddPage()
+		currentY = 40
+	}
+
+	signatureY := currentY + 24
+	leftMargin, _, rightMargin, _ := pdf.GetMargins()
+	pageWidth, _ := pdf.GetPageSize()
+	contentWidth := pageWidth - leftMargin - rightMargin
+	gap := 18.0
+	lineWidth := (contentWidth - gap) / 2
+	leftX := leftMargin
+	rightX := leftX + lineWidth + gap
+
+	pdf.Line(leftX, signatureY, leftX+lineWidth, signatureY)
+	pdf.Line(rightX, signatureY, rightX+lineWidth, signatureY)
+
+	pdf.SetXY(leftX, signatureY+2)
+	pdf.CellFormat(lineWidth, 6, leftLabel, "", 0, "C", false, 0, "")
+	pdf.SetXY(rightX, signatureY+2)
+	pdf.CellFormat(lineWidth, 6, rightLabel, "", 0, "C", false, 0, "")
+}
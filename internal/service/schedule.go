@@ -0,0 +1,71 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"pdf-service/internal/domain"
+)
+
+// buildInstallmentSummary describes the schedule in one sentence for the
+// payment terms; the detail goes in the table drawn by writeInstallmentTable.
+func buildInstallmentSummary(installments []domain.Installment) string {
+	total := 0.0
+	for _, installment := range installments {
+		total += installment.Amount
+	}
+	return fmt.Sprintf(
+		"%s, totalizando %s, conforme cronograma de pagamento",
+		formatCountWithWords(len(installments), feminine, "parcela", "parcelas"),
+		formatBRLWithWords(total),
+	)
+}
+
+// writeInstallmentTable draws the payment schedule, repeating the header
+// row whenever the table continues on a new page.
+func writeInstallmentTable(pdf *gofpdf.Fpdf, installments []domain.Installment, headerFill RGB) {
+	widths := []float64{12, 28, 62, 40, 28}
+	headers := []string{"Nº", "Vencimento", "Descrição", "Valor", "Correção"}
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottomMargin := pdf.GetMargins()
+	rowHeight := 7.0
+
+	writeHeader := func() {
+		pdf.SetFont(documentFontFamily, "B", 10)
+		pdf.SetFillColor(headerFill.R, headerFill.G, headerFill.B)
+		for i, header := range headers {
+			pdf.CellFormat(widths[i], rowHeight, header, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont(documentFontFamily, "", 10)
+	}
+
+	writeHeader()
+	total := 0.0
+	for i, installment := range installments {
+		if pdf.GetY()+rowHeight > pageHeight-bottomMargin {
+			pdf.AddPage()
+			writeHeader()
+		}
+		total += installment.Amount
+		pdf.CellFormat(widths[0], rowHeight, fmt.Sprintf("%d", i+1), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(widths[1], rowHeight, formatISODateForDisplay(installment.DueDate), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(widths[2], rowHeight, truncateRunes(fallback(installment.Description, "Parcela"), 34), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[3], rowHeight, formatBRL(installment.Amount), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], rowHeight, fallback(installment.Index, "Sem correção"), "1", 1, "C", false, 0, "")
+	}
+
+	pdf.SetFont(documentFontFamily, "B", 10)
+	pdf.CellFormat(widths[0]+widths[1]+widths[2], rowHeight, "Total das parcelas", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[3], rowHeight, formatBRL(total), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[4], rowHeight, "", "1", 1, "C", false, 0, "")
+}
+
+func truncateRunes(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit-1]) + "…"
+}
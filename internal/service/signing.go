@@ -0,0 +1,27 @@
+package service
+
+import (
+	"pdf-service/internal/domain"
+	"pdf-service/internal/signing"
+)
+
+// WithSigner lets requests ask for a digitally signed document. Without a
+// signer such requests are rejected.
+func WithSigner(signer *signing.Signer) Option {
+	return func(s *PDFService) {
+		s.signer = signer
+	}
+}
+
+// checkSigning rejects a request to sign before anything is rendered when
+// no certificate is configured.
+func (s *PDFService) checkSigning(sign bool) error {
+	if sign && s.signer == nil {
+		return domain.ValidationErrors{{
+			Path:    "/sign",
+			Code:    domain.CodeNotApplicable,
+			Message: "Assinatura digital não está habilitada neste servidor.",
+		}}
+	}
+	return nil
+}
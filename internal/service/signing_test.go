@@ -0,0 +1,96 @@
+package service
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"pdf-service/internal/domain"
+	"pdf-service/internal/signing"
+)
+
+func newTestSigner(t *testing.T) *signing.Signer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Imobiliária Teste Ltda"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+	signer, err := signing.NewSigner(key, []*x509.Certificate{cert}, signing.WithReason("Emissão de documento"))
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	return signer
+}
+
+func TestGenerateReceiptSignsOnRequest(t *testing.T) {
+	s := NewPDFService(WithSigner(newTestSigner(t)))
+	req := domain.ReceiptRequest{
+		ProposalID:    "proposal-1",
+		Payer:         domain.ContractParty{Name: "Ana Silva"},
+		Payee:         domain.ContractParty{Name: "Carlos Souza"},
+		Amount:        25000,
+		PaymentMethod: "PIX",
+		PaymentDate:   "2026-03-15",
+		IssueDate:     "2026-03-15",
+	}
+
+	unsigned, err := s.GenerateReceipt(req)
+	if err != nil {
+		t.Fatalf("GenerateReceipt() error = %v", err)
+	}
+	if _, err := signing.Verify(unsigned.PDF); !errors.Is(err, signing.ErrNotSigned) {
+		t.Fatalf("expected an unsigned receipt by default, got %v", err)
+	}
+
+	req.Sign = true
+	signed, err := s.GenerateReceipt(req)
+	if err != nil {
+		t.Fatalf("GenerateReceipt() error = %v", err)
+	}
+	if !bytes.HasPrefix(signed.PDF, unsigned.PDF) {
+		t.Fatal("expected the signature to be an incremental update of the rendered receipt")
+	}
+	certs, err := signing.Verify(signed.PDF)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if certs[0].Subject.CommonName != "Imobiliária Teste Ltda" {
+		t.Fatalf("expected the configured certificate, got %s", certs[0].Subject)
+	}
+}
+
+func TestGenerateProposalRejectsSignWithoutCertificate(t *testing.T) {
+	_, err := NewPDFService().GenerateProposal(domain.ProposalRequest{
+		ClientName:            "Ana Silva",
+		PropertyAddressLegacy: "Rua A, 10, Centro, Goiânia, GO",
+		TotalValue:            150000,
+		Payment:               domain.PaymentBreakdown{Cash: 150000},
+		Sign:                  true,
+	})
+	var fieldErrs domain.ValidationErrors
+	if !errors.As(err, &fieldErrs) || fieldErrs[0].Path != "/sign" || fieldErrs[0].Code != domain.CodeNotApplicable {
+		t.Fatalf("expected a /sign not_applicable error, got %v", err)
+	}
+}
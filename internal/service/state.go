@@ -0,0 +1,74 @@
+package service
+
+import (
+	"math"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const draftWatermarkText = "MINUTA – SEM VALOR JURÍDICO"
+
+// initialsBoxSize is the side of each initials box in millimetres; the
+// boxes stack up the right margin from the bottom one.
+const (
+	initialsBoxSize = 12.0
+	initialsBoxGap  = 2.0
+)
+
+// withDocumentState marks every page as its state requires: drafts get a
+// diagonal watermark and documents for signature one initials (rubrica)
+// box per signer in the right margin. Final documents get neither.
+func withDocumentState(state string, signers int) documentOption {
+	return func(c *pageChrome) {
+		c.state = state
+		c.signers = signers
+	}
+}
+
+// writeDraftWatermark draws the watermark across the page diagonal. It is
+// drawn before the page content, and translucent, so the text stays
+// readable over it.
+func writeDraftWatermark(pdf *gofpdf.Fpdf) {
+	pageWidth, pageHeight := pdf.GetPageSize()
+	centerX, centerY := pageWidth/2, pageHeight/2
+	diagonal := math.Hypot(pageWidth, pageHeight)
+	angle := math.Atan2(pageHeight, pageWidth) * 180 / math.Pi
+
+	pdf.SetFont(documentFontFamily, "B", 10)
+	fontSize := 10 * 0.75 * diagonal / pdf.GetStringWidth(draftWatermarkText)
+	pdf.SetFont(documentFontFamily, "B", fontSize)
+	width := pdf.GetStringWidth(draftWatermarkText)
+	_, lineHeight := pdf.GetFontSize()
+
+	pdf.TransformBegin()
+	pdf.TransformRotate(angle, centerX, centerY)
+	pdf.SetAlpha(0.15, "Normal")
+	pdf.SetTextColor(180, 0, 0)
+	pdf.Text(centerX-width/2, centerY+lineHeight/3, draftWatermarkText)
+	pdf.SetAlpha(1, "Normal")
+	pdf.TransformEnd()
+}
+
+// writeInitialsBoxes draws one box per signer in the right margin, as many
+// as fit between the top and bottom margins.
+func writeInitialsBoxes(pdf *gofpdf.Fpdf, signers int) {
+	_, topMargin, rightMargin, bottomMargin := pdf.GetMargins()
+	pageWidth, pageHeight := pdf.GetPageSize()
+	x := pageWidth - rightMargin + (rightMargin-initialsBoxSize)/2
+	bottom := pageHeight - bottomMargin
+	fits := int((bottom - topMargin - 4) / (initialsBoxSize + initialsBoxGap))
+	boxes := max(1, min(signers, fits))
+
+	pdf.SetDrawColor(150, 150, 150)
+	pdf.SetLineWidth(0.2)
+	top := bottom
+	for range boxes {
+		top -= initialsBoxSize
+		pdf.Rect(x, top, initialsBoxSize, initialsBoxSize, "D")
+		top -= initialsBoxGap
+	}
+	pdf.SetFont(documentFontFamily, "", 5)
+	pdf.SetTextColor(128, 128, 128)
+	pdf.SetXY(x, top-1)
+	pdf.CellFormat(initialsBoxSize, 3, "Rubricas", "", 0, "C", false, 0, "")
+}
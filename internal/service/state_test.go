@@ -0,0 +1,105 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"pdf-service/internal/domain"
+)
+
+// stateContractRequest is a contract long enough, with its installment
+// schedule, to run over several pages.
+func stateContractRequest(state string) domain.ContractRequest {
+	installments := make([]domain.Installment, 48)
+	for i := range installments {
+		installments[i] = domain.Installment{DueDate: fmt.Sprintf("%d-%02d-10", 2027+i/12, i%12+1), Amount: 1000}
+	}
+	return domain.ContractRequest{
+		ContractID:      "contract-1",
+		DealType:        "sale",
+		PropertyTitle:   "Casa de teste",
+		PropertyAddress: "Rua A, 10, Goiânia, GO",
+		Sellers:         []domain.ContractParty{{Name: "Carlos Souza"}},
+		Buyers:          []domain.ContractParty{{Name: "Ana Silva"}, {Name: "Bruno Lima"}},
+		SaleTerms:       domain.PaymentBreakdown{Cash: 52000, Installments: installments},
+		DocumentState:   state,
+	}
+}
+
+func TestGenerateContractMarksEveryPageByState(t *testing.T) {
+	for _, tc := range []struct {
+		state     string
+		watermark bool
+		initials  bool
+		title     string
+	}{
+		{state: "", watermark: true, title: "MINUTA DE CONTRATO DE COMPRA E VENDA"},
+		{state: domain.DocumentStateDraft, watermark: true, title: "MINUTA DE CONTRATO DE COMPRA E VENDA"},
+		{state: domain.DocumentStateForSignature, initials: true, title: "CONTRATO DE COMPRA E VENDA"},
+		{state: domain.DocumentStateFinal, title: "CONTRATO DE COMPRA E VENDA"},
+	} {
+		t.Run(tc.state, func(t *testing.T) {
+			doc, err := NewPDFService().GenerateContract(stateContractRequest(tc.state))
+			if err != nil {
+				t.Fatalf("GenerateContract() error = %v", err)
+			}
+			text := extractPDFText(doc.PDF)
+			pages := bytes.Count(doc.PDF, []byte("/Type /Page\n"))
+			if pages < 2 {
+				t.Fatalf("expected a multi-page contract, got %d pages", pages)
+			}
+
+			if got := strings.Count(text, "MINUTA – SEM VALOR JURÍDICO"); tc.watermark != (got == pages) || (!tc.watermark && got != 0) {
+				t.Fatalf("expected watermark on every page: %v, got %d on %d pages", tc.watermark, got, pages)
+			}
+			if got := strings.Count(text, "Rubricas"); tc.initials != (got == pages) || (!tc.initials && got != 0) {
+				t.Fatalf("expected initials boxes on every page: %v, got %d on %d pages", tc.initials, got, pages)
+			}
+			if !strings.Contains(text, tc.title) || (tc.title != "MINUTA DE CONTRATO DE COMPRA E VENDA" && strings.Contains(text, "MINUTA")) {
+				t.Fatalf("expected the title %q and no draft wording, got %q", tc.title, text)
+			}
+		})
+	}
+}
+
+func TestWithDocumentStateDrawsOneInitialsBoxPerSigner(t *testing.T) {
+	doc, err := NewPDFService().GenerateContract(stateContractRequest(domain.DocumentStateForSignature))
+	if err != nil {
+		t.Fatalf("GenerateContract() error = %v", err)
+	}
+	pages := bytes.Count(doc.PDF, []byte("/Type /Page\n"))
+	// 12 mm boxes are 34.02 pt wide; one seller and two buyers sign.
+	if got := bytes.Count(doc.PDF, []byte(" 34.02 -34.02 re S")); got != 3*pages {
+		t.Fatalf("expected 3 initials boxes on each of %d pages, got %d", pages, got)
+	}
+}
+
+func TestGenerateProposalIsFinalByDefault(t *testing.T) {
+	req := domain.ProposalRequest{
+		ClientName:            "Ana Silva",
+		PropertyAddressLegacy: "Rua A, 10, Centro, Goiânia, GO",
+		TotalValue:            150000,
+		Payment:               domain.PaymentBreakdown{Cash: 150000},
+	}
+	doc, err := NewPDFService().GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	if strings.Contains(extractPDFText(doc.PDF), "MINUTA") {
+		t.Fatal("expected no watermark on a proposal without a state")
+	}
+
+	req.DocumentState = domain.DocumentStateDraft
+	doc, err = NewPDFService().GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	if !strings.Contains(extractPDFText(doc.PDF), "MINUTA – SEM VALOR JURÍDICO") {
+		t.Fatal("expected the draft watermark on a draft proposal")
+	}
+	if !bytes.Contains(doc.PDF, []byte("/ca 0.15")) {
+		t.Fatal("expected the watermark to be translucent")
+	}
+}
@@ -0,0 +1,490 @@
+package service
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+
+	"pdf-service/internal/domain"
+)
+
+//go:embed assets/templates/*.json
+var defaultTemplateFiles embed.FS
+
+// templateIDPattern keeps IDs safe to echo in response headers and PDF
+// metadata.
+var templateIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// Template documents and the block types their layouts are made of.
+const (
+	templateDocumentProposal = "proposal"
+	templateDocumentContract = "contract"
+
+	blockTitle               = "title"
+	blockText                = "text"
+	blockParagraph           = "paragraph"
+	blockList                = "list"
+	blockClauses             = "clauses"
+	blockParties             = "parties"
+	blockInstallmentSchedule = "installment_schedule"
+	blockSignaturePair       = "signature_pair"
+	blockPartySignatures     = "party_signatures"
+	blockBrandFooter         = "brand_footer"
+)
+
+// DocumentTemplate describes the layout and wording of one document type
+// (proposal or contract, sale or rent). Texts are text/template strings
+// rendered against the document data; "if" fields are template pipelines
+// that drop the block, item or clause when they evaluate to an empty value.
+// PageChrome set to false leaves out the running header and page numbers,
+// for layouts printed on stationery that already carries them.
+type DocumentTemplate struct {
+	ID         string          `json:"id"`
+	Version    string          `json:"version"`
+	Document   string          `json:"document"`
+	DealType   string          `json:"deal_type"`
+	PageChrome *bool           `json:"page_chrome,omitempty"`
+	Blocks     []templateBlock `json:"blocks"`
+
+	hash string
+}
+
+type templateBlock struct {
+	Type        string            `json:"type"`
+	If          string            `json:"if,omitempty"`
+	Text        string            `json:"text,omitempty"`
+	Items       []templateText    `json:"items,omitempty"`
+	Clauses     []templateClause  `json:"clauses,omitempty"`
+	Parties     string            `json:"parties,omitempty"`
+	Label       string            `json:"label,omitempty"`
+	PluralLabel string            `json:"plural_label,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Style       string            `json:"style,omitempty"`
+	FontSize    float64           `json:"font_size,omitempty"`
+	LineHeight  float64           `json:"line_height,omitempty"`
+	Align       string            `json:"align,omitempty"`
+	SpaceBefore float64           `json:"space_before,omitempty"`
+	SpaceAfter  float64           `json:"space_after,omitempty"`
+
+	condition *template.Template
+	text      *template.Template
+}
+
+type templateText struct {
+	Text string `json:"text"`
+	If   string `json:"if,omitempty"`
+
+	condition *template.Template
+	text      *template.Template
+}
+
+type templateClause struct {
+	Title      string         `json:"title"`
+	If         string         `json:"if,omitempty"`
+	Caput      string         `json:"caput,omitempty"`
+	Items      []templateText `json:"items,omitempty"`
+	Paragraphs []templateText `json:"paragraphs,omitempty"`
+
+	condition *template.Template
+	caput     *template.Template
+}
+
+// templateFuncs are the helpers available to template texts.
+var templateFuncs = template.FuncMap{
+	"brl":          formatBRLWithWords,
+	"money":        formatBRL,
+	"date":         formatISODateForDisplay,
+	"upper":        strings.ToUpper,
+	"lower":        strings.ToLower,
+	"installments": buildInstallmentSummary,
+	"sentence": func(value string) string {
+		return strings.TrimSuffix(strings.TrimSpace(value), ".") + "."
+	},
+	"count": func(n int, singular, plural string) string {
+		return formatCountWithWords(n, masculine, singular, plural)
+	},
+	"countFeminine": func(n int, singular, plural string) string {
+		return formatCountWithWords(n, feminine, singular, plural)
+	},
+}
+
+// templateDataTypes is the data each document renders against, used to
+// check field references when a template is loaded.
+var templateDataTypes = map[string]reflect.Type{
+	templateDocumentProposal: reflect.TypeOf(proposalTemplateData{}),
+	templateDocumentContract: reflect.TypeOf(contractTemplateData{}),
+}
+
+// ParseDocumentTemplate decodes and validates a template file, compiling
+// every text and condition it contains.
+func ParseDocumentTemplate(data []byte) (*DocumentTemplate, error) {
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	var tpl DocumentTemplate
+	if err := decoder.Decode(&tpl); err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	if err := tpl.compile(); err != nil {
+		if tpl.ID != "" {
+			return nil, fmt.Errorf("template %s: %w", tpl.ID, err)
+		}
+		return nil, fmt.Errorf("template: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	tpl.hash = hex.EncodeToString(sum[:])
+	return &tpl, nil
+}
+
+// hasPageChrome reports whether documents rendered from the template carry
+// the running header and page numbers; they do unless the template opts out.
+func (t *DocumentTemplate) hasPageChrome() bool {
+	return t.PageChrome == nil || *t.PageChrome
+}
+
+// Provenance identifies this template revision on the documents it renders.
+func (t *DocumentTemplate) Provenance() domain.TemplateProvenance {
+	return domain.TemplateProvenance{ID: t.ID, Version: t.Version, Hash: t.hash}
+}
+
+// LoadDocumentTemplates parses every .json file in dir, in file name order.
+func LoadDocumentTemplates(dir string) ([]*DocumentTemplate, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("list templates: %w", err)
+	}
+	sort.Strings(paths)
+
+	templates := make([]*DocumentTemplate, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path) // #nosec G304 -- dir comes from operator configuration
+		if err != nil {
+			return nil, fmt.Errorf("read template: %w", err)
+		}
+		tpl, err := ParseDocumentTemplate(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		templates = append(templates, tpl)
+	}
+	return templates, nil
+}
+
+// defaultDocumentTemplates returns the templates shipped with the service.
+// They are covered by tests, so a parse failure is a programming error.
+// Compiled templates are immutable and shared by every service.
+var defaultDocumentTemplates = sync.OnceValue(func() []*DocumentTemplate {
+	entries, err := defaultTemplateFiles.ReadDir("assets/templates")
+	if err != nil {
+		panic(err)
+	}
+	templates := make([]*DocumentTemplate, 0, len(entries))
+	for _, entry := range entries {
+		data, err := defaultTemplateFiles.ReadFile("assets/templates/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		tpl, err := ParseDocumentTemplate(data)
+		if err != nil {
+			panic(fmt.Sprintf("%s: %v", entry.Name(), err))
+		}
+		templates = append(templates, tpl)
+	}
+	return templates
+})
+
+func documentTemplateKey(document, dealType string) string {
+	return document + "/" + dealType
+}
+
+func (t *DocumentTemplate) key() string {
+	return documentTemplateKey(t.Document, t.DealType)
+}
+
+// registerTemplate adds a template revision, replacing a registered one
+// with the same version, and keeps the revisions ordered newest first.
+func (s *PDFService) registerTemplate(tpl *DocumentTemplate) {
+	key := tpl.key()
+	revisions := s.templates[key][:0:0]
+	for _, registered := range s.templates[key] {
+		if registered.Version != tpl.Version {
+			revisions = append(revisions, registered)
+		}
+	}
+	revisions = append(revisions, tpl)
+	sort.Slice(revisions, func(i, j int) bool {
+		return domain.CompareSemanticVersions(revisions[i].Version, revisions[j].Version) > 0
+	})
+	s.templates[key] = revisions
+}
+
+// documentTemplate returns the template registered for a document and deal
+// type: the pinned version when one is given, the newest otherwise.
+func (s *PDFService) documentTemplate(document, dealType, version string) (*DocumentTemplate, error) {
+	revisions := s.templates[documentTemplateKey(document, dealType)]
+	if len(revisions) == 0 {
+		return nil, fmt.Errorf("no template registered for %s %s", document, dealType)
+	}
+	if version == "" {
+		return revisions[0], nil
+	}
+	for _, tpl := range revisions {
+		if tpl.Version == version {
+			return tpl, nil
+		}
+	}
+	return nil, domain.UnknownTemplateVersionError(document + " " + dealType + " " + version)
+}
+
+func (t *DocumentTemplate) compile() error {
+	t.ID = strings.TrimSpace(t.ID)
+	switch {
+	case t.ID == "":
+		return errors.New("id is required")
+	case !templateIDPattern.MatchString(t.ID):
+		return errors.New("id must use lowercase letters, digits, dots, dashes or underscores")
+	case !domain.IsSemanticVersion(t.Version):
+		return errors.New("version must be a semantic version such as 1.0.0")
+	case t.DealType != "sale" && t.DealType != "rent":
+		return errors.New("deal_type must be sale or rent")
+	case len(t.Blocks) == 0:
+		return errors.New("blocks must not be empty")
+	}
+	dataType, ok := templateDataTypes[t.Document]
+	if !ok {
+		return errors.New("document must be proposal or contract")
+	}
+	c := templateCompiler{dataType: dataType}
+
+	for i := range t.Blocks {
+		block := &t.Blocks[i]
+		field := fmt.Sprintf("blocks[%d]", i)
+		if err := c.validateBlock(t.Document, field, block); err != nil {
+			return err
+		}
+
+		var err error
+		if block.condition, err = c.condition(field+".if", block.If); err != nil {
+			return err
+		}
+		if block.text, err = c.text(field+".text", block.Text); err != nil {
+			return err
+		}
+		if err := c.texts(field+".items", block.Items); err != nil {
+			return err
+		}
+		for j := range block.Clauses {
+			clauseField := fmt.Sprintf("%s.clauses[%d]", field, j)
+			clause := &block.Clauses[j]
+			if strings.TrimSpace(clause.Title) == "" {
+				return fmt.Errorf("%s.title is required", clauseField)
+			}
+			if clause.condition, err = c.condition(clauseField+".if", clause.If); err != nil {
+				return err
+			}
+			if clause.caput, err = c.text(clauseField+".caput", clause.Caput); err != nil {
+				return err
+			}
+			if err := c.texts(clauseField+".items", clause.Items); err != nil {
+				return err
+			}
+			if err := c.texts(clauseField+".paragraphs", clause.Paragraphs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c templateCompiler) validateBlock(document, field string, block *templateBlock) error {
+	switch block.Type {
+	case blockTitle, blockText, blockParagraph:
+		if strings.TrimSpace(block.Text) == "" {
+			return fmt.Errorf("%s.text is required for %s blocks", field, block.Type)
+		}
+	case blockList:
+		if len(block.Items) == 0 {
+			return fmt.Errorf("%s.items is required for list blocks", field)
+		}
+	case blockClauses:
+		if len(block.Clauses) == 0 {
+			return fmt.Errorf("%s.clauses is required for clauses blocks", field)
+		}
+	case blockSignaturePair:
+		if len(block.Items) != 2 {
+			return fmt.Errorf("%s.items must hold exactly two signature labels", field)
+		}
+	case blockParties:
+		if block.Parties != "sellers" && block.Parties != "buyers" {
+			return fmt.Errorf("%s.parties must be sellers or buyers", field)
+		}
+		if block.Label == "" {
+			return fmt.Errorf("%s.label is required for parties blocks", field)
+		}
+	case blockPartySignatures:
+		for _, key := range []string{"sellers", "buyers"} {
+			if block.Labels[key] == "" {
+				return fmt.Errorf("%s.labels.%s is required for party_signatures blocks", field, key)
+			}
+		}
+	case blockInstallmentSchedule, blockBrandFooter:
+	default:
+		return fmt.Errorf("%s.type %q is not supported", field, block.Type)
+	}
+	if (block.Type == blockParties || block.Type == blockPartySignatures) && document != templateDocumentContract {
+		return fmt.Errorf("%s.type %q is only available in contracts", field, block.Type)
+	}
+	switch block.Style {
+	case "", "B", "I", "BI":
+	default:
+		return fmt.Errorf("%s.style must be one of B, I or BI", field)
+	}
+	switch block.Align {
+	case "", "L", "C", "R", "J":
+	default:
+		return fmt.Errorf("%s.align must be one of L, C, R or J", field)
+	}
+	if block.FontSize < 0 || block.LineHeight < 0 || block.SpaceBefore < 0 || block.SpaceAfter < 0 {
+		return fmt.Errorf("%s sizes and spacing must not be negative", field)
+	}
+	return nil
+}
+
+// templateCompiler parses template texts and checks that every field they
+// reference exists on the document data.
+type templateCompiler struct {
+	dataType reflect.Type
+}
+
+func (c templateCompiler) text(field, source string) (*template.Template, error) {
+	if source == "" {
+		return nil, nil
+	}
+	tpl, err := template.New(field).Funcs(templateFuncs).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", field, err)
+	}
+	if err := c.checkFields(tpl.Tree.Root, true); err != nil {
+		return nil, fmt.Errorf("%s: %w", field, err)
+	}
+	return tpl, nil
+}
+
+// condition compiles an "if" pipeline into a template that renders a
+// non-empty string only when the pipeline is truthy.
+func (c templateCompiler) condition(field, source string) (*template.Template, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, nil
+	}
+	return c.text(field, "{{if "+source+"}}1{{end}}")
+}
+
+func (c templateCompiler) texts(field string, texts []templateText) error {
+	for i := range texts {
+		itemField := fmt.Sprintf("%s[%d]", field, i)
+		var err error
+		if strings.TrimSpace(texts[i].Text) == "" {
+			return fmt.Errorf("%s.text is required", itemField)
+		}
+		if texts[i].condition, err = c.condition(itemField+".if", texts[i].If); err != nil {
+			return err
+		}
+		if texts[i].text, err = c.text(itemField+".text", texts[i].Text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkFields walks the parse tree looking for field chains on the root
+// data. Inside range and with blocks dot is rebound, so only $-rooted
+// references are checked there.
+func (c templateCompiler) checkFields(node parse.Node, dotIsRoot bool) error {
+	if node == nil || reflect.ValueOf(node).IsNil() {
+		return nil
+	}
+	switch n := node.(type) {
+	case *parse.ListNode:
+		for _, child := range n.Nodes {
+			if err := c.checkFields(child, dotIsRoot); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return c.checkFields(n.Pipe, dotIsRoot)
+	case *parse.PipeNode:
+		for _, command := range n.Cmds {
+			for _, arg := range command.Args {
+				if err := c.checkFields(arg, dotIsRoot); err != nil {
+					return err
+				}
+			}
+		}
+	case *parse.IfNode:
+		return c.checkBranch(&n.BranchNode, dotIsRoot, dotIsRoot)
+	case *parse.RangeNode:
+		return c.checkBranch(&n.BranchNode, dotIsRoot, false)
+	case *parse.WithNode:
+		return c.checkBranch(&n.BranchNode, dotIsRoot, false)
+	case *parse.FieldNode:
+		if dotIsRoot {
+			return c.checkFieldChain(n.Ident)
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			return c.checkFieldChain(n.Ident[1:])
+		}
+	case *parse.ChainNode:
+		return c.checkFields(n.Node, dotIsRoot)
+	}
+	return nil
+}
+
+func (c templateCompiler) checkBranch(branch *parse.BranchNode, dotIsRoot, bodyDotIsRoot bool) error {
+	if err := c.checkFields(branch.Pipe, dotIsRoot); err != nil {
+		return err
+	}
+	if err := c.checkFields(branch.List, bodyDotIsRoot); err != nil {
+		return err
+	}
+	return c.checkFields(branch.ElseList, dotIsRoot)
+}
+
+func (c templateCompiler) checkFieldChain(idents []string) error {
+	current := c.dataType
+	for i, ident := range idents {
+		if method, ok := reflect.PointerTo(current).MethodByName(ident); ok {
+			if method.Type.NumOut() == 0 {
+				return fmt.Errorf("%s returns no value", strings.Join(idents[:i+1], "."))
+			}
+			current = method.Type.Out(0)
+		} else {
+			if current.Kind() != reflect.Struct {
+				return fmt.Errorf("can't evaluate field %s", strings.Join(idents[:i+1], "."))
+			}
+			structField, ok := current.FieldByName(ident)
+			if !ok || !structField.IsExported() {
+				return fmt.Errorf("unknown field %s", strings.Join(idents[:i+1], "."))
+			}
+			current = structField.Type
+		}
+		for current.Kind() == reflect.Pointer {
+			current = current.Elem()
+		}
+		if current.Kind() == reflect.Interface || current.Kind() == reflect.Map {
+			return nil
+		}
+	}
+	return nil
+}
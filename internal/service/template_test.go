@@ -0,0 +1,303 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pdf-service/internal/domain"
+)
+
+func renderProposalBlocks(t *testing.T, req domain.ProposalRequest) []renderedBlock {
+	t.Helper()
+	tpl, err := NewPDFService().documentTemplate(templateDocumentProposal, req.ResolvedDealType(), "")
+	if err != nil {
+		t.Fatalf("documentTemplate() error = %v", err)
+	}
+	blocks, err := tpl.render(newProposalTemplateData(req, defaultBrandingProfile()))
+	if err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+	return blocks
+}
+
+func renderContractClauses(t *testing.T, req domain.ContractRequest) []numberedClause {
+	t.Helper()
+	dealType := "sale"
+	if req.DealType == "rent" {
+		dealType = "rent"
+	}
+	tpl, err := NewPDFService().documentTemplate(templateDocumentContract, dealType, "")
+	if err != nil {
+		t.Fatalf("documentTemplate() error = %v", err)
+	}
+	blocks, err := tpl.render(newContractTemplateData(req, defaultBrandingProfile()))
+	if err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+	return findRenderedBlock(t, blocks, blockClauses).clauses
+}
+
+func findRenderedBlock(t *testing.T, blocks []renderedBlock, blockType string) renderedBlock {
+	t.Helper()
+	for _, block := range blocks {
+		if block.Type == blockType {
+			return block
+		}
+	}
+	t.Fatalf("expected a rendered %s block", blockType)
+	return renderedBlock{}
+}
+
+// renderedLines lists every text a proposal prints, in document order.
+func renderedLines(blocks []renderedBlock) []string {
+	var lines []string
+	for _, block := range blocks {
+		if block.text != "" {
+			lines = append(lines, block.text)
+		}
+		lines = append(lines, block.items...)
+	}
+	return lines
+}
+
+func TestDefaultDocumentTemplatesCoverEveryDocument(t *testing.T) {
+	svc := NewPDFService()
+	latest := map[string]string{templateDocumentProposal: "1.0.0", templateDocumentContract: "1.1.0"}
+	for _, document := range []string{templateDocumentProposal, templateDocumentContract} {
+		for _, dealType := range []string{"sale", "rent"} {
+			tpl, err := svc.documentTemplate(document, dealType, "")
+			if err != nil {
+				t.Fatalf("documentTemplate(%s, %s) error = %v", document, dealType, err)
+			}
+			if tpl.ID != document+"-"+dealType || tpl.Version != latest[document] {
+				t.Fatalf("unexpected default template %s@%s", tpl.ID, tpl.Version)
+			}
+			if _, err := svc.documentTemplate(document, dealType, "1.0.0"); err != nil {
+				t.Fatalf("expected %s-%s@1.0.0 to stay available, got %v", document, dealType, err)
+			}
+		}
+	}
+}
+
+func TestParseDocumentTemplateRejectsInvalidTemplates(t *testing.T) {
+	cases := map[string]string{
+		"unknown field":        `{"id":"x","version":"1.0.0","document":"proposal","deal_type":"sale","blocks":[{"type":"title","text":"{{.Missing}}"}]}`,
+		"unknown nested field": `{"id":"x","version":"1.0.0","document":"proposal","deal_type":"sale","blocks":[{"type":"list","items":[{"text":"ok","if":".Payment.Nope"}]}]}`,
+		"syntax":               `{"id":"x","version":"1.0.0","document":"proposal","deal_type":"sale","blocks":[{"type":"title","text":"{{if .ClientName}}"}]}`,
+		"unknown function":     `{"id":"x","version":"1.0.0","document":"proposal","deal_type":"sale","blocks":[{"type":"title","text":"{{shout .ClientName}}"}]}`,
+		"block type":           `{"id":"x","version":"1.0.0","document":"proposal","deal_type":"sale","blocks":[{"type":"banner","text":"A"}]}`,
+		"contract-only block":  `{"id":"x","version":"1.0.0","document":"proposal","deal_type":"sale","blocks":[{"type":"parties","parties":"buyers","label":"A"}]}`,
+		"version":              `{"id":"x","version":"v1","document":"proposal","deal_type":"sale","blocks":[{"type":"brand_footer"}]}`,
+		"document":             `{"id":"x","version":"1.0.0","document":"receipt","deal_type":"sale","blocks":[{"type":"brand_footer"}]}`,
+		"json key":             `{"id":"x","version":"1.0.0","document":"proposal","deal_type":"sale","blocks":[{"type":"brand_footer","colour":"red"}]}`,
+	}
+	for name, source := range cases {
+		if _, err := ParseDocumentTemplate([]byte(source)); err == nil {
+			t.Errorf("%s: expected ParseDocumentTemplate to fail", name)
+		}
+	}
+}
+
+func TestParseDocumentTemplateAllowsRangeOverInstallments(t *testing.T) {
+	source := `{"id":"x","version":"1.0.0","document":"proposal","deal_type":"sale","blocks":[
+		{"type":"list","items":[{"text":"{{range .Payment.Installments}}{{money .Amount}} {{$.ClientName}}{{end}}"}]}
+	]}`
+	if _, err := ParseDocumentTemplate([]byte(source)); err != nil {
+		t.Fatalf("ParseDocumentTemplate() error = %v", err)
+	}
+}
+
+func TestLoadDocumentTemplatesReplacesDefaultWording(t *testing.T) {
+	dir := t.TempDir()
+	source := `{
+		"id": "proposta-venda-juridico",
+		"version": "2.1.0",
+		"document": "proposal",
+		"deal_type": "sale",
+		"blocks": [
+			{"type": "title", "text": "PROPOSTA DE AQUISIÇÃO"},
+			{"type": "paragraph", "text": "Proponente: {{.ClientName}}{{if .Payment.Financing}}, com financiamento{{end}}."},
+			{"type": "brand_footer"}
+		]
+	}`
+	if err := os.WriteFile(filepath.Join(dir, "proposal-sale.json"), []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	templates, err := LoadDocumentTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadDocumentTemplates() error = %v", err)
+	}
+
+	doc, err := NewPDFService(WithDocumentTemplates(templates...)).GenerateProposal(domain.ProposalRequest{
+		ClientName:      "Ana Silva",
+		PropertyAddress: domain.FlexibleAddress{Raw: "Rua A, 10, Goiânia, GO"},
+		TotalValue:      150000,
+		Payment:         domain.PaymentBreakdown{Cash: 50000, Financing: 100000},
+		ValidityDays:    10,
+	})
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	text := extractPDFText(doc.PDF)
+	if !strings.Contains(text, "PROPOSTA DE AQUISIÇÃO") || !strings.Contains(text, "Proponente: Ana Silva, com financiamento.") {
+		t.Fatalf("expected the loaded template wording, got %q", text)
+	}
+	if strings.Contains(text, "PROPOSTA DE COMPRA DE IMÓVEL") {
+		t.Fatalf("expected the default template to be replaced, got %q", text)
+	}
+}
+
+func TestLoadDocumentTemplatesReportsTheFailingFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{"id":"x"`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadDocumentTemplates(dir)
+	if err == nil || !strings.Contains(err.Error(), "broken.json") {
+		t.Fatalf("expected an error naming broken.json, got %v", err)
+	}
+}
+
+func TestRenderDropsClausesItemsAndParagraphsWithEmptyConditions(t *testing.T) {
+	clauses := renderContractClauses(t, domain.ContractRequest{
+		DealType:    "rent",
+		RentalTerms: domain.RentalTerms{MonthlyRent: 1500, ExpectedStartDate: "2026-08-01"},
+	})
+
+	text := clauseText(clauses)
+	for _, expected := range []string{
+		"CLÁUSULA TERCEIRA – DO PRAZO",
+		"A locação terá início previsto em 01/08/2026.",
+		"CLÁUSULA QUARTA – DAS DISPOSIÇÕES GERAIS",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Fatalf("expected clauses to contain %q, got %q", expected, text)
+		}
+	}
+	if strings.Contains(text, "Parágrafo único") {
+		t.Fatalf("expected conditional paragraphs to be dropped, got %q", text)
+	}
+}
+
+func parseTestTemplate(t *testing.T, version, title string) *DocumentTemplate {
+	t.Helper()
+	tpl, err := ParseDocumentTemplate([]byte(`{
+		"id": "proposal-sale",
+		"version": "` + version + `",
+		"document": "proposal",
+		"deal_type": "sale",
+		"blocks": [{"type": "title", "text": "` + title + `"}]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseDocumentTemplate() error = %v", err)
+	}
+	return tpl
+}
+
+func TestGenerateProposalUsesNewestTemplateUnlessPinned(t *testing.T) {
+	svc := NewPDFService(WithDocumentTemplates(
+		parseTestTemplate(t, "1.10.0", "PROPOSTA REVISADA"),
+		parseTestTemplate(t, "1.2.0", "PROPOSTA INTERMEDIÁRIA"),
+	))
+	req := domain.ProposalRequest{
+		ClientName:      "Ana Silva",
+		PropertyAddress: domain.FlexibleAddress{Raw: "Rua A, 10, Goiânia, GO"},
+		TotalValue:      100000,
+		Payment:         domain.PaymentBreakdown{Cash: 100000},
+	}
+
+	latest, err := svc.GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	if latest.Template.Version != "1.10.0" || !strings.Contains(extractPDFText(latest.PDF), "PROPOSTA REVISADA") {
+		t.Fatalf("expected the newest template, got %s", latest.Template)
+	}
+
+	req.TemplateVersion = "1.0.0"
+	pinned, err := svc.GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	if pinned.Template.Version != "1.0.0" || !strings.Contains(extractPDFText(pinned.PDF), "PROPOSTA DE COMPRA DE IMÓVEL") {
+		t.Fatalf("expected the pinned built-in template, got %s", pinned.Template)
+	}
+	if pinned.Template.Hash == latest.Template.Hash || len(pinned.Template.Hash) != 64 {
+		t.Fatalf("expected distinct SHA-256 hashes, got %q and %q", pinned.Template.Hash, latest.Template.Hash)
+	}
+}
+
+func TestGenerateProposalStampsTemplateProvenance(t *testing.T) {
+	doc, err := NewPDFService().GenerateProposal(domain.ProposalRequest{
+		ClientName:      "Ana Silva",
+		PropertyAddress: domain.FlexibleAddress{Raw: "Rua A, 10, Goiânia, GO"},
+		TotalValue:      100000,
+		Payment:         domain.PaymentBreakdown{Cash: 100000},
+	})
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+
+	keywords := "template_id=proposal-sale template_version=1.0.0 template_sha256=" + doc.Template.Hash
+	if !strings.Contains(string(doc.PDF), "/Keywords ("+keywords+")") {
+		t.Fatalf("expected provenance keywords %q in the PDF metadata", keywords)
+	}
+	if label := "Modelo proposal-sale v1.0.0 · " + doc.Template.Hash[:12]; !strings.Contains(extractPDFText(doc.PDF), label) {
+		t.Fatalf("expected footer line %q", label)
+	}
+}
+
+func TestGenerateRejectsUnknownPinnedTemplateVersions(t *testing.T) {
+	svc := NewPDFService()
+	_, err := svc.GenerateContract(domain.ContractRequest{
+		ContractID:      "contract-1",
+		DealType:        "rent",
+		PropertyTitle:   "Casa",
+		PropertyAddress: "Rua A, 10",
+		Seller:          domain.ContractParty{Name: "Locador"},
+		Buyer:           domain.ContractParty{Name: "Locatário"},
+		RentalTerms:     domain.RentalTerms{MonthlyRent: 1500},
+		TemplateVersion: "2.0.0",
+	})
+	requireFieldError(t, err, "/template_version", domain.CodeInvalidChoice)
+
+	_, err = svc.GenerateReceipt(domain.ReceiptRequest{
+		ProposalID:      "proposal-1",
+		Payer:           domain.ContractParty{Name: "Ana"},
+		Payee:           domain.ContractParty{Name: "Bruno"},
+		Amount:          1000,
+		PaymentMethod:   "PIX",
+		PaymentDate:     "2026-03-10",
+		TemplateVersion: "2.0.0",
+	})
+	requireFieldError(t, err, "/template_version", domain.CodeInvalidChoice)
+}
+
+func TestGenerateContractDrawsTemplateBrandFooter(t *testing.T) {
+	tpl, err := ParseDocumentTemplate([]byte(`{
+		"id": "contract-sale-timbrado",
+		"version": "2.0.0",
+		"document": "contract",
+		"deal_type": "sale",
+		"blocks": [{"type": "title", "text": "CONTRATO"}, {"type": "brand_footer"}]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseDocumentTemplate() error = %v", err)
+	}
+	doc, err := NewPDFService(WithDocumentTemplates(tpl)).GenerateContract(domain.ContractRequest{
+		DealType:        "sale",
+		PropertyTitle:   "Casa de teste",
+		PropertyAddress: "Rua A, 10, Goiânia, GO",
+		Seller:          domain.ContractParty{Name: "Carlos Souza"},
+		Buyer:           domain.ContractParty{Name: "Ana Silva"},
+		SaleTerms:       domain.PaymentBreakdown{Cash: 100000},
+	})
+	if err != nil {
+		t.Fatalf("GenerateContract() error = %v", err)
+	}
+	if !strings.Contains(extractPDFText(doc.PDF), "Rua Abel Pereira de Castro") {
+		t.Fatal("expected the brand footer on the contract")
+	}
+}
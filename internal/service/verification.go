@@ -0,0 +1,142 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"pdf-service/internal/domain"
+	"pdf-service/internal/qrcode"
+	"pdf-service/internal/verification"
+)
+
+// verificationQRSize is the printed width of the verification QR code in
+// millimetres, large enough for phone cameras at the usual reading
+// distance.
+const verificationQRSize = 14.0
+
+// WithVerification records every generated document in registry under an
+// ID printed with a QR code in the footer of proposals and contracts. The
+// QR code links to baseURL + "/verify/" + ID, or holds the bare ID when
+// baseURL is empty.
+func WithVerification(registry verification.Registry, baseURL string) Option {
+	return func(s *PDFService) {
+		s.registry = registry
+		s.verificationBaseURL = baseURL
+	}
+}
+
+// WithVerificationSecret keys the verification IDs derived from the
+// requests, so they cannot be worked out from what a document says.
+// Without a secret every document gets a random ID instead, and renders
+// with verification are no longer byte for byte repeatable.
+func WithVerificationSecret(secret string) Option {
+	return func(s *PDFService) {
+		s.verificationSecret = []byte(secret)
+	}
+}
+
+// newVerificationID returns the ID a document is registered under, or an
+// empty one when documents are not being registered. With a secret, the ID
+// is derived from the render cache key of the request and the creation
+// date, so the same request renders the same bytes again. Signed documents
+// differ on every signature anyway; each gets a random ID and a record of
+// its own, as does every document when there is no secret to key the ID.
+func (s *PDFService) newVerificationID(key string, created time.Time, sign bool) string {
+	if s.registry == nil {
+		return ""
+	}
+	if sign || len(s.verificationSecret) == 0 {
+		return verification.NewID()
+	}
+	return verification.DeriveID(s.verificationSecret, []byte(key+"\n"+created.UTC().Format(time.RFC3339)))
+}
+
+// verificationOption assigns the document a verification ID and returns
+// the option printing it, with its QR code, in the footer of every page.
+func (s *PDFService) verificationOption(key string, created time.Time, sign bool) (string, documentOption, error) {
+	id := s.newVerificationID(key, created, sign)
+	if id == "" {
+		return "", func(*pageChrome) {}, nil
+	}
+	target := id
+	if s.verificationBaseURL != "" {
+		target = s.verificationBaseURL + "/verify/" + id
+	}
+	code, err := qrcode.Encode(target)
+	if err != nil {
+		return "", nil, fmt.Errorf("verification qr code: %w", err)
+	}
+	mark := &verificationMark{id: id, target: target, code: code}
+	return id, func(c *pageChrome) { c.verification = mark }, nil
+}
+
+// writeVerificationFooter draws the QR code at the bottom left corner,
+// below the bottom margin, with the short code and link beside it.
+func writeVerificationFooter(pdf *gofpdf.Fpdf, code *qrcode.Code, id, target string) {
+	leftMargin, _, _, _ := pdf.GetMargins()
+	_, pageHeight := pdf.GetPageSize()
+	top := pageHeight - verificationQRSize - 4
+	module := verificationQRSize / float64(code.Size())
+
+	pdf.SetFillColor(0, 0, 0)
+	for y := 0; y < code.Size(); y++ {
+		// Draw each horizontal run of dark modules as one rectangle.
+		for x := 0; x < code.Size(); {
+			if !code.Dark(x, y) {
+				x++
+				continue
+			}
+			start := x
+			for x < code.Size() && code.Dark(x, y) {
+				x++
+			}
+			pdf.Rect(leftMargin+float64(start)*module, top+float64(y)*module, float64(x-start)*module, module, "F")
+		}
+	}
+	pdf.SetFillColor(255, 255, 255)
+
+	textX := leftMargin + verificationQRSize + 3
+	pdf.SetTextColor(128, 128, 128)
+	pdf.SetXY(textX, top+1)
+	pdf.SetFont(documentFontFamily, "B", 7)
+	pdf.CellFormat(0, 4, "Código de verificação: "+verification.ShortCode(id), "", 2, "L", false, 0, "")
+	if target != id {
+		pdf.SetFont(documentFontFamily, "", 6)
+		pdf.CellFormat(0, 3, "Confira a autenticidade em "+target, "", 2, "L", false, 0, "")
+	}
+	pdf.SetTextColor(0, 0, 0)
+}
+
+// registerDocument stores the verification record of a finished document.
+// The document is only returned once its record is stored, so every ID
+// printed on a handed out document resolves. A document rendered again
+// keeps the record, and issue time, of its first rendering.
+func (s *PDFService) registerDocument(doc domain.GeneratedDocument, id, documentType, contractID string) (domain.GeneratedDocument, error) {
+	if id == "" {
+		return doc, nil
+	}
+	sum := sha256.Sum256(doc.PDF)
+	digest := hex.EncodeToString(sum[:])
+	if record, err := s.registry.Lookup(id); err == nil && record.SHA256 == digest {
+		doc.VerificationID = id
+		return doc, nil
+	}
+	err := s.registry.Register(verification.Record{
+		ID:              id,
+		DocumentType:    documentType,
+		SHA256:          digest,
+		TemplateID:      doc.Template.ID,
+		TemplateVersion: doc.Template.Version,
+		IssuedAt:        s.now().UTC(),
+		ContractID:      contractID,
+	})
+	if err != nil {
+		return domain.GeneratedDocument{}, fmt.Errorf("register document: %w", err)
+	}
+	doc.VerificationID = id
+	return doc, nil
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"pdf-service/internal/cache"
+	"pdf-service/internal/domain"
+	"pdf-service/internal/verification"
+)
+
+func TestGenerateContractRegistersAndPrintsVerificationCode(t *testing.T) {
+	registry := verification.NewMemoryRegistry()
+	clock := func() time.Time { return time.Date(2026, 3, 15, 14, 30, 0, 0, time.UTC) }
+	s := NewPDFService(WithClock(clock), WithVerification(registry, "https://pdf.example.com"))
+
+	doc, err := s.GenerateContract(domain.ContractRequest{
+		ContractID:      "contract-1",
+		DealType:        "sale",
+		PropertyTitle:   "Casa de teste",
+		PropertyAddress: "Rua A, 10, Goiânia, GO",
+		Seller:          domain.ContractParty{Name: "Carlos Souza"},
+		Buyer:           domain.ContractParty{Name: "Ana Silva"},
+		SaleTerms:       domain.PaymentBreakdown{Cash: 100000},
+	})
+	if err != nil {
+		t.Fatalf("GenerateContract() error = %v", err)
+	}
+	if doc.VerificationID == "" {
+		t.Fatal("expected the contract to get a verification ID")
+	}
+
+	record, err := registry.Lookup(doc.VerificationID)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	sum := sha256.Sum256(doc.PDF)
+	want := verification.Record{
+		ID:              doc.VerificationID,
+		DocumentType:    "contract",
+		SHA256:          hex.EncodeToString(sum[:]),
+		TemplateID:      doc.Template.ID,
+		TemplateVersion: doc.Template.Version,
+		IssuedAt:        clock(),
+		ContractID:      "contract-1",
+	}
+	if record != want {
+		t.Fatalf("expected %+v, got %+v", want, record)
+	}
+
+	text := extractPDFText(doc.PDF)
+	for _, want := range []string{verification.ShortCode(doc.VerificationID), "https://pdf.example.com/verify/" + doc.VerificationID} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in the footer, got %q", want, text)
+		}
+	}
+}
+
+func TestGenerateReceiptRegistersWithoutPrintingCode(t *testing.T) {
+	registry := verification.NewMemoryRegistry()
+	doc, err := NewPDFService(WithVerification(registry, "")).GenerateReceipt(domain.ReceiptRequest{
+		ProposalID:    "proposal-1",
+		Payer:         domain.ContractParty{Name: "Ana Silva"},
+		Payee:         domain.ContractParty{Name: "Carlos Souza"},
+		Amount:        25000,
+		PaymentMethod: "PIX",
+		PaymentDate:   "2026-03-15",
+	})
+	if err != nil {
+		t.Fatalf("GenerateReceipt() error = %v", err)
+	}
+	if _, err := registry.Lookup(doc.VerificationID); err != nil {
+		t.Fatalf("expected the receipt to be registered, got %v", err)
+	}
+	if strings.Contains(extractPDFText(doc.PDF), "Código de verificação") {
+		t.Fatal("expected no verification code printed on receipts")
+	}
+}
+
+func TestCachedDocumentsKeepTheirVerificationID(t *testing.T) {
+	registry := verification.NewMemoryRegistry()
+	s := NewCachedPDFService(NewPDFService(WithVerification(registry, "")), cache.NewLRU(8), time.Hour)
+	req := domain.ProposalRequest{
+		ClientName:            "Ana Silva",
+		PropertyAddressLegacy: "Rua A, 10",
+		TotalValue:            150000,
+		Payment:               domain.PaymentBreakdown{Cash: 150000},
+	}
+
+	first, err := s.GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	second, err := s.GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	if second.Cache != domain.CacheHit || second.VerificationID != first.VerificationID {
+		t.Fatalf("expected a cache hit with ID %q, got %q with %q", first.VerificationID, second.Cache, second.VerificationID)
+	}
+}
+
+func TestGenerateProposalWithVerificationIsDeterministic(t *testing.T) {
+	registry := verification.NewMemoryRegistry()
+	clock := func() time.Time { return time.Date(2026, 3, 15, 14, 30, 0, 0, time.UTC) }
+	s := NewPDFService(WithClock(clock), WithVerification(registry, ""), WithVerificationSecret("secret"))
+	req := domain.ProposalRequest{
+		ClientName:            "Ana Silva",
+		PropertyAddressLegacy: "Rua A, 10",
+		TotalValue:            150000,
+		Payment:               domain.PaymentBreakdown{Cash: 150000},
+	}
+
+	first, err := s.GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	second, err := s.GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	if !bytes.Equal(first.PDF, second.PDF) || first.VerificationID != second.VerificationID {
+		t.Fatal("expected the same request to render the same bytes and verification ID")
+	}
+
+	req.ClientName = "Bruno Lima"
+	other, err := s.GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	if other.VerificationID == first.VerificationID {
+		t.Fatal("expected another request to get another verification ID")
+	}
+	rekeyed, err := NewPDFService(WithClock(clock), WithVerification(registry, ""), WithVerificationSecret("other")).GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	if rekeyed.VerificationID == other.VerificationID {
+		t.Fatal("expected the verification ID to depend on the secret")
+	}
+}
+
+func TestVerificationIDsAreRandomWithoutASecret(t *testing.T) {
+	registry := verification.NewMemoryRegistry()
+	clock := func() time.Time { return time.Date(2026, 3, 15, 14, 30, 0, 0, time.UTC) }
+	s := NewPDFService(WithClock(clock), WithVerification(registry, ""), WithVerificationSecret(""))
+	req := domain.ProposalRequest{
+		ClientName:            "Ana Silva",
+		PropertyAddressLegacy: "Rua A, 10",
+		TotalValue:            150000,
+		Payment:               domain.PaymentBreakdown{Cash: 150000},
+	}
+
+	first, err := s.GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	second, err := s.GenerateProposal(req)
+	if err != nil {
+		t.Fatalf("GenerateProposal() error = %v", err)
+	}
+	if first.VerificationID == second.VerificationID {
+		t.Fatal("expected an unkeyed service not to derive verification IDs")
+	}
+	for _, doc := range []domain.GeneratedDocument{first, second} {
+		if _, err := registry.Lookup(doc.VerificationID); err != nil {
+			t.Fatalf("Lookup(%q) error = %v", doc.VerificationID, err)
+		}
+	}
+}
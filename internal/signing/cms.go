@@ -0,0 +1,240 @@
+package signing
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+var (
+	oidData                 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData           = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidAttrContentType      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidAttrMessageDigest    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidAttrSigningCertV2    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+	oidSHA256               = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidRSAEncryption        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidSHA256WithRSA        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidECDSAWithSHA256      = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+	errUnsupportedAlgorithm = errors.New("unsupported signature algorithm")
+)
+
+// The types below are the parts of RFC 5652 SignedData this package
+// writes and reads back.
+
+// contentInfo carries its [0] EXPLICIT content as a raw value, which
+// encoding/asn1 reads and writes with the tag included.
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	EncapContentInfo encapsulatedContentInfo
+	Certificates     asn1.RawValue // [0] IMPLICIT SET OF Certificate
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type encapsulatedContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+}
+
+type issuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type signerInfo struct {
+	Version            int
+	SID                issuerAndSerialNumber
+	DigestAlgorithm    pkix.AlgorithmIdentifier
+	SignedAttrs        asn1.RawValue // [0] IMPLICIT SET OF Attribute
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          []byte
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+// essCertIDv2 omits hashAlgorithm, which defaults to SHA-256 (RFC 5035).
+type essCertIDv2 struct {
+	CertHash []byte
+}
+
+type signingCertificateV2 struct {
+	Certs []essCertIDv2
+}
+
+// signedData builds a detached CAdES-BES SignedData over a content digest:
+// the signed attributes are the content type, the message digest and the
+// ESS signing-certificate-v2 binding the signer certificate, as PAdES
+// baseline signatures require. The signing time lives in the PDF signature
+// dictionary instead, so no signing-time attribute is added.
+func (s *Signer) signedData(digest []byte) ([]byte, error) {
+	leaf := s.chain[0]
+	certHash := sha256.Sum256(leaf.Raw)
+
+	var attrs [][]byte
+	for _, attr := range []struct {
+		oid   asn1.ObjectIdentifier
+		value any
+	}{
+		{oidAttrContentType, oidData},
+		{oidAttrMessageDigest, digest},
+		{oidAttrSigningCertV2, signingCertificateV2{Certs: []essCertIDv2{{CertHash: certHash[:]}}}},
+	} {
+		value, err := asn1.Marshal(attr.value)
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := asn1.Marshal(attribute{Type: attr.oid, Values: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: value}})
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, encoded)
+	}
+	// DER sorts the members of a SET OF by their encoding.
+	sort.Slice(attrs, func(i, j int) bool { return bytes.Compare(attrs[i], attrs[j]) < 0 })
+	attrsContent := bytes.Join(attrs, nil)
+
+	signedAttrs, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: attrsContent})
+	if err != nil {
+		return nil, err
+	}
+	attrsDigest := sha256.Sum256(signedAttrs)
+	signature, err := s.key.Sign(rand.Reader, attrsDigest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	signatureAlgorithm, err := signatureAlgorithmFor(s.key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	var certificates []byte
+	for _, cert := range s.chain {
+		certificates = append(certificates, cert.Raw...)
+	}
+	sd, err := asn1.Marshal(signedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: oidSHA256}},
+		EncapContentInfo: encapsulatedContentInfo{ContentType: oidData},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: certificates},
+		SignerInfos: []signerInfo{{
+			Version:            1,
+			SID:                issuerAndSerialNumber{Issuer: asn1.RawValue{FullBytes: leaf.RawIssuer}, SerialNumber: leaf.SerialNumber},
+			DigestAlgorithm:    pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+			SignedAttrs:        asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: attrsContent},
+			SignatureAlgorithm: signatureAlgorithm,
+			Signature:          signature,
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(contentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd},
+	})
+}
+
+func signatureAlgorithmFor(public crypto.PublicKey) (pkix.AlgorithmIdentifier, error) {
+	switch public.(type) {
+	case *rsa.PublicKey:
+		return pkix.AlgorithmIdentifier{Algorithm: oidRSAEncryption, Parameters: asn1.NullRawValue}, nil
+	case *ecdsa.PublicKey:
+		return pkix.AlgorithmIdentifier{Algorithm: oidECDSAWithSHA256}, nil
+	default:
+		return pkix.AlgorithmIdentifier{}, fmt.Errorf("%w: %T", errUnsupportedAlgorithm, public)
+	}
+}
+
+// verifySignedData checks a detached SignedData against the digest of the
+// signed content and returns the signer certificate followed by the other
+// certificates it carries. It does not decide whether the certificate is
+// trusted.
+func verifySignedData(der []byte, digest []byte) ([]*x509.Certificate, error) {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(der, &ci); err != nil {
+		return nil, fmt.Errorf("parse content info: %w", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, errors.New("not a SignedData")
+	}
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, fmt.Errorf("parse signed data: %w", err)
+	}
+	if len(sd.SignerInfos) != 1 {
+		return nil, fmt.Errorf("expected one signer, found %d", len(sd.SignerInfos))
+	}
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse certificates: %w", err)
+	}
+
+	info := sd.SignerInfos[0]
+	signer := -1
+	for i, cert := range certs {
+		if bytes.Equal(cert.RawIssuer, info.SID.Issuer.FullBytes) && cert.SerialNumber.Cmp(info.SID.SerialNumber) == 0 {
+			signer = i
+			break
+		}
+	}
+	if signer < 0 {
+		return nil, errors.New("signer certificate not included")
+	}
+	if !info.DigestAlgorithm.Algorithm.Equal(oidSHA256) {
+		return nil, fmt.Errorf("%w: digest %s", errUnsupportedAlgorithm, info.DigestAlgorithm.Algorithm)
+	}
+
+	var messageDigest []byte
+	for rest := info.SignedAttrs.Bytes; len(rest) > 0; {
+		var attr attribute
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &attr); err != nil {
+			return nil, fmt.Errorf("parse signed attributes: %w", err)
+		}
+		if attr.Type.Equal(oidAttrMessageDigest) {
+			if _, err := asn1.Unmarshal(attr.Values.Bytes, &messageDigest); err != nil {
+				return nil, fmt.Errorf("parse message digest: %w", err)
+			}
+		}
+	}
+	if !bytes.Equal(messageDigest, digest) {
+		return nil, errors.New("message digest does not match the signed content")
+	}
+
+	signedAttrs, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: info.SignedAttrs.Bytes})
+	if err != nil {
+		return nil, err
+	}
+	algorithm := x509.SHA256WithRSA
+	switch {
+	case info.SignatureAlgorithm.Algorithm.Equal(oidRSAEncryption), info.SignatureAlgorithm.Algorithm.Equal(oidSHA256WithRSA):
+	case info.SignatureAlgorithm.Algorithm.Equal(oidECDSAWithSHA256):
+		algorithm = x509.ECDSAWithSHA256
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedAlgorithm, info.SignatureAlgorithm.Algorithm)
+	}
+	if err := certs[signer].CheckSignature(algorithm, signedAttrs, info.Signature); err != nil {
+		return nil, err
+	}
+
+	ordered := append([]*x509.Certificate{certs[signer]}, certs[:signer]...)
+	return append(ordered, certs[signer+1:]...), nil
+}
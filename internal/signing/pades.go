@@ -0,0 +1,261 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// signatureReserve is the room left in /Contents for the CMS structure on
+// top of the embedded certificates.
+const signatureReserve = 8192
+
+// byteRangePlaceholder is overwritten in place once the offsets are known,
+// so it must be wide enough for any of them.
+const byteRangePlaceholder = "[0 0000000000 0000000000 0000000000]"
+
+var (
+	// ErrNotSigned is returned by Verify for a PDF without a signature.
+	ErrNotSigned = errors.New("pdf is not signed")
+	// ErrInvalidSignature is returned by Verify when the signature does not
+	// match the document.
+	ErrInvalidSignature = errors.New("pdf signature is invalid")
+
+	trailerSizePattern = regexp.MustCompile(`/Size (\d+)`)
+	trailerRootPattern = regexp.MustCompile(`/Root (\d+) 0 R`)
+	trailerInfoPattern = regexp.MustCompile(`/Info (\d+) 0 R`)
+	trailerIDPattern   = regexp.MustCompile(`/ID \[[^\]]*\]`)
+	startXRefPattern   = regexp.MustCompile(`startxref\s+(\d+)\s+%%EOF\s*$`)
+	pagesRefPattern    = regexp.MustCompile(`/Pages (\d+) 0 R`)
+	firstKidPattern    = regexp.MustCompile(`/Kids \[\s*(\d+) 0 R`)
+	byteRangePattern   = regexp.MustCompile(`/ByteRange\s*\[\s*(\d+)\s+(\d+)\s+(\d+)\s+(\d+)\s*\]`)
+)
+
+// Sign appends an incremental update to pdf that adds an invisible
+// signature field on the first page holding a detached CAdES signature
+// (SubFilter ETSI.CAdES.detached), covering the whole file except the
+// signature value itself. The original bytes are kept unchanged, as PAdES
+// requires. pdf must be a single-revision file with a classic
+// cross-reference table, as gofpdf writes.
+func (s *Signer) Sign(pdf []byte) ([]byte, error) {
+	trailerAt := bytes.LastIndex(pdf, []byte("\ntrailer\n"))
+	startXRef := startXRefPattern.FindSubmatch(pdf)
+	if trailerAt < 0 || startXRef == nil {
+		return nil, errors.New("pdf has no trailer")
+	}
+	trailer := pdf[trailerAt:]
+	size, errSize := submatchInt(trailerSizePattern, trailer)
+	root, errRoot := submatchInt(trailerRootPattern, trailer)
+	if errSize != nil || errRoot != nil {
+		return nil, errors.New("pdf trailer has no /Size or /Root")
+	}
+
+	catalog, err := objectDictionary(pdf, root)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(catalog, "/AcroForm") {
+		return nil, errors.New("pdf already has a form")
+	}
+	pagesRef, err := submatchInt(pagesRefPattern, []byte(catalog))
+	if err != nil {
+		return nil, errors.New("pdf catalog has no /Pages")
+	}
+	pages, err := objectDictionary(pdf, pagesRef)
+	if err != nil {
+		return nil, err
+	}
+	firstPage, err := submatchInt(firstKidPattern, []byte(pages))
+	if err != nil {
+		return nil, errors.New("pdf has no pages")
+	}
+	page, err := objectDictionary(pdf, firstPage)
+	if err != nil {
+		return nil, err
+	}
+
+	sigNum, fieldNum := size, size+1
+	reserve := signatureReserve
+	for _, cert := range s.chain {
+		reserve += len(cert.Raw)
+	}
+
+	var out bytes.Buffer
+	out.Write(pdf)
+	if !bytes.HasSuffix(pdf, []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	offsets := map[int]int{}
+
+	offsets[sigNum] = out.Len()
+	fmt.Fprintf(&out, "%d 0 obj\n<<\n/Type /Sig\n/Filter /Adobe.PPKLite\n/SubFilter /ETSI.CAdES.detached\n/ByteRange ", sigNum)
+	byteRangeAt := out.Len()
+	out.WriteString(byteRangePlaceholder)
+	out.WriteString("\n/Contents ")
+	contentsAt := out.Len()
+	out.WriteString("<" + strings.Repeat("0", 2*reserve) + ">")
+	contentsEnd := out.Len()
+	fmt.Fprintf(&out, "\n/M %s\n/Name %s\n", pdfTextString(pdfDate(s.now())), pdfTextString(s.chain[0].Subject.CommonName))
+	if s.reason != "" {
+		fmt.Fprintf(&out, "/Reason %s\n", pdfTextString(s.reason))
+	}
+	if s.location != "" {
+		fmt.Fprintf(&out, "/Location %s\n", pdfTextString(s.location))
+	}
+	out.WriteString(">>\nendobj\n")
+
+	writeObject := func(num int, dictionary string) {
+		offsets[num] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", num, dictionary)
+	}
+	writeObject(fieldNum, fmt.Sprintf("<<\n/Type /Annot\n/Subtype /Widget\n/FT /Sig\n/T (Signature1)\n/V %d 0 R\n/F 132\n/Rect [0 0 0 0]\n/P %d 0 R\n>>", sigNum, firstPage))
+	writeObject(root, withEntry(catalog, fmt.Sprintf("/AcroForm <<\n/Fields [%d 0 R]\n/SigFlags 3\n>>", fieldNum)))
+	writeObject(firstPage, withAnnotation(page, fieldNum))
+
+	xrefAt := out.Len()
+	out.WriteString("xref\n")
+	for _, num := range slices.Sorted(maps.Keys(offsets)) {
+		fmt.Fprintf(&out, "%d 1\n%010d 00000 n \n", num, offsets[num])
+	}
+	fmt.Fprintf(&out, "trailer\n<<\n/Size %d\n/Root %d 0 R\n", fieldNum+1, root)
+	if info, err := submatchInt(trailerInfoPattern, trailer); err == nil {
+		fmt.Fprintf(&out, "/Info %d 0 R\n", info)
+	}
+	if id := trailerIDPattern.Find(trailer); id != nil {
+		fmt.Fprintf(&out, "%s\n", id)
+	}
+	fmt.Fprintf(&out, "/Prev %s\n>>\nstartxref\n%d\n%%%%EOF\n", startXRef[1], xrefAt)
+
+	signed := out.Bytes()
+	byteRange := fmt.Sprintf("[0 %d %d %d]", contentsAt, contentsEnd, len(signed)-contentsEnd)
+	copy(signed[byteRangeAt:], byteRange+strings.Repeat(" ", len(byteRangePlaceholder)-len(byteRange)))
+
+	digest := sha256.New()
+	digest.Write(signed[:contentsAt])
+	digest.Write(signed[contentsEnd:])
+	cms, err := s.signedData(digest.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	if len(cms) > reserve {
+		return nil, fmt.Errorf("signature needs %d bytes, only %d reserved", len(cms), reserve)
+	}
+	copy(signed[contentsAt+1:], strings.ToUpper(hex.EncodeToString(cms)))
+	return signed, nil
+}
+
+// Verify checks the last signature of a PDF signed by Sign and returns the
+// signer certificate followed by the rest of the embedded chain. The
+// signature must cover the whole file. Whether the certificate is trusted,
+// for example whether it chains to ICP-Brasil, is left to the caller.
+func Verify(pdf []byte) ([]*x509.Certificate, error) {
+	matches := byteRangePattern.FindAllSubmatch(pdf, -1)
+	if len(matches) == 0 {
+		return nil, ErrNotSigned
+	}
+	var byteRange [4]int
+	for i := range byteRange {
+		value, err := strconv.Atoi(string(matches[len(matches)-1][i+1]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad /ByteRange", ErrInvalidSignature)
+		}
+		byteRange[i] = value
+	}
+	start, gapEnd, tail := byteRange[1], byteRange[2], byteRange[3]
+	if byteRange[0] != 0 || start >= gapEnd || gapEnd+tail != len(pdf) || pdf[start] != '<' || pdf[gapEnd-1] != '>' {
+		return nil, fmt.Errorf("%w: /ByteRange does not cover the file", ErrInvalidSignature)
+	}
+
+	cms, err := hex.DecodeString(string(pdf[start+1 : gapEnd-1]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad /Contents", ErrInvalidSignature)
+	}
+	digest := sha256.New()
+	digest.Write(pdf[:start])
+	digest.Write(pdf[gapEnd:])
+	certs, err := verifySignedData(cms, digest.Sum(nil))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return certs, nil
+}
+
+// objectDictionary returns the dictionary of the latest definition of
+// object num.
+func objectDictionary(pdf []byte, num int) (string, error) {
+	header := []byte("\n" + strconv.Itoa(num) + " 0 obj\n")
+	at := bytes.LastIndex(pdf, header)
+	if at < 0 {
+		return "", fmt.Errorf("pdf object %d not found", num)
+	}
+	body := pdf[at+len(header):]
+	end := bytes.Index(body, []byte("endobj"))
+	if end < 0 {
+		return "", fmt.Errorf("pdf object %d is not terminated", num)
+	}
+	dictionary := strings.TrimSpace(string(body[:end]))
+	if !strings.HasPrefix(dictionary, "<<") || !strings.HasSuffix(dictionary, ">>") {
+		return "", fmt.Errorf("pdf object %d is not a dictionary", num)
+	}
+	return dictionary, nil
+}
+
+// withEntry adds a key and value at the end of a dictionary.
+func withEntry(dictionary, entry string) string {
+	return strings.TrimSpace(strings.TrimSuffix(dictionary, ">>")) + "\n" + entry + "\n>>"
+}
+
+// withAnnotation adds an annotation reference to a page, extending its
+// /Annots array when it has one.
+func withAnnotation(page string, num int) string {
+	ref := fmt.Sprintf("%d 0 R", num)
+	if at := strings.Index(page, "/Annots ["); at >= 0 {
+		at += len("/Annots [")
+		return page[:at] + ref + " " + page[at:]
+	}
+	return withEntry(page, "/Annots ["+ref+"]")
+}
+
+func submatchInt(pattern *regexp.Regexp, data []byte) (int, error) {
+	match := pattern.FindSubmatch(data)
+	if match == nil {
+		return 0, errors.New("not found")
+	}
+	return strconv.Atoi(string(match[1]))
+}
+
+// pdfDate formats a time as a PDF date in UTC.
+func pdfDate(t time.Time) string {
+	return t.UTC().Format("D:20060102150405Z")
+}
+
+// pdfTextString encodes a text string literal, falling back to UTF-16BE
+// for text outside printable ASCII.
+func pdfTextString(value string) string {
+	ascii := true
+	for _, r := range value {
+		if r < 0x20 || r > 0x7e {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		escaper := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+		return "(" + escaper.Replace(value) + ")"
+	}
+	encoded := []byte{0xfe, 0xff}
+	for _, unit := range utf16.Encode([]rune(value)) {
+		encoded = append(encoded, byte(unit>>8), byte(unit))
+	}
+	return "<" + strings.ToUpper(hex.EncodeToString(encoded)) + ">"
+}
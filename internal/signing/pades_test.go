@@ -0,0 +1,121 @@
+package signing
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// selfSignedCertificate issues a throwaway certificate for key.
+func selfSignedCertificate(t *testing.T, key crypto.Signer, commonName string) *x509.Certificate {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Imobiliária Teste"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("x509.CreateCertificate() error = %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("x509.ParseCertificate() error = %v", err)
+	}
+	return cert
+}
+
+func testPDF(t *testing.T) []byte {
+	t.Helper()
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.Cell(40, 10, "Contrato de compra e venda")
+	pdf.AddPage()
+	var out bytes.Buffer
+	if err := pdf.Output(&out); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	return out.Bytes()
+}
+
+func TestSignProducesVerifiableIncrementalUpdate(t *testing.T) {
+	for name, newKey := range map[string]func() (crypto.Signer, error){
+		"rsa":   func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, 2048) },
+		"ecdsa": func() (crypto.Signer, error) { return ecdsa.GenerateKey(elliptic.P256(), rand.Reader) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			key, err := newKey()
+			if err != nil {
+				t.Fatalf("generate key: %v", err)
+			}
+			cert := selfSignedCertificate(t, key, "Imobiliária Teste Ltda")
+			signer, err := NewSigner(key, []*x509.Certificate{cert},
+				WithReason("Documento emitido pela imobiliária"),
+				WithClock(func() time.Time { return time.Date(2026, 3, 15, 14, 30, 0, 0, time.UTC) }),
+			)
+			if err != nil {
+				t.Fatalf("NewSigner() error = %v", err)
+			}
+
+			original := testPDF(t)
+			signed, err := signer.Sign(original)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if !bytes.HasPrefix(signed, original) {
+				t.Fatal("expected the original revision to be kept byte for byte")
+			}
+			for _, want := range []string{"/SubFilter /ETSI.CAdES.detached", "/M (D:20260315143000Z)", "/SigFlags 3", "/Annots [", "/Prev "} {
+				if !bytes.Contains(signed, []byte(want)) {
+					t.Fatalf("expected %q in the signed PDF", want)
+				}
+			}
+
+			certs, err := Verify(signed)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if !certs[0].Equal(cert) {
+				t.Fatalf("expected the signer certificate, got %s", certs[0].Subject)
+			}
+
+			tampered := bytes.Replace(signed, []byte("Contrato"), []byte("Distrato"), 1)
+			if _, err := Verify(tampered); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("expected a tampered PDF to fail, got %v", err)
+			}
+			appended := append(append([]byte(nil), signed...), "\n% extra\n"...)
+			if _, err := Verify(appended); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("expected content after the signature to fail, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyReportsUnsignedPDF(t *testing.T) {
+	if _, err := Verify(testPDF(t)); !errors.Is(err, ErrNotSigned) {
+		t.Fatalf("expected ErrNotSigned, got %v", err)
+	}
+}
+
+func TestNewSignerRejectsMismatchedKey(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := NewSigner(other, []*x509.Certificate{selfSignedCertificate(t, key, "Teste")}); err == nil {
+		t.Fatal("expected a key that does not match the certificate to be rejected")
+	}
+}
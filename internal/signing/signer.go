@@ -0,0 +1,147 @@
+// Package signing applies PAdES signatures to finished PDF documents with
+// the agency's certificate and checks them back.
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+// Signer signs PDFs with one private key and its certificate chain.
+type Signer struct {
+	key      crypto.Signer
+	chain    []*x509.Certificate
+	reason   string
+	location string
+	now      func() time.Time
+}
+
+// Option customizes a Signer at construction time.
+type Option func(*Signer)
+
+// WithReason sets the reason shown by PDF readers for the signature.
+func WithReason(reason string) Option {
+	return func(s *Signer) {
+		s.reason = reason
+	}
+}
+
+// WithLocation sets the signing location shown by PDF readers.
+func WithLocation(location string) Option {
+	return func(s *Signer) {
+		s.location = location
+	}
+}
+
+// WithClock replaces time.Now as the signing time.
+func WithClock(now func() time.Time) Option {
+	return func(s *Signer) {
+		s.now = now
+	}
+}
+
+// NewSigner signs with key. chain starts with the certificate of key and
+// may continue with the intermediate certificates up to the root, which
+// are embedded so verifiers can build the path.
+func NewSigner(key crypto.Signer, chain []*x509.Certificate, options ...Option) (*Signer, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("signing certificate is required")
+	}
+	if !publicKeysEqual(key.Public(), chain[0].PublicKey) {
+		return nil, errors.New("signing key does not match the certificate")
+	}
+	if _, err := signatureAlgorithmFor(key.Public()); err != nil {
+		return nil, err
+	}
+	s := &Signer{key: key, chain: chain, now: time.Now}
+	for _, option := range options {
+		option(s)
+	}
+	return s, nil
+}
+
+// LoadPKCS12 reads a PKCS#12 (.p12/.pfx) bundle with one private key and
+// its certificate chain. Only the legacy SHA-1/3DES and RC2 encryptions are
+// understood; bundles exported with AES must be re-exported, for example
+// with "openssl pkcs12 -export -legacy".
+func LoadPKCS12(data []byte, password string, options ...Option) (*Signer, error) {
+	blocks, err := pkcs12.ToPEM(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("read PKCS#12: %w", err)
+	}
+
+	var key crypto.Signer
+	var certs []*x509.Certificate
+	for _, block := range blocks {
+		switch block.Type {
+		case "PRIVATE KEY":
+			if key, err = parsePrivateKey(block); err != nil {
+				return nil, err
+			}
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("read PKCS#12 certificate: %w", err)
+			}
+			certs = append(certs, cert)
+		}
+	}
+	if key == nil {
+		return nil, errors.New("PKCS#12 has no private key")
+	}
+
+	// Put the certificate of the key first; bundles do not agree on order.
+	for i, cert := range certs {
+		if publicKeysEqual(key.Public(), cert.PublicKey) {
+			certs[0], certs[i] = certs[i], certs[0]
+			break
+		}
+	}
+	return NewSigner(key, certs, options...)
+}
+
+// LoadPKCS12File reads a PKCS#12 bundle from path.
+func LoadPKCS12File(path, password string, options ...Option) (*Signer, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path comes from operator configuration, not from requests.
+	if err != nil {
+		return nil, err
+	}
+	return LoadPKCS12(data, password, options...)
+}
+
+// Certificate is the signer certificate.
+func (s *Signer) Certificate() *x509.Certificate {
+	return s.chain[0]
+}
+
+// parsePrivateKey reads the key blocks pkcs12.ToPEM produces, which hold
+// PKCS#1 RSA or SEC 1 EC keys despite their "PRIVATE KEY" type.
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("PKCS#12 private key must be RSA or ECDSA")
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	switch a := a.(type) {
+	case *rsa.PublicKey:
+		return a.Equal(b)
+	case *ecdsa.PublicKey:
+		return a.Equal(b)
+	default:
+		return false
+	}
+}
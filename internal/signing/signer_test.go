@@ -0,0 +1,232 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+// The helpers below write a minimal PKCS#12 bundle (RFC 7292) the way
+// legacy exporters do: a 3DES-shrouded key bag, a certificate bag and a
+// SHA-1 HMAC. x/crypto/pkcs12 only reads bundles, so tests build their own
+// instead of committing key material.
+
+var (
+	oidTestCertBag         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidTestShroudedKeyBag  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidTestX509Certificate = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidTestPBEWith3DES     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+	oidTestSHA1            = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+)
+
+type testPFX struct {
+	Version  int
+	AuthSafe testContentInfo
+	MacData  testMacData
+}
+
+type testContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue
+}
+
+type testSafeBag struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue
+}
+
+type testCertBag struct {
+	ID   asn1.ObjectIdentifier
+	Data asn1.RawValue
+}
+
+type testEncryptedKey struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Data      []byte
+}
+
+type testPBEParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+type testMacData struct {
+	Mac        testDigestInfo
+	Salt       []byte
+	Iterations int
+}
+
+type testDigestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+// explicit wraps DER in a [0] EXPLICIT tag.
+func explicit(der []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: der}
+}
+
+func mustMarshal(t *testing.T, value any) []byte {
+	t.Helper()
+	der, err := asn1.Marshal(value)
+	if err != nil {
+		t.Fatalf("asn1.Marshal() error = %v", err)
+	}
+	return der
+}
+
+// pkcs12KDF is the key derivation of RFC 7292 appendix B.2 with SHA-1.
+func pkcs12KDF(password, salt []byte, id byte, iterations, size int) []byte {
+	const u, v = 20, 64
+	fill := func(data []byte) []byte {
+		if len(data) == 0 {
+			return nil
+		}
+		out := make([]byte, v*((len(data)+v-1)/v))
+		for i := range out {
+			out[i] = data[i%len(data)]
+		}
+		return out
+	}
+	d := make([]byte, v)
+	for i := range d {
+		d[i] = id
+	}
+	input := append(fill(salt), fill(password)...)
+
+	var out []byte
+	for len(out) < size {
+		a := sha1.Sum(append(append([]byte(nil), d...), input...))
+		for i := 1; i < iterations; i++ {
+			a = sha1.Sum(a[:])
+		}
+		out = append(out, a[:]...)
+
+		b := new(big.Int).SetBytes(fill(a[:u])[:v])
+		one := big.NewInt(1)
+		modulus := new(big.Int).Lsh(one, v*8)
+		for j := 0; j < len(input); j += v {
+			block := new(big.Int).SetBytes(input[j : j+v])
+			block.Add(block, b).Add(block, one).Mod(block, modulus)
+			encoded := block.Bytes()
+			copy(input[j:j+v], make([]byte, v))
+			copy(input[j+v-len(encoded):j+v], encoded)
+		}
+	}
+	return out[:size]
+}
+
+func bmpPassword(password string) []byte {
+	var out []byte
+	for _, unit := range utf16.Encode([]rune(password)) {
+		out = append(out, byte(unit>>8), byte(unit))
+	}
+	return append(out, 0, 0)
+}
+
+func encodeTestPKCS12(t *testing.T, key crypto.Signer, certs []*x509.Certificate, password string) []byte {
+	t.Helper()
+	bmp := bmpPassword(password)
+	salt := []byte("saltsalt")
+	const iterations = 2048
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() error = %v", err)
+	}
+	padding := des.BlockSize - len(pkcs8)%des.BlockSize
+	for range padding {
+		pkcs8 = append(pkcs8, byte(padding))
+	}
+	block, err := des.NewTripleDESCipher(pkcs12KDF(bmp, salt, 1, iterations, 24))
+	if err != nil {
+		t.Fatalf("NewTripleDESCipher() error = %v", err)
+	}
+	cipher.NewCBCEncrypter(block, pkcs12KDF(bmp, salt, 2, iterations, 8)).CryptBlocks(pkcs8, pkcs8)
+	keyBag := mustMarshal(t, testEncryptedKey{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidTestPBEWith3DES, Parameters: asn1.RawValue{FullBytes: mustMarshal(t, testPBEParams{Salt: salt, Iterations: iterations})}},
+		Data:      pkcs8,
+	})
+
+	keyBags := mustMarshal(t, []testSafeBag{{ID: oidTestShroudedKeyBag, Value: explicit(keyBag)}})
+	var certBags []testSafeBag
+	for _, cert := range certs {
+		bag := mustMarshal(t, testCertBag{ID: oidTestX509Certificate, Data: explicit(mustMarshal(t, cert.Raw))})
+		certBags = append(certBags, testSafeBag{ID: oidTestCertBag, Value: explicit(bag)})
+	}
+	authSafe := mustMarshal(t, []testContentInfo{
+		{ContentType: oidData, Content: explicit(mustMarshal(t, mustMarshal(t, certBags)))},
+		{ContentType: oidData, Content: explicit(mustMarshal(t, keyBags))},
+	})
+
+	mac := hmac.New(sha1.New, pkcs12KDF(bmp, salt, 3, iterations, 20))
+	mac.Write(authSafe)
+	return mustMarshal(t, testPFX{
+		Version:  3,
+		AuthSafe: testContentInfo{ContentType: oidData, Content: explicit(mustMarshal(t, authSafe))},
+		MacData: testMacData{
+			Mac:        testDigestInfo{Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidTestSHA1, Parameters: asn1.NullRawValue}, Digest: mac.Sum(nil)},
+			Salt:       salt,
+			Iterations: iterations,
+		},
+	})
+}
+
+func TestLoadPKCS12FileSignsWithTheBundledKey(t *testing.T) {
+	for name, newKey := range map[string]func() (crypto.Signer, error){
+		"rsa":   func() (crypto.Signer, error) { return rsa.GenerateKey(rand.Reader, 2048) },
+		"ecdsa": func() (crypto.Signer, error) { return ecdsa.GenerateKey(elliptic.P256(), rand.Reader) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			key, err := newKey()
+			if err != nil {
+				t.Fatalf("generate key: %v", err)
+			}
+			caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			ca := selfSignedCertificate(t, caKey, "AC Teste")
+			cert := selfSignedCertificate(t, key, "Imobiliária Teste Ltda")
+
+			path := filepath.Join(t.TempDir(), "agency.p12")
+			if err := os.WriteFile(path, encodeTestPKCS12(t, key, []*x509.Certificate{ca, cert}, "test-password"), 0o600); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			if _, err := LoadPKCS12File(path, "wrong"); err == nil {
+				t.Fatal("expected a wrong password to fail")
+			}
+			signer, err := LoadPKCS12File(path, "test-password")
+			if err != nil {
+				t.Fatalf("LoadPKCS12File() error = %v", err)
+			}
+			if !signer.Certificate().Equal(cert) {
+				t.Fatalf("expected the key's certificate first, got %s", signer.Certificate().Subject)
+			}
+
+			signed, err := signer.Sign(testPDF(t))
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			certs, err := Verify(signed)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if len(certs) != 2 || !certs[0].Equal(cert) || !certs[1].Equal(ca) {
+				t.Fatalf("expected the chain to be embedded, got %d certificates", len(certs))
+			}
+		})
+	}
+}
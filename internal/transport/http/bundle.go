@@ -0,0 +1,46 @@
+package httptransport
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"pdf-service/internal/domain"
+)
+
+// MaxBundlePayloadBytes leaves room for the uploaded files of a bundle,
+// which arrive base64 encoded.
+const MaxBundlePayloadBytes int64 = 32 << 20 // 32MB
+
+const bundleFilename = "dossie.pdf"
+
+// GenerateBundle merges generated documents and uploaded files into one
+// PDF behind a cover page with the table of contents.
+func (h *Handler) GenerateBundle(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxBundlePayloadBytes)
+
+	var req domain.BundleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "payload too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	doc, err := h.pdfService.GenerateBundle(req)
+	if err != nil {
+		respondGenerationError(c, err)
+		return
+	}
+
+	respondDocument(c, bundleFilename, doc)
+}
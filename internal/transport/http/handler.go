@@ -10,8 +10,28 @@ import (
 )
 
 type PDFService interface {
-	GenerateProposal(req domain.ProposalRequest) ([]byte, error)
-	GenerateContract(req domain.ContractRequest) ([]byte, error)
+	GenerateProposal(req domain.ProposalRequest) (domain.GeneratedDocument, error)
+	GenerateContract(req domain.ContractRequest) (domain.GeneratedDocument, error)
+	GenerateReceipt(req domain.ReceiptRequest) (domain.GeneratedDocument, error)
+	GenerateFinancingSimulation(req domain.FinancingSimulationRequest) (domain.GeneratedDocument, error)
+	GenerateBundle(req domain.BundleRequest) (domain.GeneratedDocument, error)
+	PreviewProposal(req domain.ProposalRequest) (domain.ProposalPreview, error)
+	PreviewContract(req domain.ContractRequest) (domain.ContractPreview, error)
+	CheckReferences(refs domain.DocumentReferences) error
+}
+
+// referencedRequest is a document request that names a branding profile
+// and template version.
+type referencedRequest interface {
+	Validate() error
+	References() domain.DocumentReferences
+}
+
+// validateRequest reports the field problems of req together with unknown
+// branding profile or template version references, so a client sees all
+// of them in one response.
+func validateRequest(service PDFService, req referencedRequest) error {
+	return domain.JoinValidationErrors(req.Validate(), service.CheckReferences(req.References()))
 }
 
 type Handler struct {
@@ -20,6 +40,16 @@ type Handler struct {
 
 const maxProposalPayloadBytes int64 = 1 << 20 // 1MB
 
+// Attachment names of the generated documents.
+const (
+	proposalFilename            = "proposta_compra_imovel.pdf"
+	saleContractFilename        = "contrato_compra_venda.pdf"
+	rentContractFilename        = "contrato_locacao.pdf"
+	draftFilenamePrefix         = "minuta_"
+	receiptFilename             = "recibo_sinal.pdf"
+	financingSimulationFilename = "simulacao_financiamento.pdf"
+)
+
 func NewHandler(pdfService PDFService) *Handler {
 	return &Handler{pdfService: pdfService}
 }
@@ -38,23 +68,87 @@ func (h *Handler) GenerateContract(c *gin.Context) {
 		return
 	}
 
-	if err := req.Validate(); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := validateRequest(h.pdfService, &req); err != nil {
+		respondValidationError(c, err)
 		return
 	}
 
-	pdfBytes, err := h.pdfService.GenerateContract(req)
+	doc, err := h.pdfService.GenerateContract(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate pdf"})
+		respondGenerationError(c, err)
 		return
 	}
 
-	filename := "minuta_contrato_compra_venda.pdf"
+	respondDocument(c, contractFilename(req), doc)
+}
+
+// contractFilename names drafts "minuta_…" and contracts in the other
+// states after the contract alone.
+func contractFilename(req domain.ContractRequest) string {
+	filename := saleContractFilename
 	if req.DealType == "rent" {
-		filename = "minuta_contrato_locacao.pdf"
+		filename = rentContractFilename
 	}
-	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
-	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+	if req.ResolvedDocumentState() == domain.DocumentStateDraft {
+		filename = draftFilenamePrefix + filename
+	}
+	return filename
+}
+
+func (h *Handler) GenerateReceipt(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxProposalPayloadBytes)
+
+	var req domain.ReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "payload too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	if err := validateRequest(h.pdfService, &req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	doc, err := h.pdfService.GenerateReceipt(req)
+	if err != nil {
+		respondGenerationError(c, err)
+		return
+	}
+
+	respondDocument(c, receiptFilename, doc)
+}
+
+func (h *Handler) GenerateFinancingSimulation(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxProposalPayloadBytes)
+
+	var req domain.FinancingSimulationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "payload too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	if err := validateRequest(h.pdfService, &req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	doc, err := h.pdfService.GenerateFinancingSimulation(req)
+	if err != nil {
+		respondGenerationError(c, err)
+		return
+	}
+
+	respondDocument(c, financingSimulationFilename, doc)
 }
 
 func (h *Handler) GenerateProposal(c *gin.Context) {
@@ -73,17 +167,121 @@ func (h *Handler) GenerateProposal(c *gin.Context) {
 
 	req.Sanitize()
 
-	if err := req.Validate(); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := validateRequest(h.pdfService, &req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	doc, err := h.pdfService.GenerateProposal(req)
+	if err != nil {
+		respondGenerationError(c, err)
 		return
 	}
 
-	pdfBytes, err := h.pdfService.GenerateProposal(req)
+	respondDocument(c, proposalFilename, doc)
+}
+
+// ValidateProposal is a dry run of GenerateProposal: it answers with the
+// resolved proposal, and the request field behind each value, instead of
+// the PDF.
+func (h *Handler) ValidateProposal(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxProposalPayloadBytes)
+
+	var req domain.ProposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "payload too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	preview, err := h.pdfService.PreviewProposal(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate pdf"})
+		respondGenerationError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// ValidateContract is a dry run of GenerateContract.
+func (h *Handler) ValidateContract(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxProposalPayloadBytes)
+
+	var req domain.ContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "payload too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	preview, err := h.pdfService.PreviewContract(req)
+	if err != nil {
+		respondGenerationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// respondDocument sends the PDF as an attachment, with the template
+// provenance and verification ID in headers for the backend to store.
+func respondDocument(c *gin.Context, filename string, doc domain.GeneratedDocument) {
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("X-Template-Id", doc.Template.ID)
+	c.Header("X-Template-Version", doc.Template.Version)
+	c.Header("X-Template-Hash", doc.Template.Hash)
+	if doc.VerificationID != "" {
+		c.Header("X-Verification-Id", doc.VerificationID)
+	}
+	if doc.Cache != "" {
+		c.Header("X-Cache", doc.Cache)
+	}
+	c.Data(http.StatusOK, "application/pdf", doc.PDF)
+}
+
+// respondValidationError answers with every field problem at once, as
+// {"error": "validation failed", "errors": [{"path", "code", "message",
+// "limit"}]}, so clients can highlight all invalid fields.
+func respondValidationError(c *gin.Context, err error) {
+	var fieldErrs domain.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "validation failed", "errors": fieldErrs})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// respondGenerationError maps renderer failures to HTTP responses.
+func respondGenerationError(c *gin.Context, err error) {
+	var fieldErrs domain.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		respondValidationError(c, err)
+		return
+	}
+	status, message := generationErrorStatus(err)
+	c.JSON(status, gin.H{"error": message})
+}
+
+// generationErrorStatus maps a renderer failure to an HTTP status and the
+// message safe to show. Only request-caused errors, which carry field
+// problems, are exposed; anything else is an opaque 500.
+func generationErrorStatus(err error) (int, string) {
+	var fieldErrs domain.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		return http.StatusUnprocessableEntity, fieldErrs.Error()
+	}
+	return http.StatusInternalServerError, "failed to generate pdf"
+}
 
-	c.Header("Content-Disposition", `attachment; filename="proposta_compra_imovel.pdf"`)
-	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+// GenerationErrorMessage is the message of generationErrorStatus, for job
+// callbacks that report a failure outside an HTTP response.
+func GenerationErrorMessage(err error) string {
+	_, message := generationErrorStatus(err)
+	return message
 }
@@ -1,6 +1,7 @@
 package httptransport
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -16,26 +17,84 @@ import (
 type stubProposalPDFService struct {
 	receivedReq domain.ProposalRequest
 	response    []byte
+	template    domain.TemplateProvenance
+	cache       string
 	err         error
+	references  error
+}
+
+func (s *stubProposalPDFService) CheckReferences(domain.DocumentReferences) error {
+	return s.references
 }
 
 func (s *stubProposalPDFService) GenerateProposal(
 	req domain.ProposalRequest,
-) ([]byte, error) {
+) (domain.GeneratedDocument, error) {
 	s.receivedReq = req
 	if s.err != nil {
-		return nil, s.err
+		return domain.GeneratedDocument{}, s.err
 	}
-	return s.response, nil
+	return domain.GeneratedDocument{PDF: s.response, Template: s.template, Cache: s.cache}, nil
 }
 
 func (s *stubProposalPDFService) GenerateContract(
 	req domain.ContractRequest,
-) ([]byte, error) {
+) (domain.GeneratedDocument, error) {
+	if s.err != nil {
+		return domain.GeneratedDocument{}, s.err
+	}
+	return domain.GeneratedDocument{PDF: s.response, Template: s.template}, nil
+}
+
+func (s *stubProposalPDFService) GenerateReceipt(
+	req domain.ReceiptRequest,
+) (domain.GeneratedDocument, error) {
+	if s.err != nil {
+		return domain.GeneratedDocument{}, s.err
+	}
+	return domain.GeneratedDocument{PDF: s.response, Template: s.template}, nil
+}
+
+func (s *stubProposalPDFService) GenerateFinancingSimulation(
+	req domain.FinancingSimulationRequest,
+) (domain.GeneratedDocument, error) {
 	if s.err != nil {
-		return nil, s.err
+		return domain.GeneratedDocument{}, s.err
 	}
-	return s.response, nil
+	return domain.GeneratedDocument{PDF: s.response, Template: s.template}, nil
+}
+
+func (s *stubProposalPDFService) GenerateBundle(
+	req domain.BundleRequest,
+) (domain.GeneratedDocument, error) {
+	if s.err != nil {
+		return domain.GeneratedDocument{}, s.err
+	}
+	return domain.GeneratedDocument{PDF: s.response, Template: s.template}, nil
+}
+
+func (s *stubProposalPDFService) PreviewProposal(
+	req domain.ProposalRequest,
+) (domain.ProposalPreview, error) {
+	if err := req.Validate(); err != nil {
+		return domain.ProposalPreview{}, err
+	}
+	if s.err != nil {
+		return domain.ProposalPreview{}, s.err
+	}
+	return domain.ProposalPreview{Proposal: req.Resolve(), Template: s.template}, nil
+}
+
+func (s *stubProposalPDFService) PreviewContract(
+	req domain.ContractRequest,
+) (domain.ContractPreview, error) {
+	if err := req.Validate(); err != nil {
+		return domain.ContractPreview{}, err
+	}
+	if s.err != nil {
+		return domain.ContractPreview{}, s.err
+	}
+	return domain.ContractPreview{Contract: req.Resolve(), Template: s.template}, nil
 }
 
 func TestGenerateProposalRejectsOversizedPayload(t *testing.T) {
@@ -131,10 +190,10 @@ func TestGenerateProposalRejectsValidationErrorsBeforeCallingService(t *testing.
 
 	router.ServeHTTP(res, req)
 
-	if res.Code != http.StatusBadRequest {
-		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, res.Code)
+	if res.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, res.Code)
 	}
-	if body := res.Body.String(); !strings.Contains(body, "payment breakdown must match total value") {
+	if body := res.Body.String(); !strings.Contains(body, `"path":"/payment","code":"sum_mismatch"`) {
 		t.Fatalf("expected validation error payload, got %q", body)
 	}
 	if service.receivedReq.ClientName != "" {
@@ -153,7 +212,7 @@ func TestGenerateProposalSanitizesPayloadBeforeCallingService(t *testing.T) {
 
 	payload := `{
 		"clientName":"  Ana \u202e Silva  ",
-		"clientCpf":"123.456.789-00",
+		"clientCpf":"529.982.247-25",
 		"propertyAddress":{"street":" Rua 1 ","number":"10","city":" Goiânia ","state":"go"},
 		"brokerName":" Pedro \u200f Souza ",
 		"sellingBrokerName":" Maria ",
@@ -203,7 +262,7 @@ func TestGenerateProposalAcceptsBackendSnakeCasePayloadShape(t *testing.T) {
 
 	payload := `{
 		"client_name":"Ana Silva",
-		"client_cpf":"123.456.789-00",
+		"client_cpf":"529.982.247-25",
 		"property_address":"Rua A, 10, Centro, Goiânia, GO",
 		"broker_name":"Pedro",
 		"selling_broker_name":"Maria",
@@ -280,3 +339,419 @@ func TestGenerateProposalReturnsServerErrorWhenPDFServiceFails(t *testing.T) {
 		t.Fatalf("expected server error payload, got %q", body)
 	}
 }
+
+func TestGenerateReceiptReturnsPDFForValidPayload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubProposalPDFService{response: []byte("%PDF-1.4 receipt")}
+	handler := NewHandler(service)
+
+	router := gin.New()
+	router.POST("/generate-receipt", handler.GenerateReceipt)
+
+	payload := `{
+		"proposal_id":"proposal-1",
+		"payer":{"name":"Ana Silva","cpf":"529.982.247-25"},
+		"payee":{"name":"Carlos Souza"},
+		"amount":25000,
+		"payment_method":"PIX",
+		"payment_date":"2026-03-15"
+	}`
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/generate-receipt",
+		strings.NewReader(payload),
+	)
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, res.Code)
+	}
+	if got := res.Header().Get("Content-Disposition"); !strings.Contains(got, "recibo_sinal.pdf") {
+		t.Fatalf("expected receipt filename, got %q", got)
+	}
+}
+
+func TestGenerateReceiptRejectsValidationErrorsBeforeCallingService(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubProposalPDFService{err: errors.New("must not be called")}
+	handler := NewHandler(service)
+
+	router := gin.New()
+	router.POST("/generate-receipt", handler.GenerateReceipt)
+
+	payload := `{
+		"contract_id":"contract-1",
+		"payer":{"name":"Ana Silva"},
+		"payee":{"name":"Carlos Souza"},
+		"amount":0,
+		"payment_method":"PIX",
+		"payment_date":"2026-03-15"
+	}`
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/generate-receipt",
+		strings.NewReader(payload),
+	)
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, res.Code)
+	}
+	if body := res.Body.String(); !strings.Contains(body, `"path":"/amount","code":"positive"`) {
+		t.Fatalf("expected validation error payload, got %q", body)
+	}
+}
+
+func TestGenerateFinancingSimulationAcceptsTabelaPrice(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubProposalPDFService{response: []byte("%PDF-1.4 simulation")}
+	handler := NewHandler(service)
+
+	router := gin.New()
+	router.POST("/generate-financing-simulation", handler.GenerateFinancingSimulation)
+
+	payload := `{"property_value":400000,"entry":80000,"annual_interest_rate":10.5,"term_months":360,"system":"Tabela Price"}`
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/generate-financing-simulation",
+		strings.NewReader(payload),
+	)
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, res.Code)
+	}
+	if got := res.Header().Get("Content-Disposition"); !strings.Contains(got, "simulacao_financiamento.pdf") {
+		t.Fatalf("expected simulation filename, got %q", got)
+	}
+}
+
+func TestGenerateFinancingSimulationRejectsUnknownSystem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubProposalPDFService{response: []byte("%PDF-1.4 simulation")}
+	handler := NewHandler(service)
+
+	router := gin.New()
+	router.POST("/generate-financing-simulation", handler.GenerateFinancingSimulation)
+
+	payload := `{"property_value":400000,"entry":80000,"annual_interest_rate":10.5,"term_months":360,"system":"germain"}`
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/generate-financing-simulation",
+		strings.NewReader(payload),
+	)
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, res.Code)
+	}
+	if body := res.Body.String(); !strings.Contains(body, `"path":"/system","code":"invalid_choice"`) {
+		t.Fatalf("expected validation error payload, got %q", body)
+	}
+}
+
+func TestGenerateProposalReportsUnknownBrandingProfileWithTheOtherFieldErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubProposalPDFService{references: domain.UnknownBrandingProfileError()}
+	handler := NewHandler(service)
+
+	router := gin.New()
+	router.POST("/generate-proposal", handler.GenerateProposal)
+
+	payload := `{
+		"clientName":"Ana Silva",
+		"propertyAddress":"Rua A, 10, Centro, Goiânia, GO",
+		"totalValue":100,
+		"payment":{"cash":100},
+		"issue_date":"15/03/2026",
+		"branding_profile_id":"missing"
+	}`
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/generate-proposal",
+		strings.NewReader(payload),
+	)
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, res.Code)
+	}
+	body := res.Body.String()
+	for _, want := range []string{`"path":"/issue_date"`, `"path":"/branding_profile_id","code":"invalid_choice"`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %s in the payload, got %q", want, body)
+		}
+	}
+}
+
+func TestGenerateProposalReturnsTemplateProvenanceHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubProposalPDFService{
+		response: []byte("%PDF-1.4"),
+		template: domain.TemplateProvenance{ID: "proposal-sale", Version: "1.2.0", Hash: "abc123"},
+		cache:    domain.CacheHit,
+	}
+	handler := NewHandler(service)
+
+	router := gin.New()
+	router.POST("/generate-proposal", handler.GenerateProposal)
+
+	payload := `{
+		"clientName":"Ana Silva",
+		"propertyAddress":"Rua A, 10, Centro, Goiânia, GO",
+		"totalValue":100,
+		"payment":{"cash":100},
+		"template_version":"1.2.0"
+	}`
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/generate-proposal",
+		strings.NewReader(payload),
+	)
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, res.Code)
+	}
+	if got := service.receivedReq.TemplateVersion; got != "1.2.0" {
+		t.Fatalf("expected pinned template version to reach the service, got %q", got)
+	}
+	for header, want := range map[string]string{
+		"X-Template-Id":      "proposal-sale",
+		"X-Template-Version": "1.2.0",
+		"X-Template-Hash":    "abc123",
+		"X-Cache":            "HIT",
+	} {
+		if got := res.Header().Get(header); got != want {
+			t.Fatalf("expected %s %q, got %q", header, want, got)
+		}
+	}
+}
+
+func TestGenerateProposalMapsUnknownTemplateVersionToFieldError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubProposalPDFService{err: domain.UnknownTemplateVersionError("proposal sale 9.0.0")}
+	handler := NewHandler(service)
+
+	router := gin.New()
+	router.POST("/generate-proposal", handler.GenerateProposal)
+
+	payload := `{
+		"clientName":"Ana Silva",
+		"propertyAddress":"Rua A, 10, Centro, Goiânia, GO",
+		"totalValue":100,
+		"payment":{"cash":100},
+		"template_version":"9.0.0"
+	}`
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/generate-proposal",
+		strings.NewReader(payload),
+	)
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, res.Code)
+	}
+	if body := res.Body.String(); !strings.Contains(body, `"path":"/template_version","code":"invalid_choice"`) {
+		t.Fatalf("expected template version error payload, got %q", body)
+	}
+}
+
+func TestGenerateContractReportsEveryValidationErrorAtOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubProposalPDFService{response: []byte("%PDF-1.4 contract")}
+	handler := NewHandler(service)
+
+	router := gin.New()
+	router.POST("/generate-contract", handler.GenerateContract)
+
+	payload := `{
+		"deal_type":"sale",
+		"property_address":"Rua A, 10",
+		"sellers":[{"name":"Vendedor","cpf":"123.456.789-00"}],
+		"buyers":[{"name":"Comprador","marital_status":"casado"}]
+	}`
+
+	req := httptest.NewRequest(http.MethodPost, "/generate-contract", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, res.Code)
+	}
+	var body struct {
+		Error  string              `json:"error"`
+		Errors []domain.FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected JSON body, got %q", res.Body.String())
+	}
+	var paths []string
+	for _, fieldErr := range body.Errors {
+		paths = append(paths, fieldErr.Path+" "+fieldErr.Code)
+	}
+	want := []string{"/property_title required", "/sellers/0/cpf invalid_document", "/buyers/0/spouse required"}
+	if body.Error != "validation failed" || strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %q %v", want, body.Error, paths)
+	}
+}
+
+func TestValidateProposalReturnsResolvedModelWithSources(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubProposalPDFService{}
+	handler := NewHandler(service)
+
+	router := gin.New()
+	router.POST("/proposals/validate", handler.ValidateProposal)
+
+	payload := `{
+		"client_name":"Ana Silva",
+		"property_address":"Rua A, 10, Goiânia, GO",
+		"value":100000,
+		"payment_method":"Dinheiro: R$ 60.000,00 Financiamento: R$ 40.000,00"
+	}`
+
+	req := httptest.NewRequest(http.MethodPost, "/proposals/validate", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, res.Code, res.Body.String())
+	}
+	var body domain.ProposalPreview
+	if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected JSON body, got %q", res.Body.String())
+	}
+	proposal := body.Proposal
+	if proposal.ClientName.Source != "/client_name" || proposal.TotalValue.Source != "/value" {
+		t.Fatalf("expected legacy sources, got %+v and %+v", proposal.ClientName, proposal.TotalValue)
+	}
+	if proposal.Payment == nil || proposal.Payment.Cash.Value != 60000 || proposal.Payment.Cash.Source != "/payment_method" {
+		t.Fatalf("expected cash parsed from payment_method, got %+v", proposal.Payment)
+	}
+	if proposal.ValidityDays.Value != 10 || proposal.ValidityDays.Source != domain.SourceDefault {
+		t.Fatalf("expected default validity, got %+v", proposal.ValidityDays)
+	}
+	if res.Header().Get("Content-Type") == "application/pdf" {
+		t.Fatal("expected no PDF to be generated")
+	}
+}
+
+func TestValidateContractReportsValidationErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewHandler(&stubProposalPDFService{})
+
+	router := gin.New()
+	router.POST("/contracts/validate", handler.ValidateContract)
+
+	req := httptest.NewRequest(http.MethodPost, "/contracts/validate", strings.NewReader(`{"deal_type":"sale"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, res.Code)
+	}
+	if body := res.Body.String(); !strings.Contains(body, `"path":"/seller/name"`) {
+		t.Fatalf("expected field errors, got %q", body)
+	}
+}
+
+func TestGenerateBundleReportsPartProblemsBeforeCallingService(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubProposalPDFService{err: errors.New("must not be called")}
+	handler := NewHandler(service)
+
+	router := gin.New()
+	router.POST("/bundles", handler.GenerateBundle)
+
+	payload := `{"parts":[
+		{"type":"receipt","request":{"payer":{"name":"Ana Silva"},"payee":{"name":"Carlos Souza"},"amount":0,"payment_method":"PIX","payment_date":"2026-03-15","proposal_id":"proposal-1"}},
+		{"type":"upload","data":"R0lGODlh"}
+	]}`
+
+	req := httptest.NewRequest(http.MethodPost, "/bundles", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, res.Code)
+	}
+	body := res.Body.String()
+	for _, want := range []string{`"path":"/parts/0/request/amount","code":"positive"`, `"path":"/parts/1/data","code":"invalid_format"`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %s in the validation errors, got %q", want, body)
+		}
+	}
+}
+
+func TestGenerateBundleReturnsDossierPDF(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubProposalPDFService{response: []byte("%PDF-1.7 bundle")}
+	handler := NewHandler(service)
+
+	router := gin.New()
+	router.POST("/bundles", handler.GenerateBundle)
+
+	payload := `{"parts":[{"type":"upload","title":"Matrícula","data":"JVBERi0xLjQK"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/bundles", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, res.Code)
+	}
+	if got := res.Header().Get("Content-Disposition"); !strings.Contains(got, "dossie.pdf") {
+		t.Fatalf("expected bundle filename, got %q", got)
+	}
+}
@@ -0,0 +1,186 @@
+package httptransport
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"pdf-service/internal/cache"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyStoreKeyPrefix = "pdf-service:idempotency:v1:"
+)
+
+// idempotentResponse is a stored first response. Fingerprint is the hash of
+// the request body it answered.
+type idempotentResponse struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header"`
+	Body        []byte      `json:"body"`
+}
+
+// Idempotency replays the stored response of requests that repeat an
+// Idempotency-Key header, so a client retrying a timed-out request gets
+// the document the first attempt produced instead of a second rendering.
+type Idempotency struct {
+	store cache.Store
+	ttl   time.Duration
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+}
+
+// NewIdempotency keeps first responses in store for ttl.
+func NewIdempotency(store cache.Store, ttl time.Duration) *Idempotency {
+	return &Idempotency{store: store, ttl: ttl, inFlight: map[string]bool{}}
+}
+
+// Middleware handles requests that carry an Idempotency-Key; others pass
+// through untouched. A replay with the same payload gets the stored status,
+// headers and body; a different payload, or a replay while the first
+// request is still running, gets 409. Server errors are not stored, so a
+// retry after one renders again. The store failing, or being full,
+// degrades to handling new keys normally.
+func (i *Idempotency) Middleware() gin.HandlerFunc {
+	return i.MiddlewareWithLimit(maxProposalPayloadBytes)
+}
+
+// MiddlewareWithLimit is Middleware for endpoints whose payloads may be
+// larger than the generate endpoints accept, up to maxBytes.
+func (i *Idempotency) MiddlewareWithLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "payload too large"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		storeKey := idempotencyStoreKey(c.Request.Method, c.FullPath(), key)
+		fingerprint := sha256.Sum256(body)
+		fingerprintHex := hex.EncodeToString(fingerprint[:])
+
+		if stored, ok := i.lookup(storeKey); ok {
+			if stored.Fingerprint != fingerprintHex {
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "Idempotency-Key was already used with a different payload"})
+				return
+			}
+			replay(c, stored)
+			return
+		}
+
+		if !i.claim(storeKey) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "a request with this Idempotency-Key is still in progress"})
+			return
+		}
+		defer i.release(storeKey)
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+		data, err := json.Marshal(idempotentResponse{
+			Fingerprint: fingerprintHex,
+			Status:      recorder.Status(),
+			Header:      recorder.Header().Clone(),
+			Body:        recorder.body.Bytes(),
+		})
+		if err == nil {
+			_ = i.store.Set(storeKey, data, i.ttl)
+		}
+	}
+}
+
+func (i *Idempotency) lookup(storeKey string) (idempotentResponse, bool) {
+	data, ok, err := i.store.Get(storeKey)
+	if err != nil || !ok {
+		return idempotentResponse{}, false
+	}
+	var stored idempotentResponse
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return idempotentResponse{}, false
+	}
+	return stored, true
+}
+
+// claim marks a key as being handled, failing when another request holds
+// it.
+func (i *Idempotency) claim(storeKey string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.inFlight[storeKey] {
+		return false
+	}
+	i.inFlight[storeKey] = true
+	return true
+}
+
+func (i *Idempotency) release(storeKey string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.inFlight, storeKey)
+}
+
+// idempotencyStoreKey scopes a client key to the endpoint it was sent to.
+func idempotencyStoreKey(method, route, key string) string {
+	sum := sha256.Sum256([]byte(method + " " + route + "\x00" + key))
+	return idempotencyStoreKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+func replay(c *gin.Context, stored idempotentResponse) {
+	header := c.Writer.Header()
+	for name, values := range stored.Header {
+		header[name] = values
+	}
+	header.Set(idempotentReplayedHeader, "true")
+	c.Status(stored.Status)
+	_, _ = c.Writer.Write(stored.Body)
+	c.Abort()
+}
+
+// responseRecorder keeps a copy of the body written through it.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(data string) (int, error) {
+	r.body.WriteString(data)
+	return r.ResponseWriter.WriteString(data)
+}
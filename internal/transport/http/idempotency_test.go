@@ -0,0 +1,139 @@
+package httptransport
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"pdf-service/internal/cache"
+	"pdf-service/internal/domain"
+)
+
+// countingPDFService counts contract renders.
+type countingPDFService struct {
+	stubProposalPDFService
+	renders atomic.Int32
+}
+
+func (s *countingPDFService) GenerateContract(req domain.ContractRequest) (domain.GeneratedDocument, error) {
+	s.renders.Add(1)
+	return s.stubProposalPDFService.GenerateContract(req)
+}
+
+const idempotentContractPayload = `{
+	"deal_type":"sale",
+	"property_title":"Casa",
+	"property_address":"Rua A, 10",
+	"seller":{"name":"Vendedor"},
+	"buyer":{"name":"Comprador"},
+	"sale_value":100000,
+	"sale_terms":{"cash":100000}
+}`
+
+func newIdempotentRouter(service PDFService) *gin.Engine {
+	return newIdempotentRouterWithStore(service, cache.NewExpiring(1<<20))
+}
+
+func newIdempotentRouterWithStore(service PDFService, store cache.Store) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	idempotent := NewIdempotency(store, time.Hour).Middleware()
+	router.POST("/generate-contract", idempotent, NewHandler(service).GenerateContract)
+	return router
+}
+
+func postContract(router *gin.Engine, key, payload string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/generate-contract", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	return res
+}
+
+func TestIdempotencyKeyReplaysFirstResponse(t *testing.T) {
+	service := &countingPDFService{stubProposalPDFService: stubProposalPDFService{
+		response: []byte("%PDF-1.4 contract"),
+		template: domain.TemplateProvenance{ID: "contract-sale", Version: "1.0.0", Hash: "abc"},
+	}}
+	router := newIdempotentRouter(service)
+
+	first := postContract(router, "retry-1", idempotentContractPayload)
+	replayed := postContract(router, "retry-1", idempotentContractPayload)
+
+	if first.Code != http.StatusOK || replayed.Code != http.StatusOK {
+		t.Fatalf("expected both attempts to succeed, got %d and %d", first.Code, replayed.Code)
+	}
+	if got := service.renders.Load(); got != 1 {
+		t.Fatalf("expected a single render, got %d", got)
+	}
+	if replayed.Body.String() != first.Body.String() || replayed.Header().Get("X-Template-Id") != "contract-sale" {
+		t.Fatalf("expected the stored response, got %q %v", replayed.Body.String(), replayed.Header())
+	}
+	if replayed.Header().Get(idempotentReplayedHeader) != "true" || first.Header().Get(idempotentReplayedHeader) != "" {
+		t.Fatal("expected only the replay to be marked")
+	}
+
+	postContract(router, "", idempotentContractPayload)
+	postContract(router, "retry-2", idempotentContractPayload)
+	if got := service.renders.Load(); got != 3 {
+		t.Fatalf("expected requests without or with a new key to render, got %d renders", got)
+	}
+}
+
+func TestIdempotencyKeyRejectsDifferentPayload(t *testing.T) {
+	service := &countingPDFService{stubProposalPDFService: stubProposalPDFService{response: []byte("%PDF-1.4")}}
+	router := newIdempotentRouter(service)
+
+	postContract(router, "retry-1", idempotentContractPayload)
+	res := postContract(router, "retry-1", strings.Replace(idempotentContractPayload, "Casa", "Apartamento", 1))
+
+	if res.Code != http.StatusConflict {
+		t.Fatalf("expected status %d, got %d", http.StatusConflict, res.Code)
+	}
+	if got := service.renders.Load(); got != 1 {
+		t.Fatalf("expected the conflicting request not to render, got %d renders", got)
+	}
+}
+
+func TestIdempotencyKeyDoesNotStoreServerErrors(t *testing.T) {
+	service := &countingPDFService{stubProposalPDFService: stubProposalPDFService{err: errors.New("generator failed")}}
+	router := newIdempotentRouter(service)
+
+	if res := postContract(router, "retry-1", idempotentContractPayload); res.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, res.Code)
+	}
+	service.err = nil
+	service.response = []byte("%PDF-1.4")
+	if res := postContract(router, "retry-1", idempotentContractPayload); res.Code != http.StatusOK {
+		t.Fatalf("expected the retry to render, got %d", res.Code)
+	}
+	if got := service.renders.Load(); got != 2 {
+		t.Fatalf("expected two renders, got %d", got)
+	}
+}
+
+func TestIdempotencyKeyKeepsReplayingWhenStoreIsFull(t *testing.T) {
+	service := &countingPDFService{stubProposalPDFService: stubProposalPDFService{response: []byte("%PDF-1.4 contract")}}
+	// Room for one stored response only.
+	router := newIdempotentRouterWithStore(service, cache.NewExpiring(400))
+
+	postContract(router, "retry-1", idempotentContractPayload)
+	if res := postContract(router, "retry-2", idempotentContractPayload); res.Code != http.StatusOK {
+		t.Fatalf("expected a new key to be served when the store is full, got %d", res.Code)
+	}
+	if res := postContract(router, "retry-1", idempotentContractPayload); res.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Fatal("expected the stored response to be replayed for its whole TTL")
+	}
+	if got := service.renders.Load(); got != 2 {
+		t.Fatalf("expected 2 renders, got %d", got)
+	}
+}
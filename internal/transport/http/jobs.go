@@ -0,0 +1,266 @@
+package httptransport
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"pdf-service/internal/domain"
+	"pdf-service/internal/jobs"
+)
+
+// Document types accepted by POST /jobs.
+const (
+	jobTypeProposal            = "proposal"
+	jobTypeContract            = "contract"
+	jobTypeReceipt             = "receipt"
+	jobTypeFinancingSimulation = "financing_simulation"
+	jobTypeBundle              = "bundle"
+)
+
+var errUnknownJobType = errors.New("type must be proposal, contract, receipt, financing_simulation or bundle")
+
+// JobHandler serves the asynchronous variant of the generate endpoints for
+// documents that take longer to render than a client will wait.
+type JobHandler struct {
+	pdfService PDFService
+	queue      *jobs.Queue
+}
+
+func NewJobHandler(pdfService PDFService, queue *jobs.Queue) *JobHandler {
+	return &JobHandler{pdfService: pdfService, queue: queue}
+}
+
+// jobRequest wraps the body the matching generate endpoint accepts.
+// CallbackURL, when set, receives a signed notification once the job
+// finishes.
+type jobRequest struct {
+	Type        string          `json:"type"`
+	Request     json.RawMessage `json:"request"`
+	CallbackURL string          `json:"callback_url"`
+}
+
+type jobResponse struct {
+	ID         string                     `json:"id"`
+	Type       string                     `json:"type"`
+	Status     jobs.Status                `json:"status"`
+	Error      string                     `json:"error,omitempty"`
+	Template   *domain.TemplateProvenance `json:"template,omitempty"`
+	CreatedAt  time.Time                  `json:"created_at"`
+	StartedAt  *time.Time                 `json:"started_at,omitempty"`
+	FinishedAt *time.Time                 `json:"finished_at,omitempty"`
+	ExpiresAt  *time.Time                 `json:"expires_at,omitempty"`
+	ResultURL  string                     `json:"result_url,omitempty"`
+	SHA256     string                     `json:"sha256,omitempty"`
+	Callback   *callbackResponse          `json:"callback,omitempty"`
+}
+
+type callbackResponse struct {
+	URL      string                 `json:"url"`
+	Status   jobs.DeliveryStatus    `json:"status"`
+	Attempts []jobs.DeliveryAttempt `json:"attempts"`
+}
+
+func newJobResponse(job jobs.Job) jobResponse {
+	response := jobResponse{
+		ID:         job.ID,
+		Type:       job.Kind,
+		Status:     job.Status,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  optionalTime(job.StartedAt),
+		FinishedAt: optionalTime(job.FinishedAt),
+		ExpiresAt:  optionalTime(job.ExpiresAt),
+	}
+	if job.CallbackURL != "" {
+		response.Callback = &callbackResponse{
+			URL:      job.CallbackURL,
+			Status:   job.Delivery,
+			Attempts: append([]jobs.DeliveryAttempt{}, job.Deliveries...),
+		}
+	}
+	switch job.Status {
+	case jobs.StatusSucceeded:
+		template := job.Template
+		response.Template = &template
+		response.ResultURL = "/jobs/" + job.ID + "/result"
+		response.SHA256 = job.SHA256
+	case jobs.StatusFailed:
+		_, response.Error = generationErrorStatus(job.Err)
+	}
+	return response
+}
+
+func optionalTime(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+	return &value
+}
+
+// SubmitJob validates the wrapped request right away, so a bad payload is
+// rejected with the same 422 as the synchronous endpoints, and queues the
+// rendering. Each type keeps the payload limit of its synchronous endpoint.
+func (h *JobHandler) SubmitJob(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxBundlePayloadBytes)
+
+	var payload jobRequest
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "payload too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	if payload.Type != jobTypeBundle && int64(len(payload.Request)) > maxProposalPayloadBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "payload too large"})
+		return
+	}
+
+	render, filename, err := h.prepareJob(payload)
+	if err == nil {
+		err = h.validateCallbackURL(payload.CallbackURL)
+	}
+	if err != nil {
+		var fieldErrs domain.ValidationErrors
+		switch {
+		case errors.As(err, &fieldErrs):
+			respondValidationError(c, err)
+		case errors.Is(err, errUnknownJobType):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		}
+		return
+	}
+
+	job, err := h.queue.Submit(payload.Type, filename, render, jobs.WithCallback(payload.CallbackURL))
+	if err != nil {
+		if errors.Is(err, jobs.ErrQueueFull) || errors.Is(err, jobs.ErrClosed) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue job"})
+		return
+	}
+
+	c.Header("Location", "/jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, newJobResponse(job))
+}
+
+// prepareJob decodes and validates the wrapped request and returns the
+// render call for the worker together with the attachment name.
+func (h *JobHandler) prepareJob(payload jobRequest) (jobs.RenderFunc, string, error) {
+	switch payload.Type {
+	case jobTypeProposal:
+		var req domain.ProposalRequest
+		if err := h.decodeAndValidate(payload.Request, &req); err != nil {
+			return nil, "", err
+		}
+		return func() (domain.GeneratedDocument, error) { return h.pdfService.GenerateProposal(req) }, proposalFilename, nil
+	case jobTypeContract:
+		var req domain.ContractRequest
+		if err := h.decodeAndValidate(payload.Request, &req); err != nil {
+			return nil, "", err
+		}
+		return func() (domain.GeneratedDocument, error) { return h.pdfService.GenerateContract(req) }, contractFilename(req), nil
+	case jobTypeReceipt:
+		var req domain.ReceiptRequest
+		if err := h.decodeAndValidate(payload.Request, &req); err != nil {
+			return nil, "", err
+		}
+		return func() (domain.GeneratedDocument, error) { return h.pdfService.GenerateReceipt(req) }, receiptFilename, nil
+	case jobTypeFinancingSimulation:
+		var req domain.FinancingSimulationRequest
+		if err := h.decodeAndValidate(payload.Request, &req); err != nil {
+			return nil, "", err
+		}
+		return func() (domain.GeneratedDocument, error) { return h.pdfService.GenerateFinancingSimulation(req) }, financingSimulationFilename, nil
+	case jobTypeBundle:
+		var req domain.BundleRequest
+		if err := json.Unmarshal(payload.Request, &req); err != nil {
+			return nil, "", err
+		}
+		if err := req.Validate(); err != nil {
+			return nil, "", err
+		}
+		return func() (domain.GeneratedDocument, error) { return h.pdfService.GenerateBundle(req) }, bundleFilename, nil
+	default:
+		return nil, "", errUnknownJobType
+	}
+}
+
+// validateCallbackURL accepts an empty URL or an absolute http(s) one, and
+// only when the server has a webhook secret to sign callbacks with. Hosts
+// that are obviously internal are refused here; names resolving to them
+// are refused by the notifier when it connects.
+func (h *JobHandler) validateCallbackURL(callbackURL string) error {
+	if callbackURL == "" {
+		return nil
+	}
+	if !h.queue.AcceptsCallbacks() {
+		return domain.ValidationErrors{{
+			Path:    "/callback_url",
+			Code:    domain.CodeNotApplicable,
+			Message: "Callbacks não estão habilitados neste servidor.",
+		}}
+	}
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return domain.ValidationErrors{{
+			Path:    "/callback_url",
+			Code:    domain.CodeInvalidFormat,
+			Message: "Informe uma URL absoluta http ou https.",
+			Limit:   []string{"http", "https"},
+		}}
+	}
+	host := strings.ToLower(parsed.Hostname())
+	ip := net.ParseIP(host)
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || (ip != nil && !jobs.PublicAddress(ip)) {
+		return domain.ValidationErrors{{
+			Path:    "/callback_url",
+			Code:    domain.CodeNotApplicable,
+			Message: "Callbacks só podem ser enviados a endereços públicos.",
+		}}
+	}
+	return nil
+}
+
+func (h *JobHandler) decodeAndValidate(data json.RawMessage, req referencedRequest) error {
+	if err := json.Unmarshal(data, req); err != nil {
+		return err
+	}
+	return validateRequest(h.pdfService, req)
+}
+
+// JobStatus reports where a job is; finished jobs include when their
+// result expires.
+func (h *JobHandler) JobStatus(c *gin.Context) {
+	job, err := h.queue.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, newJobResponse(job))
+}
+
+// JobResult sends the PDF of a succeeded job. Until then it answers 409
+// with the job status.
+func (h *JobHandler) JobResult(c *gin.Context) {
+	job, doc, err := h.queue.Result(c.Param("id"))
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+	case err != nil:
+		c.JSON(http.StatusConflict, newJobResponse(job))
+	default:
+		respondDocument(c, job.Filename, doc)
+	}
+}
@@ -0,0 +1,196 @@
+package httptransport
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"pdf-service/internal/domain"
+	"pdf-service/internal/jobs"
+)
+
+func newJobRouter(service PDFService, queue *jobs.Queue) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewJobHandler(service, queue)
+	router := gin.New()
+	router.POST("/jobs", handler.SubmitJob)
+	router.GET("/jobs/:id", handler.JobStatus)
+	router.GET("/jobs/:id/result", handler.JobResult)
+	return router
+}
+
+func serveJobRequest(router *gin.Engine, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	return res
+}
+
+func TestJobLifecycleFromSubmitToResult(t *testing.T) {
+	queue := jobs.NewQueue(1, 4, time.Minute)
+	defer queue.Close()
+	service := &stubProposalPDFService{
+		response: []byte("%PDF-1.4 contract"),
+		template: domain.TemplateProvenance{ID: "contract-rent", Version: "1.0.0", Hash: "abc"},
+	}
+	router := newJobRouter(service, queue)
+
+	payload := `{"type":"contract","request":{
+		"deal_type":"rent",
+		"property_title":"Apartamento",
+		"property_address":"Rua A, 10",
+		"seller":{"name":"Locador"},
+		"buyer":{"name":"Locatário"},
+		"rental_terms":{"monthly_rent":2500}
+	}}`
+	res := serveJobRequest(router, http.MethodPost, "/jobs", payload)
+	if res.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusAccepted, res.Code, res.Body.String())
+	}
+	var submitted jobResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &submitted); err != nil || submitted.ID == "" {
+		t.Fatalf("expected a job ID, got %q", res.Body.String())
+	}
+	if got := res.Header().Get("Location"); got != "/jobs/"+submitted.ID {
+		t.Fatalf("unexpected Location %q", got)
+	}
+
+	var status jobResponse
+	deadline := time.Now().Add(2 * time.Second)
+	for status.Status != jobs.StatusSucceeded && time.Now().Before(deadline) {
+		res = serveJobRequest(router, http.MethodGet, "/jobs/"+submitted.ID, "")
+		if err := json.Unmarshal(res.Body.Bytes(), &status); err != nil {
+			t.Fatalf("expected JSON status, got %q", res.Body.String())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if status.Status != jobs.StatusSucceeded || status.ExpiresAt == nil || status.ResultURL == "" {
+		t.Fatalf("expected a succeeded job with expiry and result URL, got %+v", status)
+	}
+
+	res = serveJobRequest(router, http.MethodGet, status.ResultURL, "")
+	if res.Code != http.StatusOK || res.Body.String() != "%PDF-1.4 contract" {
+		t.Fatalf("expected the PDF, got %d %q", res.Code, res.Body.String())
+	}
+	if got := res.Header().Get("Content-Disposition"); !strings.Contains(got, rentContractFilename) {
+		t.Fatalf("expected the lease filename, got %q", got)
+	}
+	if got := res.Header().Get("X-Template-Id"); got != "contract-rent" {
+		t.Fatalf("expected provenance headers on the result, got %q", got)
+	}
+}
+
+func TestSubmitJobValidatesBeforeQueueing(t *testing.T) {
+	queue := jobs.NewQueue(1, 1, time.Minute)
+	defer queue.Close()
+	router := newJobRouter(&stubProposalPDFService{}, queue)
+
+	res := serveJobRequest(router, http.MethodPost, "/jobs", `{"type":"receipt","request":{"amount":0}}`)
+	if res.Code != http.StatusUnprocessableEntity || !strings.Contains(res.Body.String(), `"path":"/amount"`) {
+		t.Fatalf("expected field errors, got %d %q", res.Code, res.Body.String())
+	}
+
+	res = serveJobRequest(router, http.MethodPost, "/jobs", `{"type":"invoice","request":{}}`)
+	if res.Code != http.StatusBadRequest || !strings.Contains(res.Body.String(), "type must be") {
+		t.Fatalf("expected unknown type error, got %d %q", res.Code, res.Body.String())
+	}
+}
+
+func TestSubmitJobQueuesBundlesWithTheirPayloadLimit(t *testing.T) {
+	queue := jobs.NewQueue(1, 1, time.Minute)
+	defer queue.Close()
+	router := newJobRouter(&stubProposalPDFService{response: []byte("%PDF-1.4 bundle")}, queue)
+
+	upload := base64.StdEncoding.EncodeToString(append([]byte("%PDF-1.4\n"), bytes.Repeat([]byte("a"), int(maxProposalPayloadBytes))...))
+	res := serveJobRequest(router, http.MethodPost, "/jobs", `{"type":"bundle","request":{"parts":[{"type":"upload","data":"`+upload+`"}]}}`)
+	if res.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusAccepted, res.Code, res.Body.String())
+	}
+	var submitted jobResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &submitted); err != nil || submitted.Type != jobTypeBundle {
+		t.Fatalf("expected a bundle job, got %q", res.Body.String())
+	}
+
+	res = serveJobRequest(router, http.MethodPost, "/jobs", `{"type":"bundle","request":{"parts":[]}}`)
+	if res.Code != http.StatusUnprocessableEntity || !strings.Contains(res.Body.String(), `"path":"/parts"`) {
+		t.Fatalf("expected field errors, got %d %q", res.Code, res.Body.String())
+	}
+
+	res = serveJobRequest(router, http.MethodPost, "/jobs", `{"type":"receipt","request":{"payment_method":"`+upload+`"}}`)
+	if res.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected the receipt limit to apply, got %d", res.Code)
+	}
+}
+
+func TestJobResultReportsFailedJobs(t *testing.T) {
+	queue := jobs.NewQueue(1, 1, time.Minute)
+	defer queue.Close()
+	router := newJobRouter(&stubProposalPDFService{err: domain.UnknownBrandingProfileError()}, queue)
+
+	payload := `{"type":"financing_simulation","request":{"property_value":400000,"entry":80000,"annual_interest_rate":10.5,"term_months":360,"system":"sac"}}`
+	res := serveJobRequest(router, http.MethodPost, "/jobs", payload)
+	var submitted jobResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &submitted); err != nil {
+		t.Fatalf("expected JSON body, got %q", res.Body.String())
+	}
+
+	var failed jobResponse
+	deadline := time.Now().Add(2 * time.Second)
+	for failed.Status != jobs.StatusFailed && time.Now().Before(deadline) {
+		res = serveJobRequest(router, http.MethodGet, "/jobs/"+submitted.ID+"/result", "")
+		_ = json.Unmarshal(res.Body.Bytes(), &failed)
+		time.Sleep(time.Millisecond)
+	}
+	if res.Code != http.StatusConflict || !strings.Contains(failed.Error, "branding_profile_id") {
+		t.Fatalf("expected a failed job with its cause, got %d %+v", res.Code, failed)
+	}
+
+	res = serveJobRequest(router, http.MethodGet, "/jobs/unknown", "")
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d", http.StatusNotFound, res.Code)
+	}
+}
+
+func TestSubmitJobValidatesCallbackURL(t *testing.T) {
+	receipt := `"request":{"proposal_id":"p-1","payer":{"name":"Ana"},"payee":{"name":"Carlos"},"amount":100,"payment_method":"PIX","payment_date":"2026-03-15"}`
+
+	withoutSecret := jobs.NewQueue(1, 1, time.Minute)
+	defer withoutSecret.Close()
+	res := serveJobRequest(newJobRouter(&stubProposalPDFService{}, withoutSecret), http.MethodPost, "/jobs",
+		`{"type":"receipt",`+receipt+`,"callback_url":"https://crm.example.com/hooks/pdf"}`)
+	if res.Code != http.StatusUnprocessableEntity || !strings.Contains(res.Body.String(), `"code":"not_applicable"`) {
+		t.Fatalf("expected callbacks to be refused, got %d %q", res.Code, res.Body.String())
+	}
+
+	withSecret := jobs.NewQueue(1, 1, time.Minute, jobs.WithNotifier(jobs.NewNotifier("test-secret", jobs.WithRetries(1, 0))))
+	defer withSecret.Close()
+	router := newJobRouter(&stubProposalPDFService{}, withSecret)
+	res = serveJobRequest(router, http.MethodPost, "/jobs", `{"type":"receipt",`+receipt+`,"callback_url":"ftp://crm.example.com"}`)
+	if res.Code != http.StatusUnprocessableEntity || !strings.Contains(res.Body.String(), `"path":"/callback_url"`) {
+		t.Fatalf("expected an invalid callback URL, got %d %q", res.Code, res.Body.String())
+	}
+
+	for _, target := range []string{"http://127.0.0.1:1/hook", "http://localhost/hook", "http://10.0.0.5/hook", "http://169.254.169.254/latest", "http://[::1]/hook"} {
+		res = serveJobRequest(router, http.MethodPost, "/jobs", `{"type":"receipt",`+receipt+`,"callback_url":"`+target+`"}`)
+		if res.Code != http.StatusUnprocessableEntity || !strings.Contains(res.Body.String(), `"path":"/callback_url"`) {
+			t.Fatalf("expected %s to be refused, got %d %q", target, res.Code, res.Body.String())
+		}
+	}
+
+	res = serveJobRequest(router, http.MethodPost, "/jobs", `{"type":"receipt",`+receipt+`,"callback_url":"https://crm.example.com/hooks/pdf"}`)
+	var submitted jobResponse
+	if err := json.Unmarshal(res.Body.Bytes(), &submitted); err != nil || res.Code != http.StatusAccepted {
+		t.Fatalf("expected the job to be accepted, got %d %q", res.Code, res.Body.String())
+	}
+	if submitted.Callback == nil || submitted.Callback.Status != jobs.DeliveryPending {
+		t.Fatalf("expected a pending callback, got %+v", submitted.Callback)
+	}
+}
@@ -0,0 +1,35 @@
+package httptransport
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"pdf-service/internal/verification"
+)
+
+// VerificationHandler lets anyone holding a copy of a document look up the
+// record behind its verification code and compare the SHA-256 with the
+// file they received.
+type VerificationHandler struct {
+	registry verification.Registry
+}
+
+func NewVerificationHandler(registry verification.Registry) *VerificationHandler {
+	return &VerificationHandler{registry: registry}
+}
+
+// Verify answers with the stored record of the document whose ID or short
+// code is in the path.
+func (h *VerificationHandler) Verify(c *gin.Context) {
+	record, err := h.registry.Lookup(c.Param("id"))
+	switch {
+	case errors.Is(err, verification.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to look up document"})
+	default:
+		c.JSON(http.StatusOK, record)
+	}
+}
@@ -0,0 +1,48 @@
+package httptransport
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"pdf-service/internal/verification"
+)
+
+func TestVerifyReturnsTheStoredRecord(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := verification.NewMemoryRegistry()
+	record := verification.Record{
+		ID:              "7KQ2M9XD4HP1",
+		DocumentType:    "proposal",
+		SHA256:          "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		TemplateID:      "proposal-sale",
+		TemplateVersion: "1.0.0",
+		IssuedAt:        time.Date(2026, 3, 15, 14, 30, 0, 0, time.UTC),
+	}
+	_ = registry.Register(record)
+	router := gin.New()
+	router.GET("/verify/:id", NewVerificationHandler(registry).Verify)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/verify/7kq2-m9xd-4hp1", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", res.Code, res.Body.String())
+	}
+	var got verification.Record
+	if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got != record {
+		t.Fatalf("expected %+v, got %+v", record, got)
+	}
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/verify/0000-0000-0000", nil))
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", res.Code)
+	}
+}
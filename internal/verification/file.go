@@ -0,0 +1,77 @@
+package verification
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileRegistry appends records to a JSON Lines file and serves lookups
+// from memory. The file is the durable copy: it is read back on open, so
+// records survive restarts. One process must own the file; replicas need a
+// shared Registry implementation instead.
+type FileRegistry struct {
+	mu     sync.Mutex
+	file   *os.File
+	memory *MemoryRegistry
+}
+
+// OpenFileRegistry opens or creates the registry file at path, creating
+// its directory when needed.
+func OpenFileRegistry(path string) (*FileRegistry, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, err
+	}
+	// #nosec G304 -- the registry path comes from operator configuration.
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	memory := NewMemoryRegistry()
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		_ = memory.Register(record)
+	}
+	if err := scanner.Err(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &FileRegistry{file: file, memory: memory}, nil
+}
+
+// Register writes the record to the file before making it visible, so a
+// document is only handed out once its record is stored.
+func (r *FileRegistry) Register(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := r.file.Sync(); err != nil {
+		return err
+	}
+	return r.memory.Register(record)
+}
+
+func (r *FileRegistry) Lookup(id string) (Record, error) {
+	return r.memory.Lookup(id)
+}
+
+func (r *FileRegistry) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
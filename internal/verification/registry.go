@@ -0,0 +1,113 @@
+// Package verification keeps a record of every generated document under an
+// ID printed on the document, so anyone holding a copy can look the ID up
+// and compare the stored hash with the file they received.
+package verification
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by Lookup for an unknown ID.
+var ErrNotFound = errors.New("verification record not found")
+
+// Record describes a generated document. SHA256 is the hex digest of the
+// exact bytes that were handed out.
+type Record struct {
+	ID              string    `json:"id"`
+	DocumentType    string    `json:"document_type"`
+	SHA256          string    `json:"sha256"`
+	TemplateID      string    `json:"template_id"`
+	TemplateVersion string    `json:"template_version"`
+	IssuedAt        time.Time `json:"issued_at"`
+	ContractID      string    `json:"contract_id,omitempty"`
+}
+
+// Registry stores records. Implementations must be safe for concurrent
+// use.
+type Registry interface {
+	Register(record Record) error
+	Lookup(id string) (Record, error)
+}
+
+// idAlphabet is Crockford's base 32: digits and capitals without I, L, O
+// and U, so a code read aloud or typed from paper is hard to get wrong.
+const idAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// idLength gives 60 bits, which nobody can guess a valid code from.
+const idLength = 12
+
+// NewID returns a random verification ID.
+func NewID() string {
+	random := make([]byte, idLength)
+	_, _ = rand.Read(random)
+	return encodeID(random)
+}
+
+// DeriveID returns the verification ID of a document identified by
+// fingerprint, such as a digest of what it is rendered from, so rendering
+// the same input again prints the same ID. secret keys the derivation:
+// without it, whoever knows the input of a document can work out its ID.
+func DeriveID(secret, fingerprint []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(fingerprint)
+	return encodeID(mac.Sum(nil)[:idLength])
+}
+
+func encodeID(random []byte) string {
+	id := make([]byte, len(random))
+	for i, b := range random {
+		id[i] = idAlphabet[b%32]
+	}
+	return string(id)
+}
+
+// ShortCode formats an ID in groups of four, as printed on documents.
+func ShortCode(id string) string {
+	var groups []string
+	for len(id) > 4 {
+		groups = append(groups, id[:4])
+		id = id[4:]
+	}
+	return strings.Join(append(groups, id), "-")
+}
+
+// NormalizeID accepts an ID as a person would type it: in any case, with
+// the dashes of its short code or without, and with the letters O, I and
+// L read as the digits they look like.
+func NormalizeID(value string) string {
+	replacer := strings.NewReplacer("-", "", " ", "", "O", "0", "I", "1", "L", "1")
+	return replacer.Replace(strings.ToUpper(strings.TrimSpace(value)))
+}
+
+// MemoryRegistry keeps records for the life of the process.
+type MemoryRegistry struct {
+	mu      sync.RWMutex
+	records map[string]Record
+}
+
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{records: map[string]Record{}}
+}
+
+func (r *MemoryRegistry) Register(record Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records[record.ID] = record
+	return nil
+}
+
+func (r *MemoryRegistry) Lookup(id string) (Record, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	record, ok := r.records[NormalizeID(id)]
+	if !ok {
+		return Record{}, ErrNotFound
+	}
+	return record, nil
+}
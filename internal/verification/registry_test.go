@@ -0,0 +1,90 @@
+package verification
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestIDsAreReadable(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{12}$`)
+	seen := map[string]bool{}
+	for range 100 {
+		id := NewID()
+		if !pattern.MatchString(id) {
+			t.Fatalf("unexpected ID %q", id)
+		}
+		if seen[id] {
+			t.Fatalf("ID %q repeated", id)
+		}
+		seen[id] = true
+	}
+
+	derived := DeriveID([]byte("secret"), []byte("contract-1"))
+	if !pattern.MatchString(derived) || derived != DeriveID([]byte("secret"), []byte("contract-1")) {
+		t.Fatalf("expected a stable derived ID, got %q", derived)
+	}
+	if derived == DeriveID([]byte("other secret"), []byte("contract-1")) || derived == DeriveID([]byte("secret"), []byte("contract-2")) {
+		t.Fatal("expected the derived ID to depend on the secret and the fingerprint")
+	}
+
+	if got := ShortCode("7KQ2M9XD4HP1"); got != "7KQ2-M9XD-4HP1" {
+		t.Fatalf("unexpected short code %q", got)
+	}
+	if got := NormalizeID(" 7kq2-m9xd-4hpl "); got != "7KQ2M9XD4HP1" {
+		t.Fatalf("unexpected normalized ID %q", got)
+	}
+}
+
+func TestFileRegistryKeepsRecordsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry", "documents.jsonl")
+	record := Record{
+		ID:              "7KQ2M9XD4HP1",
+		DocumentType:    "contract",
+		SHA256:          "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		TemplateID:      "contract-sale",
+		TemplateVersion: "1.2.0",
+		IssuedAt:        time.Date(2026, 3, 15, 14, 30, 0, 0, time.UTC),
+		ContractID:      "contract-1",
+	}
+
+	registry, err := OpenFileRegistry(path)
+	if err != nil {
+		t.Fatalf("OpenFileRegistry() error = %v", err)
+	}
+	if err := registry.Register(record); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := registry.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened, err := OpenFileRegistry(path)
+	if err != nil {
+		t.Fatalf("OpenFileRegistry() error = %v", err)
+	}
+	defer reopened.Close()
+	got, err := reopened.Lookup("7kq2-m9xd-4hp1")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if got != record {
+		t.Fatalf("expected %+v, got %+v", record, got)
+	}
+	if _, err := reopened.Lookup("0000-0000-0000"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestOpenFileRegistryRejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "documents.jsonl")
+	if err := os.WriteFile(path, []byte("{not json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenFileRegistry(path); err == nil {
+		t.Fatal("expected a corrupt registry file to be reported")
+	}
+}